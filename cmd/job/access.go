@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	"github.com/slack-go/slack"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+const (
+	// anyChannel is the channels config key applied to channels that are not listed explicitly.
+	anyChannel = "*"
+	// allowedUsersAnnotation holds comma-separated Slack user IDs allowed to run the CronJob. Display names are
+	// not matched, any user can change theirs.
+	allowedUsersAnnotation = "botkubeAllowedUsers"
+	// allowedGroupsAnnotation holds comma-separated Slack user group IDs allowed to run the CronJob.
+	allowedGroupsAnnotation = "botkubeAllowedGroups"
+)
+
+// jobScope identifies where a command comes from, to limit which jobs can be used.
+type jobScope struct {
+	Channel string
+	User    executor.User
+	// Cluster is the name of the configured cluster the request runs against, empty for the Botkube cluster.
+	Cluster string
+
+	// memberOf caches user group membership for the duration of a single request.
+	memberOf map[string]bool
+}
+
+func newJobScope(msg executor.Message) jobScope {
+	return jobScope{
+		Channel:  channelFromURL(msg.URL),
+		User:     msg.User,
+		memberOf: map[string]bool{},
+	}
+}
+
+// UserID returns the Slack user ID extracted from the user mention, e.g. <@U0123456>.
+func (s jobScope) UserID() string {
+	return userID(s.User)
+}
+
+func userID(user executor.User) string {
+	return strings.TrimSuffix(strings.TrimPrefix(user.Mention, "<@"), ">")
+}
+
+// isUserAllowed returns true if the user matches the allowed users or groups annotations of the CronJob.
+// CronJobs without these annotations are available to everyone.
+func isUserAllowed(ctx context.Context, cfg Config, scope jobScope, annotations map[string]string) bool {
+	users := splitList(annotations[allowedUsersAnnotation])
+	groups := splitList(annotations[allowedGroupsAnnotation])
+	if len(users) == 0 && len(groups) == 0 {
+		return true
+	}
+
+	if slices.Contains(users, scope.UserID()) {
+		return true
+	}
+	for _, group := range groups {
+		if scope.isMemberOf(ctx, cfg, group) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s jobScope) isMemberOf(ctx context.Context, cfg Config, group string) bool {
+	if member, ok := s.memberOf[group]; ok {
+		return member
+	}
+	if cfg.BotToken == "" {
+		return false
+	}
+
+	members, err := slack.New(cfg.BotToken).GetUserGroupMembersContext(ctx, group)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get members of user group %s: %v", group, err)
+		return false
+	}
+	s.memberOf[group] = slices.Contains(members, s.UserID())
+	return s.memberOf[group]
+}
+
+func splitList(in string) []string {
+	var out []string
+	for _, item := range strings.Split(in, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// isJobAllowed returns true if the job can be used in the scope.
+func (c Config) isJobAllowed(scope jobScope, namespace, name string) bool {
+	if !c.Namespaces.IsAllowed(namespace) {
+		return false
+	}
+	if len(c.Channels) == 0 {
+		return true
+	}
+
+	channelCfg, ok := c.Channels[scope.Channel]
+	if !ok {
+		channelCfg, ok = c.Channels[anyChannel]
+	}
+	if !ok {
+		return false
+	}
+	return channelCfg.allows(namespace, name)
+}
+
+// allows returns true if the job matches one of the <name>, <namespace>/<name> or <namespace>/* entries.
+func (c ChannelConfig) allows(namespace, name string) bool {
+	for _, entry := range c.Jobs {
+		ns, jobName, found := strings.Cut(entry, "/")
+		if !found {
+			jobName, ns = ns, ""
+		}
+		if ns != "" && ns != namespace {
+			continue
+		}
+		if jobName == "*" || jobName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeJob returns an error unless the user can run the source of the botkube-created job in the scope,
+// so jobs are only shown and deleted where their CronJob or Job template can be run.
+func authorizeJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, job batchv1.Job) error {
+	unauthorized := fmt.Errorf("you are not authorized to access job %s/%s", job.Namespace, job.Name)
+	source := job.Labels[sourceLabel]
+	if source == "" || !cfg.isJobAllowed(scope, job.Namespace, source) {
+		return unauthorized
+	}
+	workload, err := getWorkload(ctx, envs, sourceResource(job.Labels[sourceKindLabel]), job.Namespace, source)
+	if err != nil || !isUserAllowed(ctx, cfg, scope, workload.Metadata.Annotations) {
+		return unauthorized
+	}
+	return nil
+}
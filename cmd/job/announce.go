@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// announceRun posts a compact note about the created job to the announcements channel, regardless of the channel
+// the run was requested from. Failures are logged, they don't fail the run.
+func announceRun(ctx context.Context, cfg Config, req runRequest, jobName string) {
+	if cfg.Announce.ChannelID == "" {
+		return
+	}
+	n, err := newNotifier(Config{BotToken: cfg.BotToken, BotName: cfg.BotName, ChannelID: cfg.Announce.ChannelID}, executor.Message{})
+	if err == nil {
+		err = n.Post(ctx, formatAnnouncement(req, jobName))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to announce job %s: %v", jobName, err)
+	}
+}
+
+func formatAnnouncement(req runRequest, jobName string) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s started job *%s* in namespace *%s*", announcedUser(req.Requester), req.Name, req.Namespace)
+	if req.Cluster != "" {
+		fmt.Fprintf(&out, " on cluster *%s*", req.Cluster)
+	}
+	if req.Approver != (executor.User{}) {
+		fmt.Fprintf(&out, ", approved by %s", announcedUser(req.Approver))
+	}
+	fmt.Fprintf(&out, " as `%s`", jobName)
+
+	audit := auditOf(req)
+	if params := strings.Join(append(audit.Overrides, audit.Args...), " "); params != "" {
+		fmt.Fprintf(&out, " with `%s`", params)
+	}
+	if req.Channel != "" {
+		fmt.Fprintf(&out, " from <#%s>", req.Channel)
+	}
+	return out.String()
+}
+
+// announcedUser returns the mention of the user, or the name if the mention is not known.
+func announcedUser(user executor.User) string {
+	if user.Mention != "" {
+		return user.Mention
+	}
+	return requesterName(user)
+}
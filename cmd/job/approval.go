@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+const (
+	// requireApprovalAnnotation enables the two-person approval flow for a CronJob.
+	requireApprovalAnnotation = "botkubeRequireApproval"
+	// approverAnnotation holds the user who approved the job launch.
+	approverAnnotation = "botkube.io/approver"
+	// approversAnnotation holds comma-separated Slack user IDs allowed to approve runs of the CronJob.
+	// Without it, users allowed to run the CronJob approve its runs.
+	approversAnnotation = "botkubeApprovers"
+)
+
+// pendingApproval is a job launch waiting for an approval of another user.
+type pendingApproval struct {
+	Request runRequest
+	// Origin is the message which requested the launch, used for follow-up messages.
+	Origin executor.Message
+	// Expires is when the request can no longer be approved, see ApprovalConfig.TTL.
+	Expires time.Time
+}
+
+// approvals holds pending approvals. They are kept in memory, so they don't survive plugin restarts.
+var approvals = struct {
+	sync.Mutex
+	pending map[string]pendingApproval
+}{
+	pending: map[string]pendingApproval{},
+}
+
+// requestApproval stores the launch request and asks for an approval, either in the configured approval channel
+// or in the current one.
+func requestApproval(ctx context.Context, cfg Config, origin executor.Message, req runRequest) executor.ExecuteOutput {
+	id := uuid.New().String()
+	now := time.Now()
+	approvals.Lock()
+	// expired requests are dropped here, so requests nobody handled don't pile up
+	for pendingID, pending := range approvals.pending {
+		if now.After(pending.Expires) {
+			delete(approvals.pending, pendingID)
+		}
+	}
+	approvals.pending[id] = pendingApproval{Request: req, Origin: origin, Expires: now.Add(cfg.Approval.TTL)}
+	approvals.Unlock()
+
+	action := "run job"
+	if req.Kind == kindDeployment {
+		action = "restart deployment"
+	}
+	text := fmt.Sprintf("%s requested to %s *%s* in namespace *%s*\n```\n%s\n```", req.Requester.Mention, action, req.Name, req.Namespace, req.Command)
+	btnBuilder := api.NewMessageButtonBuilder()
+	buttons := []api.Button{
+		btnBuilder.ForCommandWithoutDesc("Approve", withClusterFlag(fmt.Sprintf("%s approve %s", pluginName, id), req.Cluster), api.ButtonStylePrimary),
+		btnBuilder.ForCommandWithoutDesc("Reject", withClusterFlag(fmt.Sprintf("%s reject %s", pluginName, id), req.Cluster), api.ButtonStyleDanger),
+	}
+
+	if cfg.Approval.ChannelID == "" {
+		return executor.ExecuteOutput{
+			Message: api.Message{
+				Sections: []api.Section{
+					{
+						Base: api.Base{
+							Body: api.Body{
+								Plaintext: text,
+							},
+						},
+						Buttons: buttons,
+					},
+				},
+			},
+		}
+	}
+
+	n, err := newNotifier(Config{BotToken: cfg.BotToken, BotName: cfg.BotName, ChannelID: cfg.Approval.ChannelID}, executor.Message{})
+	if err == nil {
+		err = n.Post(ctx, text, buttons...)
+	}
+	if err != nil {
+		approvals.Lock()
+		delete(approvals.pending, id)
+		approvals.Unlock()
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("while requesting approval: %v", err), true),
+		}
+	}
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(fmt.Sprintf("Job %s requires approval, the request was sent to the approvers", req.Name), true),
+	}
+}
+
+// approveRun launches the pending job, or restarts the pending deployment, if it is approved by an approver of the job other than the requester.
+func approveRun(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, in executor.ExecuteInput, id string) executor.ExecuteOutput {
+	approver := in.Context.Message.User
+
+	pending, ok := pendingRequest(id)
+	if !ok {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("Approval request not found, it was already handled or expired", true),
+		}
+	}
+	// the mention is not compared, it may be a display name the requester can change
+	if userID(pending.Request.Requester) == scope.UserID() {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("You cannot approve your own request", true),
+		}
+	}
+	if !isApprover(ctx, envs, cfg, scope, pending.Request) {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("You are not authorized to approve runs of job %s", pending.Request.Name), true),
+		}
+	}
+	// another approver may have handled the request in the meantime
+	if !takePendingRequest(id) {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("Approval request not found, it was already handled or expired", true),
+		}
+	}
+
+	req := pending.Request
+	req.Approver = approver
+	if req.Kind == kindDeployment {
+		out := rolloutRestart(ctx, envs, req.Name, req.Namespace)
+		notifyRequester(ctx, cfg, pending.Origin, fmt.Sprintf("Restart of deployment *%s* was approved by %s", req.Name, approver.Mention))
+		return out
+	}
+	out := launchJob(ctx, envs, cfg, in.Context.KubeConfig, pending.Origin, req)
+	notifyRequester(ctx, cfg, pending.Origin, fmt.Sprintf("Run of job *%s* was approved by %s", req.Name, approver.Mention))
+	return out
+}
+
+// rejectRun discards the pending job launch, on behalf of an approver of the job or the requester.
+func rejectRun(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, in executor.ExecuteInput, id string) executor.ExecuteOutput {
+	pending, ok := pendingRequest(id)
+	if ok && userID(pending.Request.Requester) != scope.UserID() && !isApprover(ctx, envs, cfg, scope, pending.Request) {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("You are not authorized to reject runs of job %s", pending.Request.Name), true),
+		}
+	}
+	if !ok || !takePendingRequest(id) {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("Approval request not found, it was already handled or expired", true),
+		}
+	}
+
+	msg := fmt.Sprintf("Run of job *%s* was rejected by %s", pending.Request.Name, in.Context.Message.User.Mention)
+	notifyRequester(ctx, cfg, pending.Origin, msg)
+	return executor.ExecuteOutput{
+		Message: api.NewPlaintextMessage(msg, false),
+	}
+}
+
+// pendingRequest returns the pending approval of the id, unless it expired.
+func pendingRequest(id string) (pendingApproval, bool) {
+	approvals.Lock()
+	defer approvals.Unlock()
+	pending, ok := approvals.pending[id]
+	if ok && time.Now().After(pending.Expires) {
+		delete(approvals.pending, id)
+		return pendingApproval{}, false
+	}
+	return pending, ok
+}
+
+// takePendingRequest removes the pending approval of the id. It returns false if it was already removed.
+func takePendingRequest(id string) bool {
+	approvals.Lock()
+	defer approvals.Unlock()
+	_, ok := approvals.pending[id]
+	delete(approvals.pending, id)
+	return ok
+}
+
+// isApprover returns true if the user is one of the approvers of the botkubeApprovers annotation of the job,
+// or, without it, is allowed to run the job. User IDs are compared, display names can be changed by anyone.
+func isApprover(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, req runRequest) bool {
+	source, err := getWorkload(ctx, envs, sourceResource(req.Kind), req.Namespace, req.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get approvers of job %s/%s: %v", req.Namespace, req.Name, err)
+		return false
+	}
+	if approvers := splitList(source.Metadata.Annotations[approversAnnotation]); len(approvers) > 0 {
+		return slices.Contains(approvers, scope.UserID())
+	}
+	return isUserAllowed(ctx, cfg, scope, source.Metadata.Annotations)
+}
+
+func notifyRequester(ctx context.Context, cfg Config, origin executor.Message, text string) {
+	n, err := newNotifier(cfg, origin)
+	if err != nil {
+		return
+	}
+	if err := n.Post(ctx, text); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to notify requester: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+func TestApproveRun(t *testing.T) {
+	fakeKubectl(t, map[string]string{
+		"get cronjob -n team-a backup -ojson": fakeWorkload(t, "team-a", "backup", map[string]string{
+			requireApprovalAnnotation: "true",
+			approversAnnotation:       "U0234567,U0345678,U0456789",
+		}),
+	})
+	requester := executor.User{Mention: "<@U0345678>", DisplayName: "alice"}
+	approver := executor.User{Mention: "<@U0234567>"}
+	other := executor.User{Mention: "<@U0999999>"}
+	approve := func(cfg Config, user executor.User, id string) string {
+		scope := jobScope{Channel: "C0123456", User: user, memberOf: map[string]bool{}}
+		in := executor.ExecuteInput{Context: executor.ExecuteInputContext{Message: executor.Message{User: user}}}
+		out := approveRun(context.Background(), nil, cfg, scope, in, id)
+		return out.Message.BaseBody.CodeBlock + out.Message.BaseBody.Plaintext
+	}
+	request := func(cfg Config) string {
+		req := runRequest{Kind: kindCronJob, Name: "backup", Namespace: "team-a", Requester: requester, Channel: "C0123456"}
+		requestApproval(context.Background(), cfg, executor.Message{}, req)
+		return pendingID(t, "backup")
+	}
+
+	t.Run("self-approval", func(t *testing.T) {
+		cfg := testConfig()
+		id := request(cfg)
+		// the requester is an approver of the job, but not of own runs, whatever the display name
+		if out := approve(cfg, executor.User{Mention: requester.Mention, DisplayName: "bob"}, id); !strings.Contains(out, "cannot approve your own request") {
+			t.Errorf("requester approved the run: %s", out)
+		}
+		if _, ok := pendingRequest(id); !ok {
+			t.Error("self-approval removed the pending request")
+		}
+		takePendingRequest(id)
+	})
+
+	t.Run("not an approver", func(t *testing.T) {
+		cfg := testConfig()
+		id := request(cfg)
+		if out := approve(cfg, other, id); !strings.Contains(out, "not authorized to approve") {
+			t.Errorf("user outside of botkubeApprovers approved the run: %s", out)
+		}
+		takePendingRequest(id)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.Approval.TTL = -time.Second
+		id := request(cfg)
+		if out := approve(cfg, approver, id); !strings.Contains(out, "already handled or expired") {
+			t.Errorf("expired request was approved: %s", out)
+		}
+		if _, ok := pendingRequest(id); ok {
+			t.Error("expired request is still pending")
+		}
+	})
+
+	t.Run("double approve", func(t *testing.T) {
+		cfg := testConfig()
+		id := request(cfg)
+		if out := approve(cfg, approver, id); strings.Contains(out, "already handled or expired") {
+			t.Fatalf("first approval failed: %s", out)
+		}
+		if out := approve(cfg, executor.User{Mention: "<@U0456789>"}, id); !strings.Contains(out, "already handled or expired") {
+			t.Errorf("request was approved twice: %s", out)
+		}
+	})
+}
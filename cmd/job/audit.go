@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Labels and annotations stamped on created jobs for auditing and filtering.
+const (
+	managedLabel          = "botkube.io/managed"
+	requesterIDLabel      = "botkube.io/requester-id"
+	channelLabel          = "botkube.io/channel"
+	sourceLabel           = "botkube.io/source"
+	sourceKindLabel       = "botkube.io/source-kind"
+	requestedAtAnnotation = "botkube.io/requested-at"
+	argsAnnotation        = "botkube.io/args"
+)
+
+// Env vars describing the run, set on all containers of the created job when enabled with requesterEnv.
+const (
+	requesterEnv   = "BOTKUBE_REQUESTER"
+	requesterIDEnv = "BOTKUBE_REQUESTER_ID"
+	approverEnv    = "BOTKUBE_APPROVER"
+	channelEnv     = "BOTKUBE_CHANNEL"
+)
+
+var invalidLabelChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// runAudit is stored in the args annotation of created jobs.
+type runAudit struct {
+	Args      []string `json:"args"`
+	Overrides []string `json:"overrides,omitempty"`
+}
+
+// stampJob adds audit labels and annotations describing who requested the run, from where and with which args.
+func stampJob(job map[string]interface{}, req runRequest, now time.Time) error {
+	metadata := job["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	if labels == nil {
+		labels = map[string]interface{}{}
+		metadata["labels"] = labels
+	}
+	labels[managedLabel] = "true"
+	labels[sourceLabel] = labelValue(req.Name)
+	labels[sourceKindLabel] = req.Kind
+	if id := userID(req.Requester); id != "" {
+		labels[requesterIDLabel] = labelValue(id)
+	}
+	if req.Channel != "" {
+		labels[channelLabel] = labelValue(req.Channel)
+	}
+
+	rawAudit, err := json.Marshal(auditOf(req))
+	if err != nil {
+		return fmt.Errorf("while marshalling run args: %w", err)
+	}
+	annotations := metadata["annotations"].(map[string]interface{})
+	annotations[requestedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	annotations[argsAnnotation] = string(rawAudit)
+	return nil
+}
+
+// auditOf returns the args and overrides of the run as recorded for auditing.
+func auditOf(req runRequest) runAudit {
+	audit := runAudit{Args: req.Args}
+	for _, override := range req.Overrides {
+		audit.Overrides = append(audit.Overrides, fmt.Sprintf("%s:%s=%s", override.Kind, override.Key, override.Value))
+	}
+	return audit
+}
+
+// labelValue makes the value a valid label value.
+func labelValue(value string) string {
+	value = invalidLabelChars.ReplaceAllString(value, "-")
+	if len(value) > maxJobNameLen {
+		value = value[:maxJobNameLen]
+	}
+	return strings.Trim(value, "-_.")
+}
+
+// setRequesterEnv sets env vars with the requester, approver and channel of the run on all containers, so the
+// workload can log or tag who triggered it. They are set after overrides, so a run cannot fake them.
+func setRequesterEnv(job map[string]interface{}, req runRequest) {
+	values := [][2]string{
+		{requesterEnv, requesterName(req.Requester)},
+		{requesterIDEnv, userID(req.Requester)},
+		{channelEnv, req.Channel},
+	}
+	if req.Approver.Mention != "" {
+		values = append(values, [2]string{approverEnv, requesterName(req.Approver)})
+	}
+	for _, container := range jobContainers(job) {
+		for _, value := range values {
+			if value[1] == "" {
+				continue
+			}
+			setContainerEnv(container, map[string]interface{}{
+				"name":  value[0],
+				"value": value[1],
+			})
+		}
+	}
+}
+
+// ensureCronJobOwner makes the CronJob the owner of the job, so tooling following ownerReferences correlates
+// the run with its CronJob. kubectl create job --from sets it, older kubectl versions may not.
+// Runs of Job templates are only labeled: an owner reference would delete them together with the template.
+func ensureCronJobOwner(ctx context.Context, envs map[string]string, job map[string]interface{}, req runRequest) error {
+	metadata, _ := job["metadata"].(map[string]interface{})
+	if metadata == nil {
+		return nil
+	}
+	if owners, _ := metadata["ownerReferences"].([]interface{}); len(owners) > 0 {
+		return nil
+	}
+	getCmd := fmt.Sprintf("kubectl get cronjob -n %s %s -o jsonpath={.metadata.uid}", req.Namespace, req.Name)
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return fmt.Errorf("while getting cronjob %s: %w", req.Name, err)
+	}
+	metadata["ownerReferences"] = []interface{}{
+		map[string]interface{}{
+			"apiVersion":         "batch/v1",
+			"kind":               kindCronJob,
+			"name":               req.Name,
+			"uid":                strings.TrimSpace(out.Stdout),
+			"controller":         true,
+			"blockOwnerDeletion": true,
+		},
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// jobsCache keeps discovered jobs, so dropdown interactions don't list all workloads in the cluster again.
+var jobsCache = struct {
+	sync.Mutex
+	key     string
+	jobs    []Job
+	expires time.Time
+}{}
+
+// cachedJobs returns discovered jobs from the cache, refreshing them when the TTL expires
+// or the cluster or discovery settings change. A zero TTL disables caching. Partial results of a failed
+// discovery are not cached, so the next interaction tries again.
+func cachedJobs(ctx context.Context, envs map[string]string, cfg Config, cluster string) ([]Job, error) {
+	if cfg.Discovery.CacheTTL <= 0 {
+		return discoverJobs(ctx, envs, cfg)
+	}
+
+	key := fmt.Sprintf("%s %+v %+v", cluster, cfg.Discovery, cfg.Namespaces)
+	jobsCache.Lock()
+	defer jobsCache.Unlock()
+	if jobsCache.key == key && time.Now().Before(jobsCache.expires) {
+		return jobsCache.jobs, nil
+	}
+
+	jobs, err := discoverJobs(ctx, envs, cfg)
+	if err != nil {
+		return jobs, err
+	}
+	jobsCache.key = key
+	jobsCache.jobs = jobs
+	jobsCache.expires = time.Now().Add(cfg.Discovery.CacheTTL)
+	return jobs, nil
+}
+
+// invalidateJobsCache forces the next discovery to query the cluster.
+func invalidateJobsCache() {
+	jobsCache.Lock()
+	defer jobsCache.Unlock()
+	jobsCache.expires = time.Time{}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	olderThanFlag = "--older-than"
+	confirmFlag   = "--confirm"
+	// maxCleanupListed limits the number of jobs listed in the cleanup confirmation.
+	maxCleanupListed = 20
+)
+
+// cleanupJobs lists finished botkube-created jobs older than the given duration and deletes them after a confirmation.
+// Only jobs the user can delete are included, see authorizeJob.
+// Usage: job cleanup [--older-than 24h] [--confirm]
+func cleanupJobs(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string) executor.ExecuteOutput {
+	olderThan := cfg.Cleanup.OlderThan
+	confirmed := false
+	fields := strings.Fields(value)
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case olderThanFlag:
+			if i+1 >= len(fields) {
+				return cleanupUsage()
+			}
+			d, err := time.ParseDuration(fields[i+1])
+			if err != nil {
+				return cleanupUsage()
+			}
+			olderThan = d
+			i++
+		case confirmFlag:
+			confirmed = true
+		default:
+			return cleanupUsage()
+		}
+	}
+
+	jobs, err := listBotkubeJobs(ctx, envs)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	var finished []batchv1.Job
+	// runs of the same source share the answer, so it is read once
+	authorized := map[string]bool{}
+	for _, job := range jobs {
+		end, done := jobFinishedAt(job)
+		if !done || time.Since(end) < olderThan || !cfg.Namespaces.IsAllowed(job.Namespace) {
+			continue
+		}
+		key := workloadKey(job.Labels[sourceKindLabel], job.Namespace, job.Labels[sourceLabel])
+		allowed, ok := authorized[key]
+		if !ok {
+			allowed = authorizeJob(ctx, envs, cfg, scope, job) == nil
+			authorized[key] = allowed
+		}
+		if !allowed {
+			continue
+		}
+		finished = append(finished, job)
+	}
+	if len(finished) == 0 {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("No finished jobs older than %s found", olderThan), true),
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreationTimestamp.Before(&finished[j].CreationTimestamp)
+	})
+
+	if !confirmed {
+		var items []string
+		for i, job := range finished {
+			if i == maxCleanupListed {
+				items = append(items, fmt.Sprintf("... and %d more", len(finished)-maxCleanupListed))
+				break
+			}
+			items = append(items, fmt.Sprintf("%s/%s (%s, %s old)", job.Namespace, job.Name, jobPhase(job), duration.HumanDuration(time.Since(job.CreationTimestamp.Time))))
+		}
+		btnBuilder := api.NewMessageButtonBuilder()
+		confirmCmd := fmt.Sprintf("%s cleanup %s %s %s", pluginName, olderThanFlag, olderThan, confirmFlag)
+		return executor.ExecuteOutput{
+			Message: api.Message{
+				Sections: []api.Section{
+					{
+						Base: api.Base{
+							Header: fmt.Sprintf("%d finished jobs older than %s", len(finished), olderThan),
+						},
+						BulletLists: api.BulletLists{
+							{Items: items},
+						},
+						Buttons: []api.Button{
+							btnBuilder.ForCommandWithoutDesc(fmt.Sprintf("Delete %d jobs", len(finished)), confirmCmd, api.ButtonStyleDanger),
+						},
+					},
+				},
+				OnlyVisibleForYou: true,
+			},
+		}
+	}
+
+	byNamespace := map[string][]string{}
+	for _, job := range finished {
+		byNamespace[job.Namespace] = append(byNamespace[job.Namespace], job.Name)
+	}
+	var errs []string
+	for namespace, names := range byNamespace {
+		deleteCmd := fmt.Sprintf("kubectl delete job -n %s %s --cascade=background", namespace, strings.Join(names, " "))
+		if _, err := kubectl(ctx, envs, deleteCmd); err != nil {
+			errs = append(errs, fmt.Sprintf("while deleting jobs in namespace %s: %v", namespace, err))
+		}
+	}
+	if len(errs) > 0 {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(strings.Join(errs, "\n"), true),
+		}
+	}
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			BaseBody: api.Body{
+				CodeBlock: fmt.Sprintf("Deleted %d finished jobs", len(finished)),
+			},
+			OnlyVisibleForYou: true,
+			ReplaceOriginal:   true,
+		},
+	}
+}
+
+func cleanupUsage() executor.ExecuteOutput {
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(fmt.Sprintf("Usage: %s cleanup [%s <duration>]", pluginName, olderThanFlag), true),
+	}
+}
+
+// jobFinishedAt returns the time the job completed or failed.
+func jobFinishedAt(job batchv1.Job) (time.Time, bool) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue || (cond.Type != batchv1.JobComplete && cond.Type != batchv1.JobFailed) {
+			continue
+		}
+		if job.Status.CompletionTime != nil {
+			return job.Status.CompletionTime.Time, true
+		}
+		return cond.LastTransitionTime.Time, true
+	}
+	return time.Time{}, false
+}
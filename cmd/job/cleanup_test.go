@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCleanupJobsScope(t *testing.T) {
+	created := time.Now().Add(-72 * time.Hour)
+	finished := func(namespace, name, source string) batchv1.Job {
+		// the jobs are deleted in the order they were created
+		created = created.Add(time.Minute)
+		end := metav1.NewTime(time.Now().Add(-48 * time.Hour))
+		job := batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         namespace,
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created),
+				Annotations:       map[string]string{botkubeAnnotation: "true"},
+				Labels:            map[string]string{sourceKindLabel: kindCronJob},
+			},
+			Status: batchv1.JobStatus{
+				CompletionTime: &end,
+				Conditions:     []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			},
+		}
+		if source != "" {
+			job.Labels[sourceLabel] = source
+		}
+		return job
+	}
+	jobs := batchv1.JobList{Items: []batchv1.Job{
+		finished("team-a", "backup-1", "backup"),
+		finished("team-a", "backup-2", "backup"),
+		finished("team-a", "restore-1", "restore"),
+		finished("team-b", "report-1", "report"),
+		finished("team-a", "manual-1", ""),
+	}}
+	calls := fakeKubectl(t, map[string]string{
+		"get jobs -A -ojson":                   fakeJSON(t, jobs),
+		"get cronjob -n team-a backup -ojson":  fakeWorkload(t, "team-a", "backup", nil),
+		"get cronjob -n team-a restore -ojson": fakeWorkload(t, "team-a", "restore", map[string]string{allowedUsersAnnotation: "U0999999"}),
+		"get cronjob -n team-b report -ojson":  fakeWorkload(t, "team-b", "report", nil),
+	})
+	cfg := testConfig()
+	cfg.Channels = map[string]ChannelConfig{"C0123456": {Jobs: []string{"team-a/*"}}}
+	scope := jobScope{Channel: "C0123456", User: executor.User{Mention: "<@U0123456>"}, memberOf: map[string]bool{}}
+
+	cleanupJobs(context.Background(), nil, cfg, scope, "--confirm")
+
+	var deletes []string
+	for _, call := range calls() {
+		if strings.HasPrefix(call, "delete ") {
+			deletes = append(deletes, call)
+		}
+	}
+	want := []string{"delete job -n team-a backup-1 backup-2 --cascade=background"}
+	if !slices.Equal(deletes, want) {
+		t.Errorf("cleanup deleted %q, want %q", deletes, want)
+	}
+}
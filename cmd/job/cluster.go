@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+)
+
+// clusterFlag selects the cluster a command runs against: job --cluster <name> <action> ...
+// It is added to all commands rendered in responses, so follow-up interactions stay on the same cluster.
+const clusterFlag = "--cluster"
+
+// cutClusterFlag returns the cluster from the --cluster flag following the plugin name and the command without it.
+// The flag is not looked for further in the command, where it may be an arg of the job.
+func cutClusterFlag(cmd string) (cluster, rest string) {
+	plugin, args := cutField(cmd)
+	if plugin != pluginName {
+		return "", cmd
+	}
+	flag, args := cutField(args)
+	if value, found := strings.CutPrefix(flag, clusterFlag+"="); found {
+		return value, plugin + " " + args
+	}
+	if flag != clusterFlag {
+		return "", cmd
+	}
+	cluster, args = cutField(args)
+	if cluster == "" {
+		return "", cmd
+	}
+	return cluster, plugin + " " + args
+}
+
+// withClusterFlag adds the cluster flag after the plugin name of the command.
+func withClusterFlag(cmd, cluster string) string {
+	if cluster == "" {
+		return cmd
+	}
+	prefix := pluginName + " "
+	if strings.HasPrefix(cmd, api.MessageBotNamePlaceholder+" ") {
+		prefix = api.MessageBotNamePlaceholder + " " + prefix
+	}
+	rest, found := strings.CutPrefix(cmd, prefix)
+	if !found || strings.HasPrefix(rest, clusterFlag) {
+		return cmd
+	}
+	return fmt.Sprintf("%s%s %s %s", prefix, clusterFlag, cluster, rest)
+}
+
+// clusterMessage adds the cluster flag to commands of buttons, selects and inputs of the message.
+func clusterMessage(msg api.Message, cluster string) api.Message {
+	if cluster == "" {
+		return msg
+	}
+	for i := range msg.Sections {
+		section := &msg.Sections[i]
+		for j := range section.Buttons {
+			section.Buttons[j].Command = withClusterFlag(section.Buttons[j].Command, cluster)
+		}
+		for j := range section.Selects.Items {
+			section.Selects.Items[j].Command = withClusterFlag(section.Selects.Items[j].Command, cluster)
+		}
+		if section.MultiSelect.Command != "" {
+			section.MultiSelect.Command = withClusterFlag(section.MultiSelect.Command, cluster)
+		}
+		for j := range section.PlaintextInputs {
+			section.PlaintextInputs[j].Command = withClusterFlag(section.PlaintextInputs[j].Command, cluster)
+		}
+	}
+	return msg
+}
+
+// resolveCluster returns the name of the requested cluster, or of the first configured one if none was requested.
+func resolveCluster(cfg Config, cluster string) (string, error) {
+	if len(cfg.Clusters) == 0 {
+		if cluster != "" {
+			return "", fmt.Errorf("cluster %q is not configured", cluster)
+		}
+		return "", nil
+	}
+	if cluster == "" {
+		return cfg.Clusters[0].Name, nil
+	}
+	if !slices.ContainsFunc(cfg.Clusters, func(c ClusterConfig) bool { return c.Name == cluster }) {
+		return "", fmt.Errorf("cluster %q is not configured", cluster)
+	}
+	return cluster, nil
+}
+
+// clusterKubeConfig returns the kubeconfig of the cluster. Clusters without a kubeconfig file use the Botkube one.
+// When a context is set, the kubeconfig is reduced to that context, so it can be persisted like the Botkube one.
+func clusterKubeConfig(ctx context.Context, cfg Config, cluster string, botkubeKubeConfig []byte) ([]byte, error) {
+	idx := slices.IndexFunc(cfg.Clusters, func(c ClusterConfig) bool { return c.Name == cluster })
+	if idx < 0 || cfg.Clusters[idx].KubeConfig == "" {
+		return botkubeKubeConfig, nil
+	}
+	c := cfg.Clusters[idx]
+	if c.Context == "" {
+		kubeConfig, err := os.ReadFile(c.KubeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("while reading kubeconfig of cluster %s: %w", c.Name, err)
+		}
+		return kubeConfig, nil
+	}
+
+	viewCmd := fmt.Sprintf("kubectl config view --minify --flatten --context %s", c.Context)
+	out, err := kubectl(ctx, map[string]string{"KUBECONFIG": c.KubeConfig}, viewCmd)
+	if err != nil {
+		return nil, fmt.Errorf("while reading context %s of cluster %s: %w", c.Context, c.Name, err)
+	}
+	return []byte(out.Stdout), nil
+}
+
+func createClusterSelect(clusters []ClusterConfig, selected string, cmd string) api.Select {
+	var options []api.OptionItem
+	for _, c := range clusters {
+		options = append(options, api.OptionItem{Name: c.Name, Value: c.Name})
+	}
+	return api.Select{
+		Name:    "Cluster",
+		Command: cmd,
+		OptionGroups: []api.OptionGroup{
+			{
+				Name:    "Cluster",
+				Options: options,
+			},
+		},
+		InitialOption: &api.OptionItem{Name: selected, Value: selected},
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// allowConcurrentAnnotation disables the duplicate run guard of the CronJob.
+const allowConcurrentAnnotation = "botkubeAllowConcurrent"
+
+// activeRuns returns unfinished botkube-created runs of the CronJob or Job template.
+func activeRuns(ctx context.Context, envs map[string]string, req runRequest) ([]string, error) {
+	jobs, err := listBotkubeJobsBySelector(ctx, envs, fmt.Sprintf("%s=%s", sourceLabel, labelValue(req.Name)))
+	if err != nil {
+		return nil, err
+	}
+
+	var active []string
+	for _, job := range jobs {
+		if job.Namespace != req.Namespace {
+			continue
+		}
+		if _, done := jobFinished(job); done {
+			continue
+		}
+		active = append(active, fmt.Sprintf("%s (%s, started %s ago)", job.Name, jobPhase(job), duration.HumanDuration(time.Since(job.CreationTimestamp.Time))))
+	}
+	return active, nil
+}
+
+// duplicateRunMessage asks for an explicit confirmation to start another run while others are still active.
+func duplicateRunMessage(req runRequest, active []string, value string) executor.ExecuteOutput {
+	btnBuilder := api.NewMessageButtonBuilder()
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections: []api.Section{
+				{
+					Base: api.Base{
+						Header:      "Job is already running",
+						Description: fmt.Sprintf("%s in namespace %s has active runs:", req.Name, req.Namespace),
+					},
+					BulletLists: api.BulletLists{
+						{Items: active},
+					},
+					Buttons: []api.Button{
+						btnBuilder.ForCommandWithoutDesc("Run anyway", fmt.Sprintf("%s run-anyway %s", pluginName, value), api.ButtonStyleDanger),
+					},
+				},
+			},
+			OnlyVisibleForYou: true,
+		},
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// Config holds the job executor configuration.
+type Config struct {
+	// BotToken is the Slack bot token used to post follow-up messages outside of the command response.
+	BotToken string `yaml:"botToken"`
+	// BotName is the Botkube bot name used in commands of buttons attached to follow-up messages.
+	BotName string `yaml:"botName"`
+	// ChannelID is the fallback channel for follow-up messages when it cannot be resolved from the triggering message.
+	ChannelID string `yaml:"channelID"`
+	// Discovery configures how runnable CronJobs are found in addition to the botkubeJobArgs annotation.
+	Discovery DiscoveryConfig `yaml:"discovery"`
+	// Namespaces limits CronJob discovery and job launches to a subset of namespaces.
+	Namespaces NamespacesConfig `yaml:"namespaces"`
+	// Channels maps channel IDs to jobs allowed in them. The "*" entry applies to channels not listed.
+	// When empty, all discovered jobs are allowed everywhere.
+	Channels map[string]ChannelConfig `yaml:"channels"`
+	// Approval configures the two-person approval flow for CronJobs with the botkubeRequireApproval annotation.
+	Approval ApprovalConfig `yaml:"approval"`
+	// Watch configures tracking of launched jobs.
+	Watch WatchConfig `yaml:"watch"`
+	// Logs configures attaching pod logs to the completion message.
+	Logs LogsConfig `yaml:"logs"`
+	// JobNameTemplate is a Go template of created job names with CronJob, Namespace, User and Timestamp fields.
+	// The result is sanitized to a DNS-1123 label of at most 63 characters that always ends with the timestamp,
+	// followed by the index of matrix runs; longer names are shortened before it.
+	JobNameTemplate string `yaml:"jobNameTemplate"`
+	// Cleanup configures removal of finished jobs.
+	Cleanup CleanupConfig `yaml:"cleanup"`
+	// Clusters lists clusters jobs can be run on. The first one is used unless another one is selected.
+	// When empty, only the Botkube cluster is used.
+	Clusters []ClusterConfig `yaml:"clusters"`
+	// History configures the persisted history of runs.
+	History HistoryConfig `yaml:"history"`
+	// Webhook is notified about every created job.
+	Webhook WebhookConfig `yaml:"webhook"`
+	// Visibility configures who sees the job form and run results.
+	Visibility VisibilityConfig `yaml:"visibility"`
+	// Announce configures announcements of runs in a dedicated channel.
+	Announce AnnounceConfig `yaml:"announce"`
+	// Policy configures checking jobs against admission policies before they are created.
+	Policy PolicyConfig `yaml:"policy"`
+	// RequesterEnv sets BOTKUBE_REQUESTER, BOTKUBE_REQUESTER_ID, BOTKUBE_CHANNEL and, for approved runs,
+	// BOTKUBE_APPROVER on the containers of created jobs.
+	RequesterEnv bool `yaml:"requesterEnv"`
+	// Dashboards are links to monitoring of created jobs, added as buttons to the run confirmation.
+	Dashboards []DashboardLink `yaml:"dashboards"`
+}
+
+// DiscoveryConfig holds settings for label-based CronJob discovery.
+type DiscoveryConfig struct {
+	// LabelSelector selects runnable CronJobs, e.g. botkube.io/runnable=true.
+	LabelSelector string `yaml:"labelSelector"`
+	// ArgsConfigMap is the name of a ConfigMap in the CronJob namespace holding args definitions keyed by CronJob name.
+	// It is used for CronJobs selected by LabelSelector without the botkubeJobArgs annotation.
+	ArgsConfigMap string `yaml:"argsConfigMap"`
+	// DefinitionSelector selects ConfigMaps holding job definitions. Empty disables job definitions.
+	DefinitionSelector string `yaml:"definitionSelector"`
+	// CacheTTL is how long discovered jobs are cached. Zero disables caching.
+	CacheTTL time.Duration `yaml:"cacheTTL"`
+}
+
+// NamespacesConfig holds namespaces include and exclude lists.
+// When Include is set, CronJobs are listed only in the given namespaces.
+type NamespacesConfig struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// IsAllowed returns true if the namespace matches the include and exclude lists.
+func (n NamespacesConfig) IsAllowed(namespace string) bool {
+	if slices.Contains(n.Exclude, namespace) {
+		return false
+	}
+	return len(n.Include) == 0 || slices.Contains(n.Include, namespace)
+}
+
+// ChannelConfig holds the allowlist of jobs for a channel.
+type ChannelConfig struct {
+	// Jobs lists allowed jobs as <name>, <namespace>/<name> or <namespace>/*.
+	Jobs []string `yaml:"jobs"`
+}
+
+// ApprovalConfig holds settings for job launch approvals.
+type ApprovalConfig struct {
+	// ChannelID is the channel where approval requests are posted. If empty, they are posted in the requesting channel.
+	ChannelID string `yaml:"channelID"`
+	// TTL is how long a request can be approved, after that the run has to be requested again.
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// WatchConfig holds settings for tracking launched jobs until they finish.
+type WatchConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	Timeout      time.Duration `yaml:"timeout"`
+	PollInterval time.Duration `yaml:"pollInterval"`
+	// Warnings posts Warning events and failing container states of the job pods while the job runs.
+	Warnings bool `yaml:"warnings"`
+	// MaxWait caps how long a run waits for the job to finish before replying.
+	MaxWait time.Duration `yaml:"maxWait"`
+	// Progress posts a message with the elapsed time and pod phases, updated every ProgressInterval
+	// and replaced with the result when the job finishes.
+	Progress         bool          `yaml:"progress"`
+	ProgressInterval time.Duration `yaml:"progressInterval"`
+}
+
+// LogsConfig holds settings for attaching job logs to follow-up messages.
+type LogsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxBytes limits the size of attached logs. Only the last MaxBytes are kept, unless they are uploaded.
+	MaxBytes int `yaml:"maxBytes"`
+	// Upload shares logs longer than MaxBytes as a Slack file instead of truncating them. It needs the
+	// files:write scope of the bot token.
+	Upload bool `yaml:"upload"`
+}
+
+// CleanupConfig holds settings for removing finished jobs.
+type CleanupConfig struct {
+	// TTLAfterFinished is set as ttlSecondsAfterFinished on created jobs. Zero keeps the value of the template.
+	TTLAfterFinished time.Duration `yaml:"ttlAfterFinished"`
+	// OlderThan is the default age of finished jobs removed by the cleanup command.
+	OlderThan time.Duration `yaml:"olderThan"`
+}
+
+// HistoryConfig holds settings for recording runs in a ConfigMap of the cluster they run on.
+type HistoryConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Namespace and ConfigMap locate the history. The plugin needs RBAC to get, create and update it.
+	Namespace string `yaml:"namespace"`
+	ConfigMap string `yaml:"configMap"`
+	// MaxEntries is the number of most recent runs kept.
+	MaxEntries int `yaml:"maxEntries"`
+}
+
+// VisibilityConfig holds the visibility of messages, "private" or "public".
+type VisibilityConfig struct {
+	// Form is the visibility of the job form, private by default.
+	Form string `yaml:"form"`
+	// Results is the visibility of run confirmations, results and follow-up messages about the run.
+	// When empty, confirmations are private and results are posted to the channel.
+	Results string `yaml:"results"`
+	// Channels overrides the visibility per channel ID. The "*" entry applies to channels not listed.
+	Channels map[string]VisibilityConfig `yaml:"channels,omitempty"`
+}
+
+// AnnounceConfig holds the channel every run is announced in, e.g. an ops or audit channel.
+type AnnounceConfig struct {
+	// ChannelID is the channel announcements are posted to. Empty disables announcements.
+	ChannelID string `yaml:"channelID"`
+}
+
+// PolicyConfig holds settings for checking jobs against admission policies, e.g. of Gatekeeper or Kyverno.
+type PolicyConfig struct {
+	// Check sends every job to the API server with a server-side dry run before creating it,
+	// so policy violations are reported to the user instead of a failed create.
+	Check bool `yaml:"check"`
+}
+
+// WebhookConfig holds the endpoint notified when a job is created, e.g. a change management system.
+type WebhookConfig struct {
+	// URL is the endpoint the payload is posted to. Empty disables the webhook.
+	URL string `yaml:"url"`
+	// Headers are added to the request. Values may reference env vars of the plugin, e.g. "Bearer ${TOKEN}".
+	Headers map[string]string `yaml:"headers"`
+	// Template is a Go template of the JSON payload, see webhookData for the available fields.
+	Template string        `yaml:"template"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// DashboardLink holds a link to a monitoring dashboard of created jobs, e.g. Grafana, Lens or Datadog.
+type DashboardLink struct {
+	// Name is the button label.
+	Name string `yaml:"name"`
+	// URL is a Go template with Job, Kind, Namespace, JobName, Cluster and StartedAt fields, see dashboardData.
+	URL string `yaml:"url"`
+	// Namespaces limits the link to jobs created in the given namespaces.
+	Namespaces NamespacesConfig `yaml:"namespaces"`
+}
+
+// ClusterConfig holds the connection to a cluster.
+type ClusterConfig struct {
+	Name string `yaml:"name"`
+	// KubeConfig is the path to the kubeconfig file of the cluster. Empty uses the Botkube cluster.
+	KubeConfig string `yaml:"kubeConfig"`
+	// Context is the kubeconfig context to use, the current context by default.
+	Context string `yaml:"context"`
+}
+
+var defaultConfig = Config{
+	BotName:         "@Botkube",
+	JobNameTemplate: "{{.CronJob}}-{{.Timestamp}}",
+	Discovery: DiscoveryConfig{
+		ArgsConfigMap:      "botkube-job-args",
+		DefinitionSelector: "botkube.io/job-definition=true",
+		CacheTTL:           time.Minute,
+	},
+	Approval: ApprovalConfig{
+		TTL: time.Hour,
+	},
+	Watch: WatchConfig{
+		Enabled:          true,
+		Timeout:          24 * time.Hour,
+		PollInterval:     10 * time.Second,
+		Warnings:         true,
+		MaxWait:          15 * time.Minute,
+		Progress:         true,
+		ProgressInterval: 30 * time.Second,
+	},
+	Logs: LogsConfig{
+		Enabled:  true,
+		MaxBytes: 3000,
+		Upload:   true,
+	},
+	Cleanup: CleanupConfig{
+		TTLAfterFinished: 7 * 24 * time.Hour,
+		OlderThan:        24 * time.Hour,
+	},
+	Webhook: WebhookConfig{
+		Timeout: 10 * time.Second,
+	},
+	History: HistoryConfig{
+		Namespace:  "botkube",
+		ConfigMap:  "botkube-job-history",
+		MaxEntries: 200,
+	},
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// newConfigMapArgs returns a lookup of args definitions stored in a per-namespace ConfigMap.
+// ConfigMaps are fetched lazily and only once per namespace.
+func newConfigMapArgs(ctx context.Context, envs map[string]string, name string) func(namespace, cronJob string) string {
+	cache := map[string]map[string]string{}
+	return func(namespace, cronJob string) string {
+		if name == "" {
+			return ""
+		}
+		data, ok := cache[namespace]
+		if !ok {
+			data = getConfigMapData(ctx, envs, namespace, name)
+			cache[namespace] = data
+		}
+		return data[cronJob]
+	}
+}
+
+func getConfigMapData(ctx context.Context, envs map[string]string, namespace, name string) map[string]string {
+	getCmd := fmt.Sprintf("kubectl get configmap -n %s %s -ojson", namespace, name)
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return nil
+	}
+
+	var cm corev1.ConfigMap
+	if err := json.Unmarshal([]byte(out.Stdout), &cm); err != nil {
+		return nil
+	}
+	return cm.Data
+}
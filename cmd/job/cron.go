@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// cronPresets are offered in the schedule editor next to the free-text input.
+var cronPresets = []api.OptionItem{
+	{Name: "Every 15 minutes", Value: "*/15 * * * *"},
+	{Name: "Hourly", Value: "@hourly"},
+	{Name: "Daily at midnight", Value: "@daily"},
+	{Name: "Weekdays at 6:00", Value: "0 6 * * 1-5"},
+	{Name: "Weekly on Sunday", Value: "@weekly"},
+	{Name: "Monthly", Value: "@monthly"},
+}
+
+var cronMacros = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// cronField describes the allowed values of a cron schedule field.
+type cronField struct {
+	Name     string
+	Min, Max int
+	Names    []string
+}
+
+var cronFields = []cronField{
+	{Name: "minute", Min: 0, Max: 59},
+	{Name: "hour", Min: 0, Max: 23},
+	{Name: "day of month", Min: 1, Max: 31},
+	{Name: "month", Min: 1, Max: 12, Names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{Name: "day of week", Min: 0, Max: 7, Names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// validateCron checks the schedule the same way as the standard cron format used by CronJobs:
+// five fields or one of the @ macros, optionally prefixed with CRON_TZ= or TZ=.
+func validateCron(schedule string) error {
+	fields := strings.Fields(schedule)
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=")) {
+		fields = fields[1:]
+	}
+	if len(fields) == 1 && slices.Contains(cronMacros, fields[0]) {
+		return nil
+	}
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("expected %d fields or a macro like @daily, got %q", len(cronFields), schedule)
+	}
+	for i, field := range cronFields {
+		if err := validateCronField(field, fields[i]); err != nil {
+			return fmt.Errorf("invalid %s %q: %v", field.Name, fields[i], err)
+		}
+	}
+	return nil
+}
+
+func validateCronField(field cronField, value string) error {
+	for _, item := range strings.Split(value, ",") {
+		rng, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n <= 0 {
+				return fmt.Errorf("step %q is not a positive number", step)
+			}
+		}
+		if rng == "*" || rng == "?" {
+			continue
+		}
+		from, to, isRange := strings.Cut(rng, "-")
+		if !isRange {
+			to = from
+		}
+		lo, err := parseCronValue(field, from)
+		if err != nil {
+			return err
+		}
+		hi, err := parseCronValue(field, to)
+		if err != nil {
+			return err
+		}
+		if lo > hi {
+			return fmt.Errorf("range %s is reversed", rng)
+		}
+	}
+	return nil
+}
+
+func parseCronValue(field cronField, value string) (int, error) {
+	if idx := slices.Index(field.Names, strings.ToUpper(value)); idx >= 0 {
+		return idx + field.Min, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	if n < field.Min || n > field.Max {
+		return 0, fmt.Errorf("%d is out of range %d-%d", n, field.Min, field.Max)
+	}
+	return n, nil
+}
+
+// findCronJob returns the CronJob discovered for the user in this channel.
+func findCronJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, name, namespace string) (Job, error) {
+	jobs, err := getBotkubeJobs(ctx, envs, cfg, scope)
+	for _, job := range jobs {
+		if job.Kind == kindCronJob && job.Name == name && (namespace == "" || job.Namespace == namespace) {
+			return job, nil
+		}
+	}
+	if err != nil {
+		return Job{}, fmt.Errorf("while listing jobs: %w", err)
+	}
+	return Job{}, fmt.Errorf("CronJob %s not found", name)
+}
+
+// editCronSchedule renders the schedule editor, or the confirmation if a new schedule was chosen.
+// Usage: job schedule-edit <name> <namespace> [schedule]
+func editCronSchedule(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string) executor.ExecuteOutput {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Usage: %s schedule-edit <name> <namespace> [schedule]", pluginName), true),
+		}
+	}
+	name, namespace := fields[0], fields[1]
+	// text inputs are passed quoted
+	schedule := strings.Trim(strings.Join(fields[2:], " "), `"`)
+
+	job, err := findCronJob(ctx, envs, cfg, scope, name, namespace)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	cmdPrefix := func(cmd string) string {
+		return fmt.Sprintf("%s %s %s", api.MessageBotNamePlaceholder, pluginName, cmd)
+	}
+	editCmd := fmt.Sprintf("schedule-edit %s %s", name, namespace)
+	sections := []api.Section{
+		{
+			Base: api.Base{
+				Header: fmt.Sprintf("Schedule of %s", name),
+			},
+			TextFields: api.TextFields{
+				{Key: "Current", Value: job.Schedule},
+			},
+			Selects: api.Selects{
+				ID: "select-cron",
+				Items: []api.Select{
+					{
+						Name:    "Preset",
+						Command: cmdPrefix(editCmd),
+						OptionGroups: []api.OptionGroup{
+							{Name: "Preset", Options: cronPresets},
+						},
+					},
+				},
+			},
+			PlaintextInputs: api.LabelInputs{
+				{
+					Command:          cmdPrefix(editCmd + " "),
+					Text:             "Cron schedule",
+					Placeholder:      "e.g. 30 2 * * *",
+					DispatchedAction: api.DispatchInputActionOnEnter,
+				},
+			},
+		},
+	}
+
+	if schedule != "" {
+		if err := validateCron(schedule); err != nil {
+			sections = append(sections, api.Section{
+				Base: api.Base{
+					Header: "Invalid schedule",
+				},
+				BulletLists: api.BulletLists{
+					{Items: []string{err.Error()}},
+				},
+			})
+		} else {
+			btnBuilder := api.NewMessageButtonBuilder()
+			sections = append(sections, api.Section{
+				Base: api.Base{
+					Body: api.Body{
+						CodeBlock: fmt.Sprintf("%s -> %s", job.Schedule, schedule),
+					},
+				},
+				Buttons: []api.Button{
+					btnBuilder.ForCommandWithoutDesc("Apply schedule", fmt.Sprintf("%s schedule-apply %s %s %s", pluginName, name, namespace, schedule), api.ButtonStylePrimary),
+				},
+			})
+		}
+	}
+
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections:          sections,
+			OnlyVisibleForYou: true,
+			ReplaceOriginal:   schedule != "",
+		},
+	}
+}
+
+// applyCronSchedule patches the schedule of the CronJob. Usage: job schedule-apply <name> <namespace> <schedule>
+func applyCronSchedule(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string) executor.ExecuteOutput {
+	fields := strings.Fields(value)
+	if len(fields) < 3 {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Usage: %s schedule-apply <name> <namespace> <schedule>", pluginName), true),
+		}
+	}
+	name, namespace, schedule := fields[0], fields[1], strings.Join(fields[2:], " ")
+	if err := validateCron(schedule); err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	job, err := findCronJob(ctx, envs, cfg, scope, name, namespace)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	patchCmd := fmt.Sprintf(`kubectl patch cronjob -n %s %s --type merge -p '{"spec":{"schedule":%q}}'`, job.Namespace, job.Name, schedule)
+	if _, err := kubectl(ctx, envs, patchCmd); err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("while updating schedule of cronjob %s: %v", job.Name, err), true),
+		}
+	}
+	invalidateJobsCache()
+
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			BaseBody: api.Body{
+				Plaintext: fmt.Sprintf("Schedule of CronJob %s was changed from `%s` to `%s` by %s", job.Name, job.Schedule, schedule, scope.User.Mention),
+			},
+			ReplaceOriginal: true,
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+)
+
+// dashboardData holds the fields available in dashboard URL templates.
+type dashboardData struct {
+	Job       string
+	Kind      string
+	Namespace string
+	JobName   string
+	Cluster   string
+	// StartedAt is the creation time of the job in Unix milliseconds, e.g. for the Grafana from parameter.
+	StartedAt string
+}
+
+// dashboardButtons renders the configured dashboard links of the created job as URL buttons.
+// Links that fail to render, or don't render an absolute http(s) URL, are logged and skipped,
+// as Slack rejects the whole message with an invalid button URL.
+func dashboardButtons(cfg Config, req runRequest, jobName string, now time.Time) []api.Button {
+	data := dashboardData{
+		Job:       req.Name,
+		Kind:      req.Kind,
+		Namespace: req.Namespace,
+		JobName:   jobName,
+		Cluster:   req.Cluster,
+		StartedAt: strconv.FormatInt(now.UnixMilli(), 10),
+	}
+	btnBuilder := api.NewMessageButtonBuilder()
+	var buttons []api.Button
+	for _, link := range cfg.Dashboards {
+		if !link.Namespaces.IsAllowed(req.Namespace) {
+			continue
+		}
+		target, err := renderDashboardURL(link.URL, data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to render dashboard link %q of job %s: %v", link.Name, jobName, err)
+			continue
+		}
+		buttons = append(buttons, btnBuilder.ForURL(link.Name, target))
+	}
+	return buttons
+}
+
+// renderDashboardURL renders the URL template. Values are inserted as is, the urlquery function escapes them.
+func renderDashboardURL(text string, data dashboardData) (string, error) {
+	tmpl, err := template.New("dashboard").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL template: %w", err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("while rendering URL template: %w", err)
+	}
+	target := strings.TrimSpace(out.String())
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", target, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("URL %q is not an absolute http(s) URL", target)
+	}
+	return target, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultFrom reads the default value of an arg from a cluster object when the form is rendered,
+// e.g. the current image tag of a deployment or its replica count.
+type DefaultFrom struct {
+	// Resource is the resource type passed to kubectl get, e.g. deployment.
+	Resource string `json:"resource"`
+	// Name of the object to read.
+	Name string `json:"name"`
+	// Namespace of the object. Defaults to the CronJob namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// JSONPath selects the value from the object, e.g. .spec.replicas
+	JSONPath string `json:"jsonpath"`
+	// Pattern optionally extracts a part of the value: its first group, or the whole match without groups.
+	// For example :([^:@]+)$ turns the image registry.io/api:1.4.2 into 1.4.2
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// getDefaultFrom reads the value of the cluster object.
+func getDefaultFrom(ctx context.Context, envs map[string]string, namespace string, from DefaultFrom) (string, error) {
+	if from.Resource == "" || from.Name == "" || from.JSONPath == "" {
+		return "", fmt.Errorf("defaultFrom requires resource, name and jsonpath")
+	}
+	if from.Namespace != "" {
+		namespace = from.Namespace
+	}
+
+	jsonPath := strings.TrimSuffix(strings.TrimPrefix(from.JSONPath, "{"), "}")
+	getCmd := fmt.Sprintf("kubectl get %s %s -n %s -o 'jsonpath={%s}'", from.Resource, from.Name, namespace, jsonPath)
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return "", fmt.Errorf("while getting %s %s: %w", from.Resource, from.Name, err)
+	}
+	value := strings.TrimSpace(out.Stdout)
+	if from.Pattern == "" {
+		return value, nil
+	}
+
+	re, err := regexp.Compile(from.Pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid defaultFrom pattern: %w", err)
+	}
+	match := re.FindStringSubmatch(value)
+	switch {
+	case match == nil:
+		return "", fmt.Errorf("value %q of %s %s doesn't match %s", value, from.Resource, from.Name, from.Pattern)
+	case len(match) > 1:
+		return match[1], nil
+	}
+	return match[0], nil
+}
+
+// withLiveDefaults returns a copy of the args with defaults read from the cluster. When the lookup fails,
+// or a dropdown doesn't offer the current value, the static default is kept.
+func withLiveDefaults(ctx context.Context, envs map[string]string, namespace string, args []Arg) []Arg {
+	if !slices.ContainsFunc(args, func(a Arg) bool { return a.DefaultFrom != nil }) {
+		return args
+	}
+	out := slices.Clone(args)
+	for i, arg := range out {
+		if arg.DefaultFrom == nil {
+			continue
+		}
+		value, err := getDefaultFrom(ctx, envs, namespace, *arg.DefaultFrom)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get default of %s: %v", argName(arg), err)
+			continue
+		}
+		if value == "" || (len(arg.Values) > 0 && !slices.Contains(arg.Values, value)) {
+			continue
+		}
+		out[i].Default = value
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// jobDefinitionKey is the ConfigMap data key holding the job definition.
+const jobDefinitionKey = "definition.yaml"
+
+// JobDefinition describes a runnable job outside of the target annotations, so args are not size-limited.
+type JobDefinition struct {
+	// Kind of the target, CronJob (default) or Job for a Job template.
+	Kind string `json:"kind"`
+	// Name of the target CronJob or Job template.
+	Name string `json:"name"`
+	// Namespace of the target, defaults to the ConfigMap namespace.
+	Namespace string `json:"namespace"`
+	Args      []Arg  `json:"args"`
+}
+
+// key identifies the definition target.
+func (d JobDefinition) key() string {
+	return workloadKey(d.Kind, d.Namespace, d.Name)
+}
+
+func workloadKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// getJobDefinitions returns definitions from ConfigMaps matching the configured label selector.
+// Invalid definitions are skipped and reported on stderr.
+func getJobDefinitions(ctx context.Context, envs map[string]string, cfg Config) []JobDefinition {
+	defs, invalid := listJobDefinitions(ctx, envs, cfg)
+	for _, problem := range invalid {
+		fmt.Fprintf(os.Stderr, "skipping job definition %s", problem)
+	}
+	return defs
+}
+
+// listJobDefinitions returns the valid definitions and describes the ConfigMaps with invalid ones,
+// e.g. "team-a/backup: missing target name".
+func listJobDefinitions(ctx context.Context, envs map[string]string, cfg Config) ([]JobDefinition, []string) {
+	if cfg.Discovery.DefinitionSelector == "" {
+		return nil, nil
+	}
+
+	resource := fmt.Sprintf("configmaps -l %s", cfg.Discovery.DefinitionSelector)
+	var defs []JobDefinition
+	var invalid []string
+	for _, runCmd := range listCommands(cfg, resource) {
+		out, err := kubectl(ctx, envs, runCmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list job definitions: %v", err)
+			continue
+		}
+		var list corev1.ConfigMapList
+		if err := json.Unmarshal([]byte(out.Stdout), &list); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to unmarshal job definitions: %v", err)
+			continue
+		}
+
+		for _, cm := range list.Items {
+			def, err := parseJobDefinition(cm)
+			if err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s/%s: %v", cm.Namespace, cm.Name, err))
+				continue
+			}
+			defs = append(defs, def)
+		}
+	}
+	return defs, invalid
+}
+
+func parseJobDefinition(cm corev1.ConfigMap) (JobDefinition, error) {
+	raw, ok := cm.Data[jobDefinitionKey]
+	if !ok {
+		return JobDefinition{}, fmt.Errorf("missing %s key", jobDefinitionKey)
+	}
+
+	var def JobDefinition
+	if err := yaml.UnmarshalStrict([]byte(raw), &def); err != nil {
+		return JobDefinition{}, fmt.Errorf("while parsing %s: %w", jobDefinitionKey, err)
+	}
+	if def.Kind == "" {
+		def.Kind = kindCronJob
+	}
+	if def.Kind != kindCronJob && def.Kind != kindJob {
+		return JobDefinition{}, fmt.Errorf("unsupported kind %q, expected %s or %s", def.Kind, kindCronJob, kindJob)
+	}
+	if def.Name == "" {
+		return JobDefinition{}, fmt.Errorf("missing target name")
+	}
+	if def.Namespace == "" {
+		def.Namespace = cm.Namespace
+	}
+	return def, nil
+}
+
+// hasJobDefinition returns true if a job definition targets the given workload.
+func hasJobDefinition(ctx context.Context, envs map[string]string, cfg Config, kind, namespace, name string) bool {
+	for _, def := range getJobDefinitions(ctx, envs, cfg) {
+		if def.key() == workloadKey(kind, namespace, name) {
+			return true
+		}
+	}
+	return false
+}
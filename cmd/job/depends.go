@@ -0,0 +1,71 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// argValue returns the value selected or typed for the arg. Select values are stored as "<flag> <value>".
+func argValue(details stateDetails, arg Arg) string {
+	raw := details.params[argKey(details.job, arg)]
+	if isSelectArg(arg) {
+		return strings.TrimSpace(strings.TrimPrefix(raw, arg.Flag))
+	}
+	return raw
+}
+
+// isArgVisible evaluates the dependsOn expression of the arg against the current selections.
+// The expression is a comma-separated list of conditions that all must match, each in the form of
+// <flag>=<value> or <flag>!=<value>, where the value may list alternatives separated with "|".
+func isArgVisible(arg Arg, args []Arg, details stateDetails) bool {
+	return isArgVisibleWithSeen(arg, args, details, map[string]bool{})
+}
+
+// isArgVisibleWithSeen tracks visited args, so cyclic dependencies hide the args instead of looping forever.
+func isArgVisibleWithSeen(arg Arg, args []Arg, details stateDetails, seen map[string]bool) bool {
+	if arg.DependsOn == "" {
+		return true
+	}
+	if seen[arg.Flag] {
+		return false
+	}
+	seen[arg.Flag] = true
+	defer delete(seen, arg.Flag)
+
+	for _, cond := range strings.Split(arg.DependsOn, ",") {
+		negate := false
+		flag, expected, found := strings.Cut(cond, "!=")
+		if found {
+			negate = true
+		} else {
+			flag, expected, found = strings.Cut(cond, "=")
+			if !found {
+				return false
+			}
+		}
+		flag = strings.TrimSpace(flag)
+
+		idx := slices.IndexFunc(args, func(a Arg) bool { return a.Flag == flag })
+		if idx < 0 || !isArgVisibleWithSeen(args[idx], args, details, seen) {
+			return false
+		}
+		value := argValue(details, args[idx])
+		matches := slices.Contains(strings.Split(strings.TrimSpace(expected), "|"), value)
+		if matches == negate {
+			return false
+		}
+	}
+	return true
+}
+
+// visibleArgs returns args whose dependsOn conditions are met.
+func visibleArgs(args []Arg, details stateDetails) []Arg {
+	var out []Arg
+	for _, arg := range args {
+		if !isArgVisible(arg, args, details) {
+			continue
+		}
+		out = append(out, arg)
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// invalidJobLabel marks jobs with an invalid args definition in the job select.
+const invalidJobLabel = "(invalid args)"
+
+// showDoctor lists jobs whose args definition cannot be used, with the problems found, so owners can fix
+// their annotations or definitions. Definition ConfigMaps that cannot be parsed at all are listed as well,
+// as their targets are not discovered otherwise.
+func showDoctor(ctx context.Context, envs map[string]string, cfg Config, scope jobScope) executor.ExecuteOutput {
+	jobs, err := getBotkubeJobs(ctx, envs, cfg, scope)
+	var sections []api.Section
+	if err != nil {
+		sections = append(sections, api.Section{
+			Base: api.Base{
+				Header:      "Job discovery",
+				Description: "Workloads that could not be listed:",
+			},
+			BulletLists: api.BulletLists{
+				{Items: strings.Split(err.Error(), "\n")},
+			},
+		})
+	}
+	for _, job := range jobs {
+		if len(job.Issues) == 0 {
+			continue
+		}
+		sections = append(sections, api.Section{
+			Base: api.Base{
+				Header:      fmt.Sprintf("%s %s/%s", job.Kind, job.Namespace, job.Name),
+				Description: "Invalid args definition:",
+			},
+			BulletLists: api.BulletLists{
+				{Items: job.Issues},
+			},
+		})
+	}
+
+	_, invalid := listJobDefinitions(ctx, envs, cfg)
+	var definitions []string
+	for _, problem := range invalid {
+		namespace, _, _ := strings.Cut(problem, "/")
+		if cfg.Namespaces.IsAllowed(namespace) {
+			definitions = append(definitions, problem)
+		}
+	}
+	if len(definitions) > 0 {
+		sections = append(sections, api.Section{
+			Base: api.Base{
+				Header:      "Job definitions",
+				Description: fmt.Sprintf("ConfigMaps matching %s that are skipped:", cfg.Discovery.DefinitionSelector),
+			},
+			BulletLists: api.BulletLists{
+				{Items: definitions},
+			},
+		})
+	}
+
+	if len(sections) == 0 {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("All job definitions are valid", true),
+		}
+	}
+	if len(sections) > maxListedJobs {
+		sections = append(sections[:maxListedJobs], api.Section{
+			Context: api.ContextItems{
+				{Text: fmt.Sprintf("%d more problems are not shown, check the plugin logs", len(sections)-maxListedJobs)},
+			},
+		})
+	}
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			BaseBody: api.Body{
+				Plaintext: "Jobs that cannot be run until their definition is fixed",
+			},
+			Sections:          sections,
+			OnlyVisibleForYou: true,
+		},
+	}
+}
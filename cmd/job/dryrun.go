@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// dryRunJob sends the rendered Job to the API server with a server-side dry run,
+// so admission webhooks and validation run without creating the job.
+func dryRunJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string) executor.ExecuteOutput {
+	req, err := parseRunRequest(value, scope)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	source, err := authorizeRun(ctx, envs, cfg, scope, req)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	req = withLimitDefaults(req, source.Metadata.Annotations)
+
+	jobName, err := renderJobName(cfg, req, time.Now())
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	job, _, err := renderJob(ctx, envs, cfg, req, jobName)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	manifest, err := json.Marshal(job)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("while marshalling job: %v", err), true),
+		}
+	}
+
+	if err := serverDryRun(ctx, envs, req.Name, manifest); err != nil {
+		var violations policyViolations
+		if errors.As(err, &violations) {
+			return executor.ExecuteOutput{
+				Message: policyViolationsMessage(violations),
+			}
+		}
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Dry run of job %s failed:\n%s", req.Name, err), true),
+		}
+	}
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(fmt.Sprintf("Dry run of job %s succeeded, the job passes validation and admission", req.Name), true),
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+const retryGuidance = "This is usually a temporary problem, try again. If it keeps failing, ask your Botkube administrator to check the plugin logs."
+
+// errorOutput reports a failure that used to stop the plugin process. The command can be retried with the button,
+// as the plugin keeps serving other interactions.
+func errorOutput(err error, retryCommand string) executor.ExecuteOutput {
+	section := api.Section{
+		Base: api.Base{
+			Header: ":red_circle: Something went wrong",
+			Body: api.Body{
+				CodeBlock: err.Error(),
+			},
+		},
+		Context: api.ContextItems{
+			{Text: retryGuidance},
+		},
+	}
+	if cmd, found := strings.CutPrefix(strings.TrimSpace(retryCommand), pluginName); found && cmd != "" {
+		btnBuilder := api.NewMessageButtonBuilder()
+		section.Buttons = api.Buttons{
+			btnBuilder.ForCommandWithoutDesc("Retry", fmt.Sprintf("%s%s", pluginName, cmd), api.ButtonStyleDanger),
+		}
+	}
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections:          []api.Section{section},
+			OnlyVisibleForYou: true,
+		},
+	}
+}
+
+// discoveryError reports that no jobs could be listed, with a button listing them again.
+func discoveryError(err error) executor.ExecuteOutput {
+	return errorOutput(fmt.Errorf("while listing jobs: %w", err), fmt.Sprintf("%s refresh", pluginName))
+}
+
+// discoveryContext notes that some jobs could not be listed, so a partial list isn't taken for the full one.
+func discoveryContext(err error) api.ContextItems {
+	if err == nil {
+		return nil
+	}
+	return api.ContextItems{
+		{Text: fmt.Sprintf(":warning: Some jobs could not be listed: %s", strings.ReplaceAll(err.Error(), "\n", "; "))},
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// warningContainerReasons are container states reported as soon as they are seen, as the job
+// would otherwise keep retrying silently until its backoff limit or deadline is reached.
+var warningContainerReasons = map[string]struct{}{
+	"ImagePullBackOff":           {},
+	"ErrImagePull":               {},
+	"InvalidImageName":           {},
+	"CrashLoopBackOff":           {},
+	"CreateContainerConfigError": {},
+	"CreateContainerError":       {},
+	"OOMKilled":                  {},
+}
+
+// jobWarning is a problem of a running job, reported once per object and reason.
+type jobWarning struct {
+	Object  string
+	Reason  string
+	Message string
+}
+
+func (w jobWarning) key() string {
+	return w.Object + "/" + w.Reason
+}
+
+// watchJobEvents posts warning events and failing container states of the job pods until ctx is done.
+func watchJobEvents(ctx context.Context, envs map[string]string, interval time.Duration, n *notifier, namespace, name string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	reported := map[string]struct{}{}
+	for {
+		warnings, err := getJobWarnings(ctx, envs, namespace, name)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "failed to get warnings of job %s: %v", name, err)
+		}
+
+		var fresh []jobWarning
+		for _, w := range warnings {
+			if _, ok := reported[w.key()]; ok {
+				continue
+			}
+			reported[w.key()] = struct{}{}
+			fresh = append(fresh, w)
+		}
+		if len(fresh) > 0 {
+			if err := n.Post(ctx, formatJobWarnings(namespace, name, fresh)); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to post warnings of job %s: %v", name, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// getJobWarnings returns Warning events of the job and its pods together with failing container states.
+func getJobWarnings(ctx context.Context, envs map[string]string, namespace, name string) ([]jobWarning, error) {
+	podsCmd := fmt.Sprintf("kubectl get pods -n %s -l job-name=%s -ojson", namespace, name)
+	out, err := kubectl(ctx, envs, podsCmd)
+	if err != nil {
+		return nil, fmt.Errorf("while getting pods of job %s: %w", name, err)
+	}
+	var pods corev1.PodList
+	if err := json.Unmarshal([]byte(out.Stdout), &pods); err != nil {
+		return nil, fmt.Errorf("while unmarshalling pods: %w", err)
+	}
+
+	var warnings []jobWarning
+	objects := map[string]struct{}{"Job/" + name: {}}
+	for _, pod := range pods.Items {
+		objects["Pod/"+pod.Name] = struct{}{}
+		for _, status := range pod.Status.ContainerStatuses {
+			object := fmt.Sprintf("%s/%s", pod.Name, status.Name)
+			if waiting := status.State.Waiting; waiting != nil {
+				if _, ok := warningContainerReasons[waiting.Reason]; ok {
+					warnings = append(warnings, jobWarning{Object: object, Reason: waiting.Reason, Message: waiting.Message})
+				}
+			}
+			for _, term := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+				if term == nil {
+					continue
+				}
+				if _, ok := warningContainerReasons[term.Reason]; ok {
+					warnings = append(warnings, jobWarning{Object: object, Reason: term.Reason, Message: fmt.Sprintf("exit code %d", term.ExitCode)})
+				}
+			}
+		}
+	}
+
+	eventsCmd := fmt.Sprintf("kubectl get events -n %s --field-selector type=Warning -ojson", namespace)
+	out, err = kubectl(ctx, envs, eventsCmd)
+	if err != nil {
+		return warnings, fmt.Errorf("while getting events of job %s: %w", name, err)
+	}
+	var events corev1.EventList
+	if err := json.Unmarshal([]byte(out.Stdout), &events); err != nil {
+		return warnings, fmt.Errorf("while unmarshalling events: %w", err)
+	}
+	for _, event := range events.Items {
+		object := event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name
+		if _, ok := objects[object]; !ok {
+			continue
+		}
+		warnings = append(warnings, jobWarning{Object: object, Reason: event.Reason, Message: event.Message})
+	}
+	return warnings, nil
+}
+
+func formatJobWarnings(namespace, name string, warnings []jobWarning) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, ":warning: Job *%s* in namespace *%s* has problems\n```\n", name, namespace)
+	for _, w := range warnings {
+		fmt.Fprintf(&out, "%s: %s", w.Object, w.Reason)
+		if w.Message != "" {
+			fmt.Fprintf(&out, " - %s", w.Message)
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString("```")
+	return out.String()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/plugin"
+)
+
+const (
+	// kubectlTimeout bounds a single kubectl call, so a hung API server doesn't block the interaction.
+	kubectlTimeout = 30 * time.Second
+	// kubectlAttempts is how many times a kubectl call failing with a transient error is tried.
+	kubectlAttempts = 3
+	// kubectlBackoff is the wait before the first retry, doubled for each next one.
+	kubectlBackoff = 500 * time.Millisecond
+)
+
+var (
+	// unsentErrors mean the request didn't reach the API server, so any command can be retried.
+	unsentErrors = []string{
+		"connection refused",
+		"no such host",
+		"TLS handshake timeout",
+		"Unable to connect to the server",
+	}
+	// transientErrors mean the API server may have processed the request, so only idempotent commands are retried.
+	transientErrors = []string{
+		"the server is currently unable to handle the request",
+		"the server was unable to return a response in the time allotted",
+		"Too many requests",
+		"etcdserver: request timed out",
+		"etcdserver: leader changed",
+		"connection reset by peer",
+		"http2: client connection lost",
+		"i/o timeout",
+		"unexpected EOF",
+	}
+)
+
+// kubectl runs the kubectl command with a timeout derived from ctx, retrying transient failures with backoff.
+// The returned error includes stderr of the last attempt.
+func kubectl(ctx context.Context, envs map[string]string, cmd string) (plugin.ExecuteCommandOutput, error) {
+	return kubectlStdin(ctx, envs, cmd, nil)
+}
+
+// kubectlStdin runs the kubectl command like kubectl, passing stdin to every attempt, e.g. a manifest for "-f -".
+func kubectlStdin(ctx context.Context, envs map[string]string, cmd string, stdin []byte) (plugin.ExecuteCommandOutput, error) {
+	backoff := kubectlBackoff
+	for attempt := 1; ; attempt++ {
+		out, err := kubectlOnce(ctx, envs, cmd, stdin)
+		if err == nil || attempt >= kubectlAttempts || !isRetryable(cmd, out.Stderr) {
+			return out, err
+		}
+		select {
+		case <-ctx.Done():
+			return out, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func kubectlOnce(ctx context.Context, envs map[string]string, cmd string, stdin []byte) (plugin.ExecuteCommandOutput, error) {
+	callCtx, cancel := context.WithTimeout(ctx, kubectlTimeout)
+	defer cancel()
+
+	opts := []plugin.ExecuteCommandMutation{plugin.ExecuteCommandEnvs(envs)}
+	if stdin != nil {
+		opts = append(opts, plugin.ExecuteCommandStdin(bytes.NewReader(stdin)))
+	}
+	out, err := plugin.ExecuteCommand(callCtx, cmd, opts...)
+	if err == nil {
+		return out, nil
+	}
+	// the killed process only reports a signal, name the reason instead
+	switch {
+	case ctx.Err() != nil:
+		return out, fmt.Errorf("%s: %w", kubectlVerb(cmd), ctx.Err())
+	case errors.Is(callCtx.Err(), context.DeadlineExceeded):
+		return out, fmt.Errorf("%s timed out after %s", kubectlVerb(cmd), kubectlTimeout)
+	}
+	return out, err
+}
+
+// isRetryable returns true if the failure is transient and the command is safe to run again.
+func isRetryable(cmd, stderr string) bool {
+	if containsAny(stderr, unsentErrors) {
+		return true
+	}
+	return isIdempotent(cmd) && containsAny(stderr, transientErrors)
+}
+
+// isIdempotent returns true for commands that have the same effect when run twice.
+func isIdempotent(cmd string) bool {
+	if strings.Contains(cmd, "--dry-run") {
+		return true
+	}
+	fields := strings.Fields(cmd)
+	if len(fields) < 2 {
+		return false
+	}
+	switch fields[1] {
+	case "get", "logs", "config", "apply", "patch":
+		return true
+	}
+	return false
+}
+
+// kubectlVerb returns the command up to its verb, e.g. "kubectl get", for error messages.
+func kubectlVerb(cmd string) string {
+	fields := strings.Fields(cmd)
+	return strings.Join(fields[:min(len(fields), 2)], " ")
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/kubeshop/botkube/pkg/plugin"
+)
+
+var fakeOutputNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// fakeKubectl installs a kubectl script as the Botkube dependency. It prints the output of a command from outputs,
+// by its args, e.g. "get cronjob -n team-a backup -ojson", and an empty list for other commands. The returned
+// function returns the args of the commands run so far.
+func fakeKubectl(t *testing.T, outputs map[string]string) func() []string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake kubectl is a shell script")
+	}
+	dir := t.TempDir()
+	outDir := filepath.Join(dir, "out")
+	if err := os.Mkdir(outDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for args, out := range outputs {
+		if err := os.WriteFile(filepath.Join(outDir, fakeOutputNameChars.ReplaceAllString(args, "_")), []byte(out), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	calls := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+printf '%s\n' "$*" >> '` + calls + `'
+f='` + outDir + `'/$(printf '%s' "$*" | tr -c 'A-Za-z0-9._-' '_')
+if [ -f "$f" ]; then cat "$f"; else echo '{"items":[]}'; fi
+`
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(plugin.DependencyDirEnvName, dir)
+
+	return func() []string {
+		data, err := os.ReadFile(calls)
+		if err != nil {
+			return nil
+		}
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+}
+
+// fakeWorkload returns the JSON of a workload with the annotations, as printed by kubectl get -ojson.
+func fakeWorkload(t *testing.T, namespace, name string, annotations map[string]string) string {
+	t.Helper()
+	var workload CronJobs
+	workload.Metadata.Namespace = namespace
+	workload.Metadata.Name = name
+	workload.Metadata.Annotations = annotations
+	return fakeJSON(t, workload)
+}
+
+func fakeJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// testConfig returns the default config without the discovery cache, so each test discovers its own jobs.
+func testConfig() Config {
+	cfg := defaultConfig
+	cfg.Discovery.CacheTTL = 0
+	return cfg
+}
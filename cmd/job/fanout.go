@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// namespaceSeparator separates namespaces of a fan-out run: job run <cronjob> <ns1>,<ns2> [args...]
+// Namespace names cannot contain commas, so a single namespace is never mistaken for a list.
+const namespaceSeparator = ","
+
+// jobNamespaces returns the namespaces the job of the given kind and name exists in, in discovery order.
+func jobNamespaces(jobs []Job, name string) []string {
+	var namespaces []string
+	for _, job := range jobs {
+		if job.Name == name && !slices.Contains(namespaces, job.Namespace) {
+			namespaces = append(namespaces, job.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// uniqueJobOptions returns one option per job name, jobs existing in several namespaces are listed once.
+// Slack options cannot be disabled, so jobs with an invalid args definition are labeled instead.
+func uniqueJobOptions(jobs []Job) []api.OptionItem {
+	var options []api.OptionItem
+	for _, job := range jobs {
+		idx := slices.IndexFunc(options, func(o api.OptionItem) bool { return o.Value == job.Name })
+		if idx < 0 {
+			idx = len(options)
+			options = append(options, api.OptionItem{
+				Name:  job.Name,
+				Value: job.Name,
+			})
+		}
+		if len(job.Issues) > 0 {
+			options[idx].Name = fmt.Sprintf("%s %s", job.Name, invalidJobLabel)
+		}
+	}
+	return options
+}
+
+// selectedNamespaces returns the selected namespaces the job exists in, in the order of available.
+func selectedNamespaces(available, selected []string) []string {
+	var out []string
+	for _, ns := range available {
+		if slices.Contains(selected, ns) {
+			out = append(out, ns)
+		}
+	}
+	return out
+}
+
+// namespacesSection renders the multi-select of namespaces a job is run in.
+func namespacesSection(available, selected []string, command string) api.Section {
+	var options, initial []api.OptionItem
+	for _, ns := range available {
+		option := api.OptionItem{Name: ns, Value: ns}
+		options = append(options, option)
+		if slices.Contains(selected, ns) {
+			initial = append(initial, option)
+		}
+	}
+	return api.Section{
+		MultiSelect: api.MultiSelect{
+			Name: "Namespaces",
+			Description: api.Body{
+				Plaintext: "The job exists in several namespaces, select where to run it",
+			},
+			Command:        command,
+			Options:        options,
+			InitialOptions: initial,
+		},
+	}
+}
+
+// isFanOut returns true if the run value targets more than one namespace.
+func isFanOut(value string) bool {
+	_, rest := cutField(value)
+	namespace, _ := cutField(rest)
+	return strings.Contains(namespace, namespaceSeparator)
+}
+
+// isMultiRun returns true if the run value creates more than one job, in several namespaces or with matrix args.
+func isMultiRun(value string) bool {
+	if isFanOut(value) {
+		return true
+	}
+	req, err := parseRunRequest(value, jobScope{})
+	return err == nil && len(expandMatrix(req)) > 1
+}
+
+// runFanOut runs the job in each listed namespace, once per combination of matrix values, and reports the
+// outcome per run. Each namespace is checked on its own, so one denied or busy namespace does not stop the others.
+func runFanOut(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, in executor.ExecuteInput, value string, force bool) executor.ExecuteOutput {
+	name, rest := cutField(value)
+	list, rest := cutField(rest)
+
+	var items, busy []string
+	var sections []api.Section
+	for _, namespace := range strings.Split(list, namespaceSeparator) {
+		if namespace == "" {
+			continue
+		}
+		req, err := parseRunRequest(strings.TrimSpace(fmt.Sprintf("%s %s %s", name, namespace, rest)), scope)
+		if err != nil {
+			return executor.ExecuteOutput{
+				Message: api.NewCodeBlockMessage(err.Error(), true),
+			}
+		}
+		for i, run := range expandMatrix(req) {
+			// matrix runs in the same namespace would block each other, only the first one checks for active runs
+			status, section := runOne(ctx, envs, cfg, scope, in, run.Request, force || i > 0)
+			if status == "" {
+				busy = append(busy, namespace)
+				items = append(items, fmt.Sprintf("%s: skipped, a previous run is still active", namespace))
+				break
+			}
+			items = append(items, fmt.Sprintf("%s: %s", strings.TrimSpace(namespace+" "+run.Label), status))
+			if section != nil {
+				sections = append(sections, *section)
+			}
+		}
+	}
+
+	btnBuilder := api.NewMessageButtonBuilder()
+	summary := api.Section{
+		Base: api.Base{
+			Header:      fmt.Sprintf("Run of %s", strings.TrimPrefix(name, jobTemplatePrefix)),
+			Description: "Status per run:",
+		},
+		BulletLists: api.BulletLists{
+			{Items: items},
+		},
+		Buttons: []api.Button{
+			btnBuilder.ForCommandWithoutDesc("Runs", fmt.Sprintf("%s list --job %s", pluginName, strings.TrimPrefix(name, jobTemplatePrefix))),
+		},
+	}
+	if len(busy) > 0 {
+		retry := strings.TrimSpace(fmt.Sprintf("%s %s %s", name, strings.Join(busy, namespaceSeparator), rest))
+		summary.Buttons = append(summary.Buttons, btnBuilder.ForCommandWithoutDesc("Run anyway in skipped namespaces", fmt.Sprintf("%s run-anyway %s", pluginName, retry), api.ButtonStyleDanger))
+	}
+
+	msg := cfg.withResultVisibility(scope.Channel, api.Message{
+		Sections:          append([]api.Section{summary}, sections...),
+		OnlyVisibleForYou: true,
+	})
+	// approval requests in the reply have to be visible to the approvers
+	msg.OnlyVisibleForYou = msg.OnlyVisibleForYou && len(sections) == 0
+	return executor.ExecuteOutput{
+		Message: msg,
+	}
+}
+
+// runOne launches a single job of a run in several namespaces or with matrix args. It returns the status of the run, empty when it was skipped
+// because of active runs, and the approval request section if the run needs an approval.
+// These runs do not wait for completion, they are watched in the background.
+func runOne(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, in executor.ExecuteInput, req runRequest, force bool) (string, *api.Section) {
+	source, err := authorizeRun(ctx, envs, cfg, scope, req)
+	if err != nil {
+		return err.Error(), nil
+	}
+	req = withLimitDefaults(req, source.Metadata.Annotations)
+
+	if !force && source.Metadata.Annotations[allowConcurrentAnnotation] != "true" {
+		active, err := activeRuns(ctx, envs, req)
+		if err != nil {
+			return err.Error(), nil
+		}
+		if len(active) > 0 {
+			return "", nil
+		}
+	}
+
+	if source.Metadata.Annotations[requireApprovalAnnotation] == "true" {
+		out := requestApproval(ctx, cfg, in.Context.Message, req)
+		// with an approval channel, the request is posted there and only a confirmation is returned
+		if len(out.Message.Sections) == 0 {
+			return out.Message.BaseBody.CodeBlock, nil
+		}
+		return "waiting for approval", &out.Message.Sections[0]
+	}
+
+	jobName, err := createJob(ctx, envs, cfg, in.Context.KubeConfig, in.Context.Message, req)
+	if err != nil {
+		return err.Error(), nil
+	}
+	recordRecentJob(req)
+	return fmt.Sprintf("started job %s", jobName), nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/kubeshop/botkube/pkg/api"
+)
+
+// maxRecentJobs is the number of recently run jobs listed for each user.
+const maxRecentJobs = 5
+
+// userJobs keeps recently run and favorite jobs of each user, keyed by user ID and cluster.
+// Jobs are stored as <kind>/<name>. They are kept in memory and are lost when the plugin restarts.
+var userJobs = struct {
+	sync.Mutex
+	recent    map[string][]string
+	favorites map[string][]string
+}{
+	recent:    map[string][]string{},
+	favorites: map[string][]string{},
+}
+
+func userJobsKey(scope jobScope) string {
+	return fmt.Sprintf("%s/%s", scope.UserID(), scope.Cluster)
+}
+
+func userJobRef(kind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+// recordRecentJob moves the job to the top of the recently run jobs of the requester.
+func recordRecentJob(req runRequest) {
+	key := fmt.Sprintf("%s/%s", userID(req.Requester), req.Cluster)
+	ref := userJobRef(req.Kind, req.Name)
+
+	userJobs.Lock()
+	defer userJobs.Unlock()
+	recent := slices.DeleteFunc(userJobs.recent[key], func(r string) bool { return r == ref })
+	recent = append([]string{ref}, recent...)
+	if len(recent) > maxRecentJobs {
+		recent = recent[:maxRecentJobs]
+	}
+	userJobs.recent[key] = recent
+}
+
+// toggleFavorite pins or unpins the job for the user.
+func toggleFavorite(scope jobScope, kind, name string) {
+	key, ref := userJobsKey(scope), userJobRef(kind, name)
+
+	userJobs.Lock()
+	defer userJobs.Unlock()
+	if slices.Contains(userJobs.favorites[key], ref) {
+		userJobs.favorites[key] = slices.DeleteFunc(userJobs.favorites[key], func(f string) bool { return f == ref })
+		return
+	}
+	userJobs.favorites[key] = append(userJobs.favorites[key], ref)
+}
+
+func isFavorite(scope jobScope, kind, name string) bool {
+	userJobs.Lock()
+	defer userJobs.Unlock()
+	return slices.Contains(userJobs.favorites[userJobsKey(scope)], userJobRef(kind, name))
+}
+
+// jobOptionGroups splits the job options into the user's favorites, recently run jobs and the rest.
+// Each job is listed only once, in the first group it belongs to.
+func jobOptionGroups(scope jobScope, kind string, options []api.OptionItem) []api.OptionGroup {
+	userJobs.Lock()
+	favorites := slices.Clone(userJobs.favorites[userJobsKey(scope)])
+	recent := slices.Clone(userJobs.recent[userJobsKey(scope)])
+	userJobs.Unlock()
+
+	var groups []api.OptionGroup
+	listed := map[string]bool{}
+	pick := func(name string, refs []string) {
+		var picked []api.OptionItem
+		for _, ref := range refs {
+			idx := slices.IndexFunc(options, func(o api.OptionItem) bool { return userJobRef(kind, o.Value) == ref })
+			if idx < 0 || listed[options[idx].Value] {
+				continue
+			}
+			listed[options[idx].Value] = true
+			picked = append(picked, options[idx])
+		}
+		if len(picked) > 0 {
+			groups = append(groups, api.OptionGroup{Name: name, Options: picked})
+		}
+	}
+	pick("Favorites", favorites)
+	pick("Recent", recent)
+
+	var rest []api.OptionItem
+	for _, option := range options {
+		if !listed[option.Value] {
+			rest = append(rest, option)
+		}
+	}
+	if len(rest) > 0 || len(groups) == 0 {
+		groups = append(groups, api.OptionGroup{Name: "Job Name", Options: rest})
+	}
+	return groups
+}
+
+// favoriteButton pins or unpins the selected job.
+func favoriteButton(btnBuilder *api.ButtonBuilder, scope jobScope, kind, name string) api.Button {
+	if isFavorite(scope, kind, name) {
+		return btnBuilder.ForCommandWithoutDesc("★ Unpin", fmt.Sprintf("%s favorite %s %s", pluginName, kind, name))
+	}
+	return btnBuilder.ForCommandWithoutDesc("☆ Pin to favorites", fmt.Sprintf("%s favorite %s %s", pluginName, kind, name))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	"github.com/slack-go/slack"
+)
+
+// stateFlag carries the form selections in commands: job --state <token> <action> ...
+// Botkube forwards the form state to plugins only for Slack. On other platforms with interactive messages,
+// such as Mattermost and Teams, each select sends only its own value, so the earlier selections are
+// encoded into the commands of the rendered form instead.
+const stateFlag = "--state"
+
+// formState reads the current selections of the interactive form.
+type formState interface {
+	Details() stateDetails
+}
+
+// newFormState returns the Slack form state if Botkube passed it, otherwise the state carried in the command.
+func newFormState(e *MsgExecutor, in executor.ExecuteInput) formState {
+	if in.Context.SlackState != nil {
+		return slackFormState{e: e, state: in.Context.SlackState}
+	}
+	return commandFormState{command: in.Command}
+}
+
+type slackFormState struct {
+	e     *MsgExecutor
+	state *slack.BlockActionStates
+}
+
+func (s slackFormState) Details() stateDetails {
+	return s.e.extractStateDetails(s.state)
+}
+
+// commandFormState decodes the selections carried with the state flag and applies the value of the select
+// that sent the command.
+type commandFormState struct {
+	command string
+}
+
+func (s commandFormState) Details() stateDetails {
+	_, cmd := cutClusterFlag(s.command)
+	token, cmd := cutStateFlag(cmd)
+	details := decodeState(token)
+	details.embedState = true
+
+	action, value := parseCommand(cmd)
+	switch action {
+	case "select_cluster":
+		details.cluster = value
+	case "select_kind":
+		details.kind = value
+	case "select_delay":
+		details.delay = value
+	case "select_first":
+		details.job = value
+	case "select_namespaces":
+		details.namespaces = strings.Split(value, namespaceSeparator)
+	case "select_multi":
+		// multi-selects send "<key> <value>,<value>"
+		key, selected, _ := strings.Cut(value, " ")
+		details.params[key] = selected
+	case "select_dynamic":
+		// selects send "<flag> <value>", inputs send the flag followed by the typed value in quotes
+		key, selected, _ := strings.Cut(value, " ")
+		if idx := strings.Index(selected, `"`); idx >= 0 {
+			selected = strings.Trim(selected[idx:], `"`)
+		}
+		details.params[key] = selected
+	}
+	return details
+}
+
+// encodedState is the serialized form of stateDetails.
+type encodedState struct {
+	Kind       string            `json:"k,omitempty"`
+	Delay      string            `json:"d,omitempty"`
+	Job        string            `json:"j,omitempty"`
+	Namespaces []string          `json:"n,omitempty"`
+	Params     map[string]string `json:"p,omitempty"`
+}
+
+func encodeState(details stateDetails) string {
+	raw, err := json.Marshal(encodedState{Kind: details.kind, Delay: details.delay, Job: details.job, Namespaces: details.namespaces, Params: details.params})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeState returns the selections of the token. Invalid tokens start the form from scratch.
+func decodeState(token string) stateDetails {
+	details := stateDetails{params: map[string]string{}}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return details
+	}
+	var state encodedState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return details
+	}
+	details.kind, details.delay, details.job, details.namespaces = state.Kind, state.Delay, state.Job, state.Namespaces
+	for key, value := range state.Params {
+		details.params[key] = value
+	}
+	return details
+}
+
+// cutStateFlag returns the token of the state flag following the plugin name and the command without it.
+func cutStateFlag(cmd string) (token, rest string) {
+	plugin, args := cutField(cmd)
+	flag, args := cutField(args)
+	if plugin != pluginName || flag != stateFlag {
+		return "", cmd
+	}
+	token, args = cutField(args)
+	return token, plugin + " " + args
+}
+
+// withState adds the state flag after the plugin name of the command when the form state has to be carried in commands.
+func withState(cmd string, details stateDetails) string {
+	if !details.embedState {
+		return cmd
+	}
+	return strings.Replace(cmd, pluginName+" ", pluginName+" "+stateFlag+" "+encodeState(details)+" ", 1)
+}
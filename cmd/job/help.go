@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// showJobHelp describes the args of a job, so users can learn them without starting the form.
+// Usage: job help <name> [namespace]
+func showJobHelp(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string) executor.ExecuteOutput {
+	name, namespace, ok := parseJobRef(value)
+	if !ok {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Usage: %s help <name> [namespace]", pluginName), true),
+		}
+	}
+	job, err := findTextJob(ctx, envs, cfg, scope, name, namespace)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	job.Args = withLiveDefaults(ctx, envs, job.Namespace, job.Args)
+
+	sections := []api.Section{
+		{
+			Base: api.Base{
+				Header:      fmt.Sprintf("%s (%s)", textJobName(job), job.Kind),
+				Description: fmt.Sprintf("Namespace %s", job.Namespace),
+			},
+		},
+	}
+	if len(job.Args) == 0 {
+		sections[0].Base.Body.Plaintext = "The job takes no parameters."
+	}
+
+	// Args are listed by group, in the order of the first arg of the group, the same way as in the form
+	groupIdx := map[string]int{}
+	for _, arg := range job.Args {
+		idx, found := groupIdx[arg.Group]
+		if !found {
+			idx = len(sections)
+			groupIdx[arg.Group] = idx
+			sections = append(sections, api.Section{
+				Base: api.Base{
+					Header: arg.Group,
+				},
+				BulletLists: api.BulletLists{{}},
+			})
+		}
+		sections[idx].BulletLists[0].Items = append(sections[idx].BulletLists[0].Items, describeArg(arg))
+	}
+
+	var usage strings.Builder
+	fmt.Fprintf(&usage, "%s run %s %s", pluginName, textJobName(job), job.Namespace)
+	for _, arg := range job.Args {
+		if !arg.Optional && arg.Type != "bool" && arg.Default == "" && arg.DependsOn == "" {
+			fmt.Fprintf(&usage, " %s <value>", argName(arg))
+		}
+	}
+	sections = append(sections, api.Section{
+		Base: api.Base{
+			Header: "Run",
+			Body: api.Body{
+				CodeBlock: usage.String(),
+			},
+		},
+		Buttons: []api.Button{
+			api.NewMessageButtonBuilder().ForCommandWithoutDesc("Open form", pluginName),
+		},
+	})
+
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections:          sections,
+			OnlyVisibleForYou: true,
+		},
+	}
+}
+
+// describeArg returns a one-line description of the arg, e.g. `--env` dropdown, required: Environment (dev, prod)
+func describeArg(arg Arg) string {
+	var traits []string
+	traits = append(traits, arg.Type)
+	if arg.Optional {
+		traits = append(traits, "optional")
+	} else {
+		traits = append(traits, "required")
+	}
+	if arg.Multiple {
+		traits = append(traits, "one job per value")
+	}
+	if arg.Default != "" {
+		traits = append(traits, fmt.Sprintf("default %s", arg.Default))
+	}
+	if arg.DefaultFrom != nil {
+		traits = append(traits, fmt.Sprintf("default read from %s %s", arg.DefaultFrom.Resource, arg.DefaultFrom.Name))
+	}
+	if arg.Min != nil {
+		traits = append(traits, fmt.Sprintf("min %v", *arg.Min))
+	}
+	if arg.Max != nil {
+		traits = append(traits, fmt.Sprintf("max %v", *arg.Max))
+	}
+	if arg.DependsOn != "" {
+		traits = append(traits, fmt.Sprintf("when %s", arg.DependsOn))
+	}
+
+	desc := fmt.Sprintf("`%s` %s: %s", argName(arg), strings.Join(traits, ", "), arg.Description)
+	switch {
+	case len(arg.Values) > 0:
+		desc += fmt.Sprintf(" (%s)", strings.Join(arg.Values, ", "))
+	case arg.ValuesFrom != nil:
+		desc += fmt.Sprintf(" (%s listed from the cluster)", arg.ValuesFrom.Resource)
+	case arg.Registry != nil:
+		desc += " (image tags listed from the registry)"
+	case arg.Secret != "":
+		desc += fmt.Sprintf(" (keys of Secret %s)", arg.Secret)
+	case arg.Pattern != "":
+		desc += fmt.Sprintf(" (matching %s)", arg.Pattern)
+	}
+	return desc
+}
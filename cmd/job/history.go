@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// historyKey is the ConfigMap key holding the run history as a JSON list, oldest first.
+	historyKey = "runs.json"
+	// outcomeRunning is the outcome of runs whose result is not known yet.
+	outcomeRunning = "Running"
+)
+
+// historyEntry is a single run kept in the history ConfigMap.
+type historyEntry struct {
+	Kind      string `json:"kind"`
+	Job       string `json:"job"`
+	Namespace string `json:"namespace"`
+	// JobName is the name of the created Job.
+	JobName string `json:"jobName"`
+	runAudit
+	// Command is the plugin command that launched the job, used to run it again.
+	Command   string    `json:"command"`
+	Requester string    `json:"requester"`
+	Channel   string    `json:"channel,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	// Outcome is Running until the watch or the waiting run reports the final phase.
+	Outcome string `json:"outcome"`
+}
+
+// historyMu serializes read-modify-write cycles of the history ConfigMap within the plugin.
+var historyMu sync.Mutex
+
+// recordRun adds the created job to the run history. Failures are logged, they don't fail the run.
+func recordRun(ctx context.Context, envs map[string]string, cfg Config, req runRequest, jobName string, now time.Time) {
+	if !cfg.History.Enabled {
+		return
+	}
+	entry := historyEntry{
+		Kind:      req.Kind,
+		Job:       req.Name,
+		Namespace: req.Namespace,
+		JobName:   jobName,
+		runAudit:  auditOf(req),
+		Command:   req.Command,
+		Requester: requesterName(req.Requester),
+		Channel:   req.Channel,
+		StartedAt: now.UTC(),
+		Outcome:   outcomeRunning,
+	}
+	err := updateHistory(ctx, envs, cfg, func(entries []historyEntry) []historyEntry {
+		entries = append(entries, entry)
+		if len(entries) > cfg.History.MaxEntries {
+			entries = entries[len(entries)-cfg.History.MaxEntries:]
+		}
+		return entries
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record run of job %s in history: %v", jobName, err)
+	}
+}
+
+// recordOutcome sets the final phase of the run in the history.
+func recordOutcome(ctx context.Context, envs map[string]string, cfg Config, namespace, jobName, outcome string) {
+	if !cfg.History.Enabled {
+		return
+	}
+	err := updateHistory(ctx, envs, cfg, func(entries []historyEntry) []historyEntry {
+		for i := range entries {
+			if entries[i].JobName == jobName && entries[i].Namespace == namespace {
+				entries[i].Outcome = outcome
+			}
+		}
+		return entries
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record outcome of job %s in history: %v", jobName, err)
+	}
+}
+
+// getHistory returns the recorded runs, oldest first. A missing ConfigMap is an empty history.
+func getHistory(ctx context.Context, envs map[string]string, cfg Config) ([]historyEntry, error) {
+	data := getConfigMapData(ctx, envs, cfg.History.Namespace, cfg.History.ConfigMap)
+	if data[historyKey] == "" {
+		return nil, nil
+	}
+	var entries []historyEntry
+	if err := json.Unmarshal([]byte(data[historyKey]), &entries); err != nil {
+		return nil, fmt.Errorf("while unmarshalling run history: %w", err)
+	}
+	return entries, nil
+}
+
+// updateHistory applies the change to the recorded runs and writes them back to the ConfigMap.
+func updateHistory(ctx context.Context, envs map[string]string, cfg Config, change func([]historyEntry) []historyEntry) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	entries, err := getHistory(ctx, envs, cfg)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(change(entries))
+	if err != nil {
+		return fmt.Errorf("while marshalling run history: %w", err)
+	}
+	cm := corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cfg.History.ConfigMap,
+			Namespace: cfg.History.Namespace,
+			Labels:    map[string]string{managedLabel: "true"},
+		},
+		Data: map[string]string{historyKey: string(raw)},
+	}
+	manifest, err := json.Marshal(cm)
+	if err != nil {
+		return fmt.Errorf("while marshalling history ConfigMap: %w", err)
+	}
+
+	applyCmd := "kubectl apply -f -"
+	if _, err := kubectlStdin(ctx, envs, applyCmd, manifest); err != nil {
+		return fmt.Errorf("while saving run history: %w", err)
+	}
+	return nil
+}
+
+// showHistory renders the most recent runs, optionally of a single job, with buttons to run them again
+// with the same parameters. Runs of jobs not allowed in the channel, or the user cannot run, are not shown.
+func showHistory(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string) executor.ExecuteOutput {
+	if !cfg.History.Enabled {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("Run history is disabled, enable it with history.enabled in the plugin config", true),
+		}
+	}
+	name := strings.TrimSpace(value)
+	entries, err := getHistory(ctx, envs, cfg)
+	if err != nil {
+		return errorOutput(err, withClusterFlag(fmt.Sprintf("%s history %s", pluginName, name), scope.Cluster))
+	}
+	// runs of the same job share the answer, so it is read once
+	allowedUser := map[string]bool{}
+	entries = slices.DeleteFunc(entries, func(e historyEntry) bool {
+		if (name != "" && e.Job != name) || !cfg.isJobAllowed(scope, e.Namespace, e.Job) {
+			return true
+		}
+		key := workloadKey(e.Kind, e.Namespace, e.Job)
+		allowed, ok := allowedUser[key]
+		if !ok {
+			source, err := getWorkload(ctx, envs, sourceResource(e.Kind), e.Namespace, e.Job)
+			allowed = err == nil && isUserAllowed(ctx, cfg, scope, source.Metadata.Annotations)
+			allowedUser[key] = allowed
+		}
+		return !allowed
+	})
+	if len(entries) == 0 {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("No recorded runs found", true),
+		}
+	}
+
+	slices.Reverse(entries)
+	if len(entries) > maxListedJobs {
+		entries = entries[:maxListedJobs]
+	}
+	btnBuilder := api.NewMessageButtonBuilder()
+	var sections []api.Section
+	for _, entry := range entries {
+		section := api.Section{
+			Base: api.Base{
+				Header: entry.JobName,
+			},
+			TextFields: api.TextFields{
+				{Key: "Job", Value: fmt.Sprintf("%s/%s", entry.Namespace, entry.Job)},
+				{Key: "Outcome", Value: entry.Outcome},
+				{Key: "Requester", Value: entry.Requester},
+				{Key: "Started", Value: entry.StartedAt.Format(time.RFC3339)},
+			},
+		}
+		if params := strings.Join(append(entry.Overrides, entry.Args...), " "); params != "" {
+			section.Context = api.ContextItems{
+				{Text: fmt.Sprintf("Params: %s", params)},
+			}
+		}
+		if entry.Command != "" {
+			section.Buttons = api.Buttons{
+				btnBuilder.ForCommandWithoutDesc("Re-run with same params", entry.Command, api.ButtonStylePrimary),
+			}
+		}
+		sections = append(sections, section)
+	}
+
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			BaseBody: api.Body{
+				Plaintext: "Recent runs",
+			},
+			Sections:          sections,
+			OnlyVisibleForYou: true,
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestShowHistoryScope(t *testing.T) {
+	entries := []historyEntry{
+		{Kind: kindCronJob, Job: "backup", Namespace: "team-a", JobName: "backup-1"},
+		{Kind: kindCronJob, Job: "restore", Namespace: "team-a", JobName: "restore-1"},
+		{Kind: kindCronJob, Job: "report", Namespace: "team-b", JobName: "report-1"},
+		{Kind: kindCronJob, Job: "backup", Namespace: "team-a", JobName: "backup-2"},
+	}
+	cm := corev1.ConfigMap{Data: map[string]string{historyKey: fakeJSON(t, entries)}}
+	fakeKubectl(t, map[string]string{
+		"get configmap -n botkube botkube-job-history -ojson": fakeJSON(t, cm),
+		"get cronjob -n team-a backup -ojson":                 fakeWorkload(t, "team-a", "backup", nil),
+		"get cronjob -n team-a restore -ojson":                fakeWorkload(t, "team-a", "restore", map[string]string{allowedUsersAnnotation: "U0999999"}),
+		"get cronjob -n team-b report -ojson":                 fakeWorkload(t, "team-b", "report", nil),
+	})
+	cfg := testConfig()
+	cfg.History.Enabled = true
+	cfg.Channels = map[string]ChannelConfig{"C0123456": {Jobs: []string{"team-a/*"}}}
+	scope := jobScope{Channel: "C0123456", User: executor.User{Mention: "<@U0123456>"}, memberOf: map[string]bool{}}
+
+	out := showHistory(context.Background(), nil, cfg, scope, "")
+
+	var shown []string
+	for _, section := range out.Message.Sections {
+		shown = append(shown, section.Header)
+	}
+	want := []string{"backup-2", "backup-1"}
+	if !slices.Equal(shown, want) {
+		t.Errorf("history shows %q, want %q", shown, want)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// overrideImage replaces the image tag of a container: image:<container>=<tag>
+	// An empty container name targets the first container.
+	overrideImage = "image"
+	// argTargetImage makes the arg value an image tag of a job container.
+	argTargetImage = "image"
+	// maxSelectOptions is the Slack limit of options in a select.
+	maxSelectOptions = 100
+)
+
+// RegistrySource lists image tags from a Docker Registry HTTP API v2 compatible registry.
+type RegistrySource struct {
+	// URL is the registry base URL, e.g. https://ghcr.io
+	URL string `json:"url"`
+	// Repository is the image repository, e.g. org/app
+	Repository string `json:"repository"`
+}
+
+// listRegistryTags returns tags of the repository. Anonymous bearer tokens are requested when the registry asks for them.
+func listRegistryTags(ctx context.Context, src RegistrySource) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tagsURL := fmt.Sprintf("%s/v2/%s/tags/list", strings.TrimSuffix(src.URL, "/"), src.Repository)
+	resp, err := registryGet(ctx, tagsURL, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("Www-Authenticate")
+		resp.Body.Close()
+		token, err := anonymousRegistryToken(ctx, challenge)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = registryGet(ctx, tagsURL, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("while listing tags of %s: %s", src.Repository, string(body))
+	}
+
+	var result struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("while decoding tags list: %w", err)
+	}
+
+	// registries usually return tags in ascending order, keep the most recent ones first
+	tags := result.Tags
+	if len(tags) > maxSelectOptions {
+		tags = tags[len(tags)-maxSelectOptions:]
+	}
+	for i, j := 0, len(tags)-1; i < j; i, j = i+1, j-1 {
+		tags[i], tags[j] = tags[j], tags[i]
+	}
+	return tags, nil
+}
+
+func registryGet(ctx context.Context, rawURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("while calling registry: %w", err)
+	}
+	return resp, nil
+}
+
+// anonymousRegistryToken requests a token for the Bearer challenge, e.g.
+// Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:org/app:pull"
+func anonymousRegistryToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("unsupported registry authentication %q", challenge)
+	}
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if found {
+			params[key] = strings.Trim(value, `"`)
+		}
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("missing realm in registry challenge %q", challenge)
+	}
+
+	query := url.Values{}
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	resp, err := registryGet(ctx, params["realm"]+"?"+query.Encode(), "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("while decoding registry token: %w", err)
+	}
+	if result.Token != "" {
+		return result.Token, nil
+	}
+	return result.AccessToken, nil
+}
+
+// setImageTag replaces the tag (or digest) of the image reference.
+func setImageTag(image, tag string) string {
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		image = image[:idx]
+	}
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		image = image[:idx]
+	}
+	return fmt.Sprintf("%s:%s", image, tag)
+}
+
+// applyImageTag sets the tag of the named container image, or of the first container if the name is empty.
+func applyImageTag(job map[string]interface{}, containerName, tag string) error {
+	for _, container := range jobContainers(job) {
+		if containerName != "" && container["name"] != containerName {
+			continue
+		}
+		image, _ := container["image"].(string)
+		container["image"] = setImageTag(image, tag)
+		return nil
+	}
+	return fmt.Errorf("container %q not found in the job", containerName)
+}
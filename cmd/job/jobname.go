@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// maxJobNameLen is the limit of label values, as the job name is copied to the job-name label of its pods.
+const maxJobNameLen = 63
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// jobNameData holds the fields available in the job name template.
+type jobNameData struct {
+	CronJob   string
+	Namespace string
+	User      string
+	Timestamp string
+}
+
+// renderJobName renders the configured job name template and makes the result a valid DNS-1123 label.
+// The timestamp, followed by the index of matrix runs, tells runs apart, so it always ends the name: the rest
+// of the name is shortened to keep it, and it is appended when the template doesn't end with it.
+func renderJobName(cfg Config, req runRequest, now time.Time) (string, error) {
+	tmpl, err := template.New("jobName").Option("missingkey=error").Parse(cfg.JobNameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid jobNameTemplate: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	var out strings.Builder
+	err = tmpl.Execute(&out, jobNameData{
+		CronJob:   req.Name,
+		Namespace: req.Namespace,
+		User:      requesterName(req.Requester),
+		Timestamp: timestamp,
+	})
+	if err != nil {
+		return "", fmt.Errorf("while rendering jobNameTemplate: %w", err)
+	}
+
+	name := sanitizeName(out.String())
+	if name == "" {
+		return "", fmt.Errorf("jobNameTemplate %q rendered an empty name", cfg.JobNameTemplate)
+	}
+	suffix := timestamp
+	// the runs of a matrix are created in the same second, the timestamp doesn't tell them apart
+	if req.MatrixIndex > 0 {
+		suffix += "-" + strconv.Itoa(req.MatrixIndex)
+	}
+	prefix := strings.TrimRight(strings.TrimSuffix(name, timestamp), "-")
+	if maxPrefix := maxJobNameLen - len(suffix) - 1; len(prefix) > maxPrefix {
+		prefix = strings.TrimRight(prefix[:maxPrefix], "-")
+	}
+	if prefix == "" {
+		return suffix, nil
+	}
+	return prefix + "-" + suffix, nil
+}
+
+// sanitizeName lowercases the name and replaces invalid characters with dashes.
+func sanitizeName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	return strings.Trim(name, "-")
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+func TestRenderJobNameMatrix(t *testing.T) {
+	req, err := parseRunRequest("backup team-a matrix:--region=eu,us matrix:--tier=web,db --", jobScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := expandMatrix(req)
+	if len(runs) != 4 {
+		t.Fatalf("expandMatrix returned %d runs, want 4", len(runs))
+	}
+
+	// all combinations are created in the same second
+	now := time.Unix(1700000000, 0)
+	names := map[string]string{}
+	for _, run := range runs {
+		name, err := renderJobName(defaultConfig, run.Request, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other, ok := names[name]; ok {
+			t.Errorf("runs %q and %q get the same job name %s", other, run.Label, name)
+		}
+		names[name] = run.Label
+	}
+
+	single, err := renderJobName(defaultConfig, req, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "backup-1700000000"; single != want {
+		t.Errorf("renderJobName() = %s, want %s", single, want)
+	}
+}
+
+func TestRenderJobNameLong(t *testing.T) {
+	cfg := defaultConfig
+	cfg.JobNameTemplate = "{{.CronJob}}-{{.User}}-{{.Timestamp}}"
+	now := time.Unix(1700000000, 0)
+	alice := executor.User{DisplayName: "alice"}
+	tests := []struct {
+		name string
+		req  runRequest
+		want string
+	}{
+		{
+			name: "short",
+			req:  runRequest{Name: "backup", Requester: alice},
+			want: "backup-alice-1700000000",
+		},
+		{
+			name: "long",
+			req:  runRequest{Name: strings.Repeat("nightly-", 8), Requester: alice},
+			want: "nightly-nightly-nightly-nightly-nightly-nightly-nigh-1700000000",
+		},
+		{
+			name: "long matrix run",
+			req:  runRequest{Name: strings.Repeat("nightly-", 8), Requester: alice, MatrixIndex: 12},
+			want: "nightly-nightly-nightly-nightly-nightly-nightly-n-1700000000-12",
+		},
+		{
+			name: "dash at the cut",
+			req:  runRequest{Name: strings.Repeat("a", 51) + "-b", Requester: alice},
+			want: strings.Repeat("a", 51) + "-1700000000",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			name, err := renderJobName(cfg, tc.req, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tc.want {
+				t.Errorf("renderJobName() = %s, want %s", name, tc.want)
+			}
+			if len(name) > maxJobNameLen {
+				t.Errorf("renderJobName() = %s, longer than %d characters", name, maxJobNameLen)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// kubectlVersionEnv overrides the version of the kubectl dependency.
+	kubectlVersionEnv = "BOTKUBE_JOB_KUBECTL_VERSION"
+	// kubectlBaseURLEnv overrides the base URL kubectl is downloaded from, e.g. an internal mirror of dl.k8s.io/release.
+	kubectlBaseURLEnv = "BOTKUBE_JOB_KUBECTL_BASE_URL"
+)
+
+// kubectlVersion and kubectlBaseURL are the defaults of the kubectl dependency, they can also be set via ldflags.
+var (
+	kubectlVersion = "v1.28.1"
+	kubectlBaseURL = "https://dl.k8s.io/release"
+)
+
+// kubectlPlatforms are the platforms kubectl is downloaded for.
+var kubectlPlatforms = []string{
+	"windows/amd64",
+	"darwin/amd64",
+	"darwin/arm64",
+	"linux/amd64",
+	"linux/s390x",
+	"linux/ppc64le",
+	"linux/arm64",
+	"linux/386",
+}
+
+// kubectlURLs returns the kubectl download URL per platform.
+// Dependencies are resolved from Metadata before any plugin config is passed, so overrides come from the environment.
+func kubectlURLs() map[string]string {
+	version := envOr(kubectlVersionEnv, kubectlVersion)
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	baseURL := strings.TrimSuffix(envOr(kubectlBaseURLEnv, kubectlBaseURL), "/")
+
+	urls := make(map[string]string, len(kubectlPlatforms))
+	for _, platform := range kubectlPlatforms {
+		binary := "kubectl"
+		if strings.HasPrefix(platform, "windows/") {
+			binary += ".exe"
+		}
+		urls[platform] = fmt.Sprintf("%s/%s/bin/%s/%s", baseURL, version, platform, binary)
+	}
+	return urls
+}
+
+// envOr returns the value of the env var or def when it is empty.
+func envOr(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// limitOverridesAnnotation enables inputs for Job limits: "true" or a comma-separated list of limit fields.
+	limitOverridesAnnotation = "botkubeLimitOverrides"
+	// overrideLimit sets a Job spec limit: limit:<activeDeadlineSeconds|backoffLimit>=<value>
+	overrideLimit = "limit"
+	// argTargetLimit makes the arg value a Job spec limit.
+	argTargetLimit = "limit"
+	limitsGroup    = "Limits"
+)
+
+// limitField is a Job spec field that can be overridden, with its input label, minimal value
+// and the annotation holding its default for ad-hoc runs.
+type limitField struct {
+	Field       string
+	Description string
+	Min         float64
+	Annotation  string
+}
+
+var limitFields = []limitField{
+	{"activeDeadlineSeconds", "Timeout (seconds)", 1, "botkubeActiveDeadlineSeconds"},
+	{"backoffLimit", "Retries", 0, "botkubeBackoffLimit"},
+}
+
+// limitArgs returns optional inputs for the limit fields enabled with the CronJob annotations.
+func limitArgs(annotations map[string]string) []Arg {
+	enabled := strings.TrimSpace(annotations[limitOverridesAnnotation])
+	if enabled == "" || enabled == "false" {
+		return nil
+	}
+	fields := splitList(enabled)
+
+	var args []Arg
+	for _, lf := range limitFields {
+		if enabled != "true" && !slices.Contains(fields, lf.Field) {
+			continue
+		}
+		minValue := lf.Min
+		args = append(args, Arg{
+			Flag:        lf.Field,
+			Description: lf.Description,
+			Type:        argTypeInt,
+			Min:         &minValue,
+			Target:      argTargetLimit,
+			Group:       limitsGroup,
+			Optional:    true,
+		})
+	}
+	return args
+}
+
+// withLimitDefaults adds the limits defaulted with the job annotations, unless the request overrides them.
+func withLimitDefaults(req runRequest, annotations map[string]string) runRequest {
+	var defaults []runOverride
+	for _, lf := range limitFields {
+		value := strings.TrimSpace(annotations[lf.Annotation])
+		if value == "" {
+			continue
+		}
+		overridden := slices.ContainsFunc(req.Overrides, func(o runOverride) bool {
+			return o.Kind == overrideLimit && o.Key == lf.Field
+		})
+		if !overridden {
+			defaults = append(defaults, runOverride{Kind: overrideLimit, Key: lf.Field, Value: value})
+		}
+	}
+	req.Overrides = append(defaults, req.Overrides...)
+	return req
+}
+
+// applyLimit sets the Job spec limit field.
+func applyLimit(job map[string]interface{}, field, value string) error {
+	idx := slices.IndexFunc(limitFields, func(lf limitField) bool {
+		return lf.Field == field
+	})
+	if idx < 0 {
+		return fmt.Errorf("invalid limit %q, expected activeDeadlineSeconds or backoffLimit", field)
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || float64(n) < limitFields[idx].Min {
+		return fmt.Errorf("invalid %s %q, expected a number of at least %v", field, value, limitFields[idx].Min)
+	}
+
+	spec, _ := job["spec"].(map[string]interface{})
+	if spec == nil {
+		return fmt.Errorf("job has no spec")
+	}
+	spec[field] = n
+	return nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	// requesterAnnotation holds the user who launched the job.
+	requesterAnnotation = "botkube.io/requester"
+	// runCommandAnnotation holds the plugin command that launched the job, so it can be run again.
+	runCommandAnnotation = "botkube.io/run-command"
+	// maxListedJobs keeps the list message within Slack block limits.
+	maxListedJobs = 15
+	// defaultLogsTail is the number of log lines shown by the logs subcommand.
+	defaultLogsTail = 50
+	// maxLogsLen keeps the logs within the Slack limit of a section text.
+	maxLogsLen = 2800
+)
+
+// showJobList renders the most recent botkube-created jobs with buttons to view logs or delete them.
+// Jobs can be filtered with "--mine" and "--job <cronjob>", which match the audit labels of created jobs.
+func showJobList(ctx context.Context, envs map[string]string, scope jobScope, value string) executor.ExecuteOutput {
+	format, value, err := cutOutputFormat(value)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	var selectors []string
+	fields := strings.Fields(value)
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case fields[i] == "--mine":
+			selectors = append(selectors, fmt.Sprintf("%s=%s", requesterIDLabel, labelValue(scope.UserID())))
+		case fields[i] == "--job" && i+1 < len(fields):
+			selectors = append(selectors, fmt.Sprintf("%s=%s", sourceLabel, labelValue(fields[i+1])))
+			i++
+		default:
+			return executor.ExecuteOutput{
+				Message: api.NewCodeBlockMessage(fmt.Sprintf("Usage: %s list [--mine] [--job <cronjob>] [-o json|yaml]", pluginName), true),
+			}
+		}
+	}
+
+	jobs, err := listBotkubeJobsBySelector(ctx, envs, strings.Join(selectors, ","))
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreationTimestamp.After(jobs[j].CreationTimestamp.Time)
+	})
+	// machine-readable output is not limited by Slack blocks, all jobs are listed
+	if format != "" {
+		summaries := []jobSummary{}
+		for _, job := range jobs {
+			summaries = append(summaries, summarizeJob(job))
+		}
+		return machineOutput(format, summaries)
+	}
+	if len(jobs) == 0 {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("No jobs launched by Botkube found", true),
+		}
+	}
+	if len(jobs) > maxListedJobs {
+		jobs = jobs[:maxListedJobs]
+	}
+
+	btnBuilder := api.NewMessageButtonBuilder()
+	var sections []api.Section
+	for _, job := range jobs {
+		requester := job.Annotations[requesterAnnotation]
+		if requester == "" {
+			requester = "-"
+		}
+		buttons := []api.Button{
+			btnBuilder.ForCommandWithoutDesc("Logs", fmt.Sprintf("%s logs %s %s", pluginName, job.Name, job.Namespace)),
+			btnBuilder.ForCommandWithoutDesc("Delete", fmt.Sprintf("%s delete %s %s", pluginName, job.Name, job.Namespace), api.ButtonStyleDanger),
+		}
+		if runCmd := job.Annotations[runCommandAnnotation]; runCmd != "" {
+			buttons = append(buttons, btnBuilder.ForCommandWithoutDesc("Run again", runCmd))
+		}
+		sections = append(sections, api.Section{
+			Base: api.Base{
+				Header: job.Name,
+			},
+			TextFields: api.TextFields{
+				{Key: "Namespace", Value: job.Namespace},
+				{Key: "Status", Value: jobPhase(job)},
+				{Key: "Requester", Value: requester},
+				{Key: "Age", Value: duration.HumanDuration(time.Since(job.CreationTimestamp.Time))},
+			},
+			Buttons: buttons,
+		})
+	}
+
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			BaseBody: api.Body{
+				Plaintext: "Recent jobs launched by Botkube",
+			},
+			Sections:          sections,
+			OnlyVisibleForYou: true,
+		},
+	}
+}
+
+// showJobLogs renders the last lines of the job logs, with a button loading the preceding lines.
+// Usage: job logs <name> [namespace] [--tail <lines>] [--skip <lines>]
+func showJobLogs(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string) executor.ExecuteOutput {
+	usage := executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(fmt.Sprintf("Usage: %s logs <name> [namespace] [--tail <lines>] [--skip <lines>]", pluginName), true),
+	}
+	var refs []string
+	tail, skip := defaultLogsTail, 0
+	fields := strings.Fields(value)
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case (fields[i] == "--tail" || fields[i] == "--skip") && i+1 < len(fields):
+			n, err := strconv.Atoi(fields[i+1])
+			if err != nil || n < 0 || (fields[i] == "--tail" && n == 0) {
+				return usage
+			}
+			if fields[i] == "--tail" {
+				tail = n
+			} else {
+				skip = n
+			}
+			i++
+		case !strings.HasPrefix(fields[i], "-") && len(refs) < 2:
+			refs = append(refs, fields[i])
+		default:
+			return usage
+		}
+	}
+	name, namespace, ok := parseJobRef(strings.Join(refs, " "))
+	if !ok {
+		return usage
+	}
+	job, err := findBotkubeJob(ctx, envs, name, namespace)
+	if err == nil {
+		err = authorizeJob(ctx, envs, cfg, scope, job)
+	}
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	// kubectl limits the lines per container, the skipped lines are fetched as well and cut off below
+	logs, err := getJobLogs(ctx, envs, job.Namespace, job.Name, skip+tail)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	var lines []string
+	if logs != "" {
+		lines = strings.Split(logs, "\n")
+	}
+	end := max(len(lines)-skip, 0)
+	start := max(end-tail, 0)
+	page := strings.Join(lines[start:end], "\n")
+	if page == "" {
+		page = "no logs"
+	}
+
+	section := api.Section{
+		Base: api.Base{
+			Header: fmt.Sprintf("Logs of %s", job.Name),
+			Body: api.Body{
+				CodeBlock: truncateLogs(page, maxLogsLen),
+			},
+		},
+	}
+	// with fewer lines than requested, the beginning of the logs is reached
+	if start > 0 || len(lines) >= skip+tail {
+		btnBuilder := api.NewMessageButtonBuilder()
+		more := fmt.Sprintf("%s logs %s %s --tail %d --skip %d", pluginName, job.Name, job.Namespace, tail, skip+tail)
+		section.Buttons = api.Buttons{
+			btnBuilder.ForCommandWithoutDesc("Load more", withClusterFlag(more, scope.Cluster)),
+		}
+	}
+	if skip > 0 {
+		section.Context = api.ContextItems{
+			{Text: fmt.Sprintf("Lines before the last %d", skip)},
+		}
+	}
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections:          []api.Section{section},
+			OnlyVisibleForYou: true,
+		},
+	}
+}
+
+// deleteJob deletes the botkube-created job together with its pods, if the user can run its source.
+func deleteJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string) executor.ExecuteOutput {
+	name, namespace, ok := parseJobRef(value)
+	if !ok {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Usage: %s delete <name> [namespace]", pluginName), true),
+		}
+	}
+	job, err := findBotkubeJob(ctx, envs, name, namespace)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	if err := authorizeJob(ctx, envs, cfg, scope, job); err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	deleteCmd := fmt.Sprintf("kubectl delete job -n %s %s --cascade=background", job.Namespace, job.Name)
+	if _, err := kubectl(ctx, envs, deleteCmd); err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("while deleting job %s: %v", job.Name, err), true),
+		}
+	}
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(fmt.Sprintf("Job %s is deleted", job.Name), true),
+	}
+}
+
+// requesterName returns the display name of the user, falling back to the user mention.
+func requesterName(user executor.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Mention
+}
+
+// parseJobRef parses "<name> [namespace]" arguments.
+func parseJobRef(value string) (name, namespace string, ok bool) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return "", "", false
+	}
+	if len(fields) > 1 {
+		namespace = fields[1]
+	}
+	return fields[0], namespace, true
+}
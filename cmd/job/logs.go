@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"botkube.io/plugins-example/internal/slackupload"
+	"github.com/slack-go/slack"
+)
+
+const truncatedLogsPrefix = "... (truncated)\n"
+
+// getJobLogs returns the logs of all containers of the job pods, prefixed with the pod and container name.
+// tail limits the lines per container, -1 returns all lines.
+func getJobLogs(ctx context.Context, envs map[string]string, namespace, name string, tail int) (string, error) {
+	logsCmd := fmt.Sprintf("kubectl logs -n %s -l job-name=%s --all-containers --prefix --tail=%d", namespace, name, tail)
+	out, err := kubectl(ctx, envs, logsCmd)
+	if err != nil {
+		return "", fmt.Errorf("while getting logs of job %s: %w", name, err)
+	}
+	return strings.TrimSpace(out.Stdout), nil
+}
+
+// truncateLogs keeps the last maxBytes of logs, as the end of the output is usually the most relevant part.
+func truncateLogs(logs string, maxBytes int) string {
+	if maxBytes <= 0 || len(logs) <= maxBytes {
+		return logs
+	}
+	logs = logs[len(logs)-maxBytes:]
+	if idx := strings.Index(logs, "\n"); idx >= 0 && idx < len(logs)-1 {
+		logs = logs[idx+1:]
+	}
+	return truncatedLogsPrefix + logs
+}
+
+// collectLogs sets the logs of the finished job on the result. Logs longer than the configured limit are shared
+// as a file in the channel, in the thread if threadTS is set, and the result links to it. Without a channel,
+// e.g. for private results, or when the upload fails, the logs are truncated instead.
+func collectLogs(ctx context.Context, envs map[string]string, cfg Config, result *jobResult, namespace, name, channel, threadTS string) {
+	if !cfg.Logs.Enabled {
+		return
+	}
+	logs, err := getJobLogs(ctx, envs, namespace, name, -1)
+	if err != nil {
+		logs = err.Error()
+	}
+	if !cfg.Logs.Upload || channel == "" || cfg.BotToken == "" || cfg.Logs.MaxBytes <= 0 || len(logs) <= cfg.Logs.MaxBytes {
+		result.Logs = truncateLogs(logs, cfg.Logs.MaxBytes)
+		return
+	}
+
+	filename := fmt.Sprintf("%s.log", name)
+	fileID, err := slackupload.Upload(ctx, cfg.BotToken, channel, threadTS, filename, logs, fmt.Sprintf("Logs of job %s in namespace %s", name, namespace))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to upload logs of job %s: %v", name, err)
+		result.Logs = truncateLogs(logs, cfg.Logs.MaxBytes)
+		return
+	}
+	result.LogsFile = filename
+	// the permalink needs the files:read scope, the file name is enough to find the upload next to the result
+	if file, _, _, err := slack.New(cfg.BotToken).GetFileInfoContext(ctx, fileID, 0, 0); err == nil && file.Permalink != "" {
+		result.LogsFile = fmt.Sprintf("<%s|%s>", file.Permalink, filename)
+	}
+}
@@ -2,26 +2,24 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
-	"time"
+	"unicode"
 
-	"github.com/google/uuid"
 	go_plugin "github.com/hashicorp/go-plugin"
 	"github.com/kubeshop/botkube/pkg/api"
 	"github.com/kubeshop/botkube/pkg/api/executor"
 	"github.com/kubeshop/botkube/pkg/plugin"
 	"github.com/slack-go/slack"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
 	description = "Run Job."
 	pluginName  = "job"
-	kubectlVersion   = "v1.28.1"
 
 )
 
@@ -44,9 +42,14 @@ type BotKubeAnnotation struct {
 type CronJobs struct {
     Metadata struct {
         Annotations map[string]string `json:"Annotations"`
+		Labels map[string]string `json:"labels"`
 		Name string `json:"name"`
 		Namespace string `json:"namespace"`
     } `json:"metadata"`
+	Spec struct {
+		Suspend  bool   `json:"suspend"`
+		Schedule string `json:"schedule"`
+	} `json:"spec"`
 }
 
 type CronJobsList struct {
@@ -58,12 +61,58 @@ type Arg struct {
 	Type        string `json:"type"`
 	Default     string `json:"default"`
 	Values      []string `json:"values,omitempty"`
+	// Min, Max and Step constrain values of number and int args.
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+	Step *float64 `json:"step,omitempty"`
+	// Pattern is a regular expression text args must match.
+	Pattern string `json:"pattern,omitempty"`
+	// ErrorMessage is shown instead of the default validation error.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// Secret is the name of the Secret whose keys are offered by secret args.
+	Secret string `json:"secret,omitempty"`
+	// Target is where the arg value is passed to: container args (default) or env.
+	Target string `json:"target,omitempty"`
+	// Env is the name of the env var the arg is injected as, for secret args and args with the env target.
+	Env string `json:"env,omitempty"`
+	// Selector is a label selector filtering namespaces offered by namespace args.
+	Selector string `json:"selector,omitempty"`
+	// Group renders the arg in a titled section together with other args of the same group.
+	Group string `json:"group,omitempty"`
+	// DependsOn renders the arg only when other args have specific values, e.g. --cloud=aws
+	DependsOn string `json:"dependsOn,omitempty"`
+	// Container is the name of the container whose image tag is set by args with the image target.
+	Container string `json:"container,omitempty"`
+	// Registry lists image tags offered by args with the image target.
+	Registry *RegistrySource `json:"registry,omitempty"`
+	// Optional args may be left empty, they are omitted from the command then.
+	Optional bool `json:"optional,omitempty"`
+	// Multiple allows choosing several values of a dropdown arg, one job is run per value.
+	Multiple bool `json:"multiple,omitempty"`
+	// Order sorts the arg in the form, the command and the help, ascending. Args without it follow in definition order.
+	Order *int `json:"order,omitempty"`
+	// Position places the value of an arg without a flag at this index of the container args.
+	Position *int `json:"position,omitempty"`
+	// ValuesFrom fetches dropdown values from the cluster at render time instead of Values.
+	ValuesFrom *ValuesFrom `json:"valuesFrom,omitempty"`
+	// DefaultFrom reads the default from the cluster at render time, Default is used when the lookup fails.
+	DefaultFrom *DefaultFrom `json:"defaultFrom,omitempty"`
 }
 
 type Job struct {
+	// Kind is CronJob, Job (a Job template) or Deployment (restarted instead of run).
+	Kind      string `json:"kind"`
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
 	Args      []Arg  `json:"args"`
+	// Issues lists problems with the args definition. Jobs with issues are listed but cannot be run.
+	Issues []string `json:"issues,omitempty"`
+	// Suspended is true for CronJobs with suspended schedule.
+	Suspended bool `json:"suspended,omitempty"`
+	// Schedule is the cron schedule of CronJobs.
+	Schedule string `json:"schedule,omitempty"`
+	// annotations of the workload, used to check access of the user listing the jobs.
+	annotations map[string]string
 }
 
 // Metadata returns details about the Msg plugin.
@@ -71,16 +120,7 @@ func (MsgExecutor) Metadata(context.Context) (api.MetadataOutput, error) {
 	return api.MetadataOutput{
 		Dependencies: map[string]api.Dependency{
 			"kubectl": {
-				URLs: map[string]string{
-					"windows/amd64": fmt.Sprintf("https://dl.k8s.io/release/%s/bin/windows/amd64/kubectl.exe", kubectlVersion),
-					"darwin/amd64":  fmt.Sprintf("https://dl.k8s.io/release/%s/bin/darwin/amd64/kubectl", kubectlVersion),
-					"darwin/arm64":  fmt.Sprintf("https://dl.k8s.io/release/%s/bin/darwin/arm64/kubectl", kubectlVersion),
-					"linux/amd64":   fmt.Sprintf("https://dl.k8s.io/release/%s/bin/linux/amd64/kubectl", kubectlVersion),
-					"linux/s390x":   fmt.Sprintf("https://dl.k8s.io/release/%s/bin/linux/s390x/kubectl", kubectlVersion),
-					"linux/ppc64le": fmt.Sprintf("https://dl.k8s.io/release/%s/bin/linux/ppc64le/kubectl", kubectlVersion),
-					"linux/arm64":   fmt.Sprintf("https://dl.k8s.io/release/%s/bin/linux/arm64/kubectl", kubectlVersion),
-					"linux/386":     fmt.Sprintf("https://dl.k8s.io/release/%s/bin/linux/386/kubectl", kubectlVersion),
-				},
+				URLs: kubectlURLs(),
 			},
 		},
 		Version:     version,
@@ -90,11 +130,42 @@ func (MsgExecutor) Metadata(context.Context) (api.MetadataOutput, error) {
 
 // Execute returns a given command as a response.
 func (e *MsgExecutor) Execute(ctx context.Context, in executor.ExecuteInput) (executor.ExecuteOutput, error) {
+	var cfg Config
+	if err := plugin.MergeExecutorConfigsWithDefaults(defaultConfig, in.Configs, &cfg); err != nil {
+		return executor.ExecuteOutput{}, fmt.Errorf("while merging input configs: %w", err)
+	}
 
+	// The cluster selected in the form takes precedence over the one in the command
+	details := newFormState(e, in).Details()
+	cluster, command := cutClusterFlag(in.Command)
+	_, command = cutStateFlag(command)
+	if details.cluster != "" {
+		cluster = details.cluster
+	}
+	cluster, err := resolveCluster(cfg, cluster)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}, nil
+	}
+	kubeConfig, err := clusterKubeConfig(ctx, cfg, cluster, in.Context.KubeConfig)
+	if err != nil {
+		return errorOutput(err, in.Command), nil
+	}
+	in.Command = command
+	in.Context.KubeConfig = kubeConfig
+
+	out, err := e.execute(ctx, in, cfg, cluster, details)
+	out.Message = clusterMessage(out.Message, cluster)
+	return out, err
+}
+
+// execute runs the command against the cluster with the kubeconfig passed in the input.
+func (e *MsgExecutor) execute(ctx context.Context, in executor.ExecuteInput, cfg Config, cluster string, details stateDetails) (executor.ExecuteOutput, error) {
 	// Kubernetes client setup
 	kubeConfigPath, deleteFn, err := plugin.PersistKubeConfig(ctx, in.Context.KubeConfig)
 	if err != nil {
-		log.Fatalf("Error writing kubeconfig file: %v", err)
+		return errorOutput(fmt.Errorf("while writing kubeconfig file: %w", err), withClusterFlag(in.Command, cluster)), nil
 	}
 	defer func() {
 		if deleteErr := deleteFn(ctx); deleteErr != nil {
@@ -104,64 +175,142 @@ func (e *MsgExecutor) Execute(ctx context.Context, in executor.ExecuteInput) (ex
 	envs := map[string]string{
 		"KUBECONFIG": kubeConfigPath,
 	}
-	if err != nil {
-		log.Fatalf("Error creating Kubernetes client: %v", err)
-	}
-	slackState := in.Context.SlackState
-	details := e.extractStateDetails(slackState)
+
+	scope := newJobScope(in.Context.Message)
+	scope.Cluster = cluster
 
 	// Parse the action and value from the command
 	action, value := parseCommand(in.Command)
 
 	switch action {
+	case "select_cluster":
+		return showBothSelects(ctx, envs, cfg, scope, details), nil
+
+	case "select_kind":
+		return showBothSelects(ctx, envs, cfg, scope, details), nil
+
+	case "select_delay":
+		return showBothSelects(ctx, envs, cfg, scope, details), nil
+
 	case "select_first":
-		return showBothSelects(ctx, envs, details), nil
+		return showBothSelects(ctx, envs, cfg, scope, details), nil
+
+	case "select_namespaces":
+		return showBothSelects(ctx, envs, cfg, scope, details), nil
+
+	case "select_multi":
+		return showBothSelects(ctx, envs, cfg, scope, details), nil
 
 	case "select_dynamic":
-		return showBothSelects(ctx, envs, details), nil
+		return showBothSelects(ctx, envs, cfg, scope, details), nil
+
+	case "status":
+		return showJobStatus(ctx, envs, cfg, scope, value), nil
+
+	case "list":
+		return showJobList(ctx, envs, scope, value), nil
+
+	case "logs":
+		return showJobLogs(ctx, envs, cfg, scope, value), nil
+
+	case "history":
+		return showHistory(ctx, envs, cfg, scope, value), nil
+
+	case "doctor":
+		return showDoctor(ctx, envs, cfg, scope), nil
+
+	case "cleanup":
+		return cleanupJobs(ctx, envs, cfg, scope, value), nil
+
+	case "delete":
+		return deleteJob(ctx, envs, cfg, scope, value), nil
 
 	case "run":
-		fields := strings.Fields(value)
-		args := fields[2:]
-		jobName := fmt.Sprintf("%s-%s",fields[0], strconv.FormatInt(time.Now().Unix(), 10))
-		filePath := fmt.Sprintf("/tmp/%s-%s.json",jobName, uuid.New())
-		runCmd := fmt.Sprintf("kubectl create job --from=cronjob/%s -n %s %s --dry-run -ojson", fields[0], fields[1], jobName)
-		out, _ := plugin.ExecuteCommand(ctx, runCmd, plugin.ExecuteCommandEnvs(envs))
-		var cronJob map[string]interface{}
-		err := json.Unmarshal([]byte(out.Stdout), &cronJob)
-		if err != nil {
-			fmt.Println("Error unmarshalling JSON:", err)
+		// Without interactive messages or a namespace, the job is run from --flag value pairs
+		if !in.Context.IsInteractivitySupported || len(strings.Fields(value)) < 2 {
+			return runFromText(ctx, envs, cfg, scope, in, value), nil
 		}
-		annotations := cronJob["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})
-		annotations["botkube"] = "true" 
-		// Navigate to the container args
-		template := cronJob["spec"].(map[string]interface{})["template"].(map[string]interface{})
-		container := template["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+		return runJob(ctx, envs, cfg, scope, in, value, false), nil
 
-		// Modify the first container args
-		container["args"] = args
+	case "run-anyway":
+		return runJob(ctx, envs, cfg, scope, in, value, true), nil
 
-		// Marshal the modified map back to JSON
-		modifiedJSON, err := json.MarshalIndent(cronJob, "", "  ")
-		if err != nil {
-			fmt.Println("Error marshalling JSON:", err)
+	case "preview":
+		return previewJob(ctx, envs, cfg, scope, value, false), nil
+
+	case "manifest":
+		return previewJob(ctx, envs, cfg, scope, value, true), nil
+
+	case "dryrun":
+		return dryRunJob(ctx, envs, cfg, scope, value), nil
+
+	case "schedule":
+		return scheduleRun(ctx, envs, cfg, scope, in, value), nil
+
+	case "unschedule":
+		return unscheduleRun(ctx, envs, cfg, scope, value), nil
+
+	case "cancel":
+		return cancelPreview(), nil
+
+	case "suspend":
+		return setCronJobSuspend(ctx, envs, cfg, scope, value, true), nil
+
+	case "resume":
+		return setCronJobSuspend(ctx, envs, cfg, scope, value, false), nil
+
+	case "schedule-edit":
+		return editCronSchedule(ctx, envs, cfg, scope, value), nil
+
+	case "schedule-apply":
+		return applyCronSchedule(ctx, envs, cfg, scope, value), nil
+
+	case "restart":
+		return restartDeployment(ctx, envs, cfg, scope, in, value), nil
+
+	case "back":
+		// keeps the selected kind, but discards the job and its parameters
+		out := initialMessages(ctx, envs, cfg, scope, e, value)
+		out.Message.ReplaceOriginal = true
+		return out, nil
+
+	case "favorite":
+		if kind, name, found := strings.Cut(value, " "); found {
+			toggleFavorite(scope, kind, name)
 		}
+		return showBothSelects(ctx, envs, cfg, scope, details), nil
 
-		// // Save the patched JSON to a file
-		err = os.WriteFile(filePath, modifiedJSON, 0644) // Create or overwrite the file
-		if err != nil {
-			log.Fatalf("error writing patched JSON to file: %w", err)
+	case "help":
+		if strings.TrimSpace(value) == "" {
+			msg, err := e.Help(ctx)
+			return executor.ExecuteOutput{Message: msg}, err
 		}
-		createCmd := fmt.Sprintf("kubectl apply -f %s", filePath)
-		plugin.ExecuteCommand(ctx, createCmd, plugin.ExecuteCommandEnvs(envs))
-		defer os.RemoveAll(filePath)
-		return executor.ExecuteOutput{
-			Message: api.NewCodeBlockMessage(fmt.Sprintf("Job %s is started",jobName), true),
-		}, nil
+		return showJobHelp(ctx, envs, cfg, scope, value), nil
+
+	case "reset":
+		// the initial message has no parameter selects, which discards the captured state
+		out := initialMessages(ctx, envs, cfg, scope, e, "")
+		out.Message.ReplaceOriginal = true
+		return out, nil
+
+	case "refresh":
+		invalidateJobsCache()
+		out := initialMessages(ctx, envs, cfg, scope, e, "")
+		out.Message.ReplaceOriginal = true
+		return out, nil
+
+	case "approve":
+		return approveRun(ctx, envs, cfg, scope, in, value), nil
+
+	case "reject":
+		return rejectRun(ctx, envs, cfg, scope, in, value), nil
 	}
 
 	if strings.TrimSpace(in.Command) == pluginName {
-		return initialMessages(ctx, envs, e), nil
+		if !in.Context.IsInteractivitySupported {
+			return textJobList(ctx, envs, cfg, scope), nil
+		}
+		return initialMessages(ctx, envs, cfg, scope, e, ""), nil
 	}
 
 	msg := fmt.Sprintf("Plain command: %s", in.Command)
@@ -173,8 +322,15 @@ func (e *MsgExecutor) Execute(ctx context.Context, in executor.ExecuteInput) (ex
 }
 
 type stateDetails struct {
+	cluster     string
+	kind        string
+	delay       string
 	job         string
+	// namespaces are the selected namespaces of a job existing in more than one namespace.
+	namespaces  []string
 	params      map[string]string
+	// embedState carries the selections in commands of the rendered form, for platforms without form state.
+	embedState  bool
 }
 
 func (e *MsgExecutor) extractStateDetails(state *slack.BlockActionStates) stateDetails {
@@ -188,11 +344,30 @@ func (e *MsgExecutor) extractStateDetails(state *slack.BlockActionStates) stateD
 	for _, blocks := range state.Values {
 
 		for id, act := range blocks {
+			_, id := cutClusterFlag(id)
+			_, id = cutStateFlag(id)
 			id_full := strings.TrimPrefix(id, pluginName)
 			id_cmd := strings.Fields(id_full)[0]
 			switch id_cmd {
+			case "select_cluster":
+				details.cluster = act.SelectedOption.Value
+			case "select_kind":
+				details.kind = act.SelectedOption.Value
+			case "select_delay":
+				details.delay = act.SelectedOption.Value
 			case "select_first":
 				details.job = act.SelectedOption.Value
+			case "select_namespaces":
+				for _, option := range act.SelectedOptions {
+					details.namespaces = append(details.namespaces, option.Value)
+				}
+			case "select_multi":
+				key := strings.Fields(id_full)[1]
+				var values []string
+				for _, option := range act.SelectedOptions {
+					values = append(values, option.Value)
+				}
+				details.params[key] = strings.Join(values, ",")
 			case "select_dynamic":
 				key := strings.Fields(id_full)[1]
 				if act.Value != "" {
@@ -210,14 +385,12 @@ func (e *MsgExecutor) extractStateDetails(state *slack.BlockActionStates) stateD
 }
 
 
-// parseCommand parses the input command into action and value
+// parseCommand parses the input command into action and value.
+// The value is kept as typed, so quoted arg values keep their spacing.
 func parseCommand(cmd string) (action, value string) {
-	parts := strings.Fields(cmd)
-	if len(parts) > 1 {
-		action = parts[1]
-		value = strings.Join(parts[2:], " ")
-	}
-	return
+	_, rest := cutField(cmd)
+	action, value = cutField(rest)
+	return action, strings.TrimRightFunc(value, unicode.IsSpace)
 }
 
 
@@ -242,43 +415,144 @@ func createJobNameSelect(fileList []api.OptionItem, initialOption *api.OptionIte
 }
 
 
-func getBotkubeJobs(ctx context.Context, envs map[string]string) ([]Job) {
+func getBotkubeJobs(ctx context.Context, envs map[string]string, cfg Config, scope jobScope) ([]Job, error) {
 	var jobList []Job
+	jobs, err := cachedJobs(ctx, envs, cfg, scope.Cluster)
+	for _, job := range jobs {
+		if !cfg.isJobAllowed(scope, job.Namespace, job.Name) || !isUserAllowed(ctx, cfg, scope, job.annotations) {
+			continue
+		}
+		jobList = append(jobList, job)
+	}
+	return jobList, err
+}
+
+// discoverJobs returns all runnable workloads, regardless of the channel and user the jobs are listed for.
+// Workloads that could be listed are returned together with the errors of the others.
+func discoverJobs(ctx context.Context, envs map[string]string, cfg Config) ([]Job, error) {
+	var jobList []Job
+
+	selector := labels.Nothing()
+	if cfg.Discovery.LabelSelector != "" {
+		parsed, err := labels.Parse(cfg.Discovery.LabelSelector)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid label selector %q: %v", cfg.Discovery.LabelSelector, err)
+		} else {
+			selector = parsed
+		}
+	}
+	argsFromConfigMap := newConfigMapArgs(ctx, envs, cfg.Discovery.ArgsConfigMap)
+	isRunnable := func(workload CronJobs) bool {
+		return cfg.Namespaces.IsAllowed(workload.Metadata.Namespace)
+	}
+
+	// Job definitions take precedence over args defined on the target itself
+	definitions := map[string]JobDefinition{}
+	for _, def := range getJobDefinitions(ctx, envs, cfg) {
+		definitions[def.key()] = def
+	}
 
-	runCmd := "kubectl get cronjobs -A -ojson"
-	out, _ := plugin.ExecuteCommand(ctx, runCmd, plugin.ExecuteCommandEnvs(envs))
-	var cronJobsResList CronJobsList
-	json.Unmarshal([]byte(out.Stdout), &cronJobsResList)
-	for _,cronJob := range cronJobsResList.Items {
-		_, ok := cronJob.Metadata.Annotations["botkubeJobArgs"]
+	cronJobs, cronJobsErr := listWorkloads(ctx, envs, cfg, "cronjobs")
+	for _,cronJob := range cronJobs {
+		if !isRunnable(cronJob) {
+			continue
+		}
+		def, ok := definitions[workloadKey(kindCronJob, cronJob.Metadata.Namespace, cronJob.Metadata.Name)]
+		args := def.Args
+		var issues []string
+		if !ok {
+			var rawArgs string
+			rawArgs, ok = cronJob.Metadata.Annotations["botkubeJobArgs"]
+			if !ok && selector.Matches(labels.Set(cronJob.Metadata.Labels)) {
+				rawArgs, ok = argsFromConfigMap(cronJob.Metadata.Namespace, cronJob.Metadata.Name), true
+			}
+			if ok {
+				args, issues = parseArgs(rawArgs)
+			}
+		}
 		if ok {
-			var args []Arg
-			json.Unmarshal([]byte(cronJob.Metadata.Annotations["botkubeJobArgs"]), &args)
+			args = append(args, resourceArgs(cronJob.Metadata.Annotations)...)
+			args = append(args, limitArgs(cronJob.Metadata.Annotations)...)
+			args = orderArgs(args)
 			jobList = append(jobList, Job{
+				Kind: kindCronJob,
 				Name: cronJob.Metadata.Name,
 				Namespace: cronJob.Metadata.Namespace,
 				Args: args,
+				Issues: issues,
+				Suspended: cronJob.Spec.Suspend,
+				Schedule: cronJob.Spec.Schedule,
+				annotations: cronJob.Metadata.Annotations,
 			})
 		}
 	}
-	return jobList
+
+	templates, templatesErr := listWorkloads(ctx, envs, cfg, "jobs")
+	for _, template := range templates {
+		def, hasDef := definitions[workloadKey(kindJob, template.Metadata.Namespace, template.Metadata.Name)]
+		if (!hasDef && template.Metadata.Annotations[jobTemplateAnnotation] != "true") || !isRunnable(template) {
+			continue
+		}
+		args := def.Args
+		var issues []string
+		if rawArgs, ok := template.Metadata.Annotations["botkubeJobArgs"]; !hasDef && ok {
+			args, issues = parseArgs(rawArgs)
+		}
+		args = append(args, resourceArgs(template.Metadata.Annotations)...)
+		args = append(args, limitArgs(template.Metadata.Annotations)...)
+		args = orderArgs(args)
+		jobList = append(jobList, Job{
+			Kind:        kindJob,
+			Name:        template.Metadata.Name,
+			Namespace:   template.Metadata.Namespace,
+			Args:        args,
+			Issues:      issues,
+			annotations: template.Metadata.Annotations,
+		})
+	}
+
+	deployments, deploymentsErr := listWorkloads(ctx, envs, cfg, "deployments")
+	for _, deployment := range deployments {
+		if deployment.Metadata.Annotations[restartAnnotation] != "true" || !isRunnable(deployment) {
+			continue
+		}
+		jobList = append(jobList, Job{
+			Kind:        kindDeployment,
+			Name:        deployment.Metadata.Name,
+			Namespace:   deployment.Metadata.Namespace,
+			annotations: deployment.Metadata.Annotations,
+		})
+	}
+	for _, job := range jobList {
+		if len(job.Issues) > 0 {
+			fmt.Fprintf(os.Stderr, "invalid args definition of %s %s/%s: %s", job.Kind, job.Namespace, job.Name, strings.Join(job.Issues, "; "))
+		}
+	}
+	return jobList, errors.Join(cronJobsErr, templatesErr, deploymentsErr)
 }
 
-func initialMessages(ctx context.Context, envs map[string]string, e *MsgExecutor) executor.ExecuteOutput {
+func initialMessages(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, e *MsgExecutor, kind string) executor.ExecuteOutput {
 	var jobList []api.OptionItem
-	jobs := getBotkubeJobs(ctx, envs)
-	for _, job := range jobs {
-		jobList = append(jobList, api.OptionItem{
-			Name:  job.Name,
-			Value: job.Name,
-		})
+	allJobs, err := getBotkubeJobs(ctx, envs, cfg, scope)
+	if err != nil && len(allJobs) == 0 {
+		return discoveryError(err)
 	}
+	jobs, kind := jobsOfKind(allJobs, kind)
+	jobList = uniqueJobOptions(jobs)
 
 	cmdPrefix := func(cmd string) string {
 		return fmt.Sprintf("%s %s %s", api.MessageBotNamePlaceholder, pluginName, cmd)
 	}
 
 	selects := createJobNameSelect(jobList, nil, cmdPrefix("select_first"))
+	selects.Items[0].OptionGroups = jobOptionGroups(scope, kind, jobList)
+	// The kind select is shown only if there is something else than CronJobs to choose from
+	if kinds := availableKinds(allJobs); len(kinds) > 1 {
+		selects.Items = append([]api.Select{createKindSelect(kinds, kind, cmdPrefix("select_kind"))}, selects.Items...)
+	}
+	if len(cfg.Clusters) > 1 {
+		selects.Items = append([]api.Select{createClusterSelect(cfg.Clusters, scope.Cluster, cmdPrefix("select_cluster"))}, selects.Items...)
+	}
 
 	return executor.ExecuteOutput{
 		Message: api.Message{
@@ -288,57 +562,151 @@ func initialMessages(ctx context.Context, envs map[string]string, e *MsgExecutor
 			Sections: []api.Section{
 				{
 					Selects: selects,
+					Buttons: []api.Button{
+						api.NewMessageButtonBuilder().ForCommandWithoutDesc("Refresh list", fmt.Sprintf("%s refresh", pluginName)),
+					},
+					Context: discoveryContext(err),
 				},
 			},
-			OnlyVisibleForYou: true,
+			OnlyVisibleForYou: cfg.formPrivate(scope.Channel),
 			ReplaceOriginal:   false,
 		},
 	}
 }
 
 // showBothSelects dynamically generates dropdowns based on the selected options.
-func showBothSelects(ctx context.Context, envs map[string]string, details stateDetails) executor.ExecuteOutput {
+func showBothSelects(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, details stateDetails) executor.ExecuteOutput {
 	var jobList []api.OptionItem
-	jobs := getBotkubeJobs(ctx, envs)
-	for _, job := range jobs {
-		jobList = append(jobList, api.OptionItem{
-			Name:  job.Name,
-			Value: job.Name,
-		})
+	allJobs, err := getBotkubeJobs(ctx, envs, cfg, scope)
+	if err != nil && len(allJobs) == 0 {
+		return discoveryError(err)
+	}
+	jobs, kind := jobsOfKind(allJobs, details.kind)
+	details.kind = kind
+	jobList = uniqueJobOptions(jobs)
+	// A job selected before switching the kind is no longer valid
+	if !slices.ContainsFunc(jobs, func(j Job) bool { return j.Name == details.job }) {
+		details.job = ""
 	}
 
 	btnBuilder := api.NewMessageButtonBuilder()
 	cmdPrefix := func(cmd string) string {
-		return fmt.Sprintf("%s %s %s", api.MessageBotNamePlaceholder, pluginName, cmd)
+		return withState(fmt.Sprintf("%s %s %s", api.MessageBotNamePlaceholder, pluginName, cmd), details)
 	}
 
-	initialOption := &api.OptionItem{
-		Name:  details.job,
-		Value: details.job,
+	// the initial option must match the listed one, which may be labeled as invalid
+	var initialOption *api.OptionItem
+	if idx := slices.IndexFunc(jobList, func(o api.OptionItem) bool { return o.Value == details.job }); details.job != "" && idx >= 0 {
+		initialOption = &jobList[idx]
 	}
 	selects := createJobNameSelect(jobList, initialOption, cmdPrefix("select_first"))
+	selects.Items[0].OptionGroups = jobOptionGroups(scope, kind, jobList)
+	if kinds := availableKinds(allJobs); len(kinds) > 1 {
+		selects.Items = append([]api.Select{createKindSelect(kinds, kind, cmdPrefix("select_kind"))}, selects.Items...)
+	}
+	if len(cfg.Clusters) > 1 {
+		selects.Items = append([]api.Select{createClusterSelect(cfg.Clusters, scope.Cluster, cmdPrefix("select_cluster"))}, selects.Items...)
+	}
 
 	sections := []api.Section{
 		{
 			Selects: selects,
+			Buttons: []api.Button{
+				btnBuilder.ForCommandWithoutDesc("Reset", fmt.Sprintf("%s reset", pluginName)),
+			},
+			Context: discoveryContext(err),
 		},
 	}
+	if details.job != "" {
+		back := btnBuilder.ForCommandWithoutDesc("← Choose another job", fmt.Sprintf("%s back %s", pluginName, kind))
+		sections[0].Buttons = append([]api.Button{back, favoriteButton(btnBuilder, scope, kind, details.job)}, sections[0].Buttons...)
+	}
+	// A job existing in several namespaces can be run in any of them at once, the form uses the args of the
+	// first selected namespace. Deployments are restarted one at a time.
+	namespaces := jobNamespaces(jobs, details.job)
+	selected := selectedNamespaces(namespaces, details.namespaces)
+	fanOut := len(namespaces) > 1 && details.kind != kindDeployment
+	if fanOut {
+		sections = append(sections, namespacesSection(namespaces, selected, cmdPrefix("select_namespaces")))
+	}
 	var namespace string
 	var jobArgs []Arg
+	var definitionIssues []string
+	// Ungrouped selects are rendered next to the job select and ungrouped inputs in a separate section.
+	// Each arg group gets its own titled section, in the order of the first arg of the group.
+	// When args have an explicit order, ungrouped args are rendered in sequence instead, see flowSection.
+	inputsSection := -1
+	flowIdx := -1
+	// Inputs render above selects in a section, so a select may join a section with inputs,
+	// but an input after a select starts a new section.
+	flowSection := func(input bool) int {
+		if flowIdx < 0 || (input && len(sections[flowIdx].Selects.Items) > 0) {
+			flowIdx = len(sections)
+			sections = append(sections, api.Section{
+				Selects: api.Selects{
+					ID: fmt.Sprintf("select-flow-%d", flowIdx),
+				},
+			})
+		}
+		return flowIdx
+	}
+	groupSections := map[string]int{}
+	groupSection := func(group string) int {
+		idx, ok := groupSections[group]
+		if !ok {
+			idx = len(sections)
+			groupSections[group] = idx
+			flowIdx = -1
+			sections = append(sections, api.Section{
+				Base: api.Base{
+					Header: group,
+				},
+				Selects: api.Selects{
+					ID: fmt.Sprintf("select-group-%d", idx),
+				},
+			})
+		}
+		return idx
+	}
 	// Create multiple dropdowns based on the options in the script output
 	for _, job := range jobs {
-		if job.Name == details.job {
+		if job.Name == details.job && (len(selected) == 0 || job.Namespace == selected[0]) {
 			namespace = job.Namespace
-			jobArgs = job.Args
-			for _, option := range job.Args {
+			if job.Kind == kindCronJob {
+				sections = append(sections, cronJobScheduleSection(job))
+			}
+			// A broken args definition would render a broken form, point to the invalid fields instead
+			if len(job.Issues) > 0 {
+				definitionIssues = job.Issues
+				break
+			}
+			// Defaults read from the cluster reflect the current state, e.g. the deployed image tag
+			jobArgs = withLiveDefaults(ctx, envs, namespace, job.Args)
+			ordered := hasArgOrder(jobArgs)
+			for _, option := range jobArgs {
+				// Args are re-evaluated on every state change, as their dependencies may have changed
+				if !isArgVisible(option, jobArgs, details) {
+					continue
+				}
 				// Construct the flag key for the state
-				flagKey := fmt.Sprintf("%s-%s", details.job, option.Flag)
+				flagKey := argKey(details.job, option)
 
-				if option.Type == "bool" || option.Type == "dropdown" {
+				// Dropdowns accepting several values get their own multi-select section
+				if option.Multiple {
+					if _, exists := details.params[flagKey]; !exists && option.Default != "" {
+						details.params[flagKey] = selectParam(option, option.Default)
+					}
+					values := optionValues(ctx, envs, namespace, option)
+					sections = append(sections, matrixSection(option, values, details.params[flagKey], cmdPrefix(fmt.Sprintf("select_multi %s", flagKey))))
+					// args following the multi-select must render below it
+					flowIdx = -1
+					continue
+				}
+
+				if isSelectArg(option) {
 
 					var dropdownOptions []api.OptionItem
-					boolValues := []string{"true", "false"}
-					values := option.Values; if option.Type == "bool" { values = boolValues }
+					values := optionValues(ctx, envs, namespace, option)
 					for _, value := range values {
 						dropdownOptions = append(dropdownOptions, api.OptionItem{
 							Name:  value,
@@ -362,8 +730,14 @@ func showBothSelects(ctx context.Context, envs map[string]string, details stateD
 						initialOption = nil // Set to nil if Default is not set
 					}
 
+					idx := 0
+					if option.Group != "" {
+						idx = groupSection(option.Group)
+					} else if ordered {
+						idx = flowSection(false)
+					}
 					// Add the dropdown with the InitialOption if available
-					sections[0].Selects.Items = append(sections[0].Selects.Items, api.Select{
+					sections[idx].Selects.Items = append(sections[idx].Selects.Items, api.Select{
 						Name:    option.Description, // Adjust name based on flags
 						Command: cmdPrefix(fmt.Sprintf("select_dynamic %s", flagKey)), // Handle dynamic dropdown
 						OptionGroups: []api.OptionGroup{
@@ -376,34 +750,86 @@ func showBothSelects(ctx context.Context, envs map[string]string, details stateD
 					})
 				}
 
-				if option.Type == "text" {
-					if len(sections) < 2 {
+				if isInputArg(option) {
+					// Inputs can't be pre-filled, the default is kept in the state and shown as the placeholder
+					placeholder := "Please write parameter value"
+					if option.Default != "" {
+						if _, exists := details.params[flagKey]; !exists {
+							details.params[flagKey] = option.Default
+						}
+						placeholder = fmt.Sprintf("Defaults to %s", option.Default)
+					}
+					idx := inputsSection
+					if option.Group != "" {
+						idx = groupSection(option.Group)
+					} else if ordered {
+						idx = flowSection(true)
+					} else if idx < 0 {
+						idx = len(sections)
+						inputsSection = idx
 						sections = append(sections, api.Section{})
 					}
-					sections[1].PlaintextInputs = append(sections[1].PlaintextInputs, api.LabelInput{
-						Command: cmdPrefix(fmt.Sprintf("select_dynamic %s %s ", flagKey, option.Flag)),
+					sections[idx].PlaintextInputs = append(sections[idx].PlaintextInputs, api.LabelInput{
+						Command: cmdPrefix(fmt.Sprintf("select_dynamic %s %s ", flagKey, argName(option))),
 						Text:        option.Description,
-						Placeholder: "Please write parameter value",
+						Placeholder: placeholder,
 						DispatchedAction: api.DispatchInputActionOnCharacter,
 					})
 				}
 			}
+			// only one namespace of a job existing in several namespaces renders the form
+			break
 		}
 	}
 
-	// If all selections are made, show the run button
-	if allSelectionsMade(details, jobArgs) {
-		code := buildFinalCommand(jobArgs, namespace, details)
+	jobArgs = visibleArgs(jobArgs, details)
+	issues := validateSelections(details, jobArgs)
+	if len(issues) > 0 {
+		sections = append(sections, api.Section{
+			Base: api.Base{
+				Header: "Invalid parameters",
+			},
+			BulletLists: api.BulletLists{
+				{Items: issues},
+			},
+		})
+	}
+
+	if len(definitionIssues) > 0 {
 		sections = append(sections, api.Section{
+			Base: api.Base{
+				Header:      "Invalid job definition",
+				Description: fmt.Sprintf("Fix the args definition of %s:", details.job),
+			},
+			BulletLists: api.BulletLists{
+				{Items: definitionIssues},
+			},
+		})
+	}
+
+	// If all selections are made and valid, show the run button
+	if details.job != "" && len(definitionIssues) == 0 && len(issues) == 0 && allSelectionsMade(details, jobArgs) && (!fanOut || len(selected) > 0) {
+		if fanOut {
+			namespace = strings.Join(selected, namespaceSeparator)
+		}
+		code := buildFinalCommand(jobArgs, namespace, details)
+		multiRun := isMultiRun(strings.TrimPrefix(code, pluginName+" run "))
+		runSection := api.Section{
 			Base: api.Base{
 				Body: api.Body{
 					CodeBlock: code,
 				},
 			},
-			Buttons: []api.Button{
-				btnBuilder.ForCommandWithoutDesc("Run command", code, api.ButtonStylePrimary),
-			},
-		})
+			Buttons: runButtons(btnBuilder, code, details.delay),
+		}
+		// Restarts and runs creating several jobs are not delayed, only single job runs can be scheduled
+		if details.kind != kindDeployment && !multiRun {
+			runSection.Selects = api.Selects{
+				ID:    "select-delay",
+				Items: []api.Select{delaySelect(details.delay, cmdPrefix("select_delay"))},
+			}
+		}
+		sections = append(sections, runSection)
 	}
 
 	return executor.ExecuteOutput{
@@ -412,16 +838,60 @@ func showBothSelects(ctx context.Context, envs map[string]string, details stateD
 				Plaintext: fmt.Sprintf("Please select the Job parameters for %s", details.job),
 			},
 			Sections:          sections,
-			OnlyVisibleForYou: true,
+			OnlyVisibleForYou: cfg.formPrivate(scope.Channel),
 			ReplaceOriginal:   true,
 		},
 	}
 }
 
+// isSelectArg returns true if the arg value is chosen from a dropdown.
+func isSelectArg(arg Arg) bool {
+	switch arg.Type {
+	case "bool", "dropdown", argTypeSecret, argTypeNamespace:
+		return true
+	}
+	return false
+}
+
+// optionValues returns values offered by a select arg.
+func optionValues(ctx context.Context, envs map[string]string, namespace string, option Arg) []string {
+	switch option.Type {
+	case "bool":
+		return []string{"true", "false"}
+	case argTypeSecret:
+		keys, err := getSecretKeys(ctx, envs, namespace, option.Secret)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list keys of secret %s: %v", option.Secret, err)
+		}
+		return keys
+	case argTypeNamespace:
+		namespaces, err := getNamespaces(ctx, envs, option.Selector)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list namespaces: %v", err)
+		}
+		return namespaces
+	}
+	if option.Registry != nil {
+		tags, err := listRegistryTags(ctx, *option.Registry)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list image tags: %v", err)
+		}
+		return tags
+	}
+	if option.ValuesFrom != nil {
+		values, err := getValuesFrom(ctx, envs, namespace, *option.ValuesFrom)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get values of %s: %v", option.Flag, err)
+		}
+		return values
+	}
+	return option.Values
+}
+
 // Helper function to check if all selections are made
 func allSelectionsMade(details stateDetails, options []Arg) bool {
 	for _, option := range options {
-		if details.params[fmt.Sprintf("%s-%s", details.job, option.Flag)] == "" {
+		if details.params[argKey(details.job, option)] == "" && !option.Optional {
 			return false
 		}
 	}
@@ -432,24 +902,80 @@ func allSelectionsMade(details stateDetails, options []Arg) bool {
 func buildFinalCommand(options []Arg, namespace string, details stateDetails) string {
 	var commandParts []string
 
+	// Deployments take no args, they are only restarted
+	if details.kind == kindDeployment {
+		return fmt.Sprintf("job restart %s %s", details.job, namespace)
+	}
+
 	// Add the first selection (e.g., job name)
-	commandParts = append(commandParts, details.job)
+	if details.kind == kindJob {
+		commandParts = append(commandParts, jobTemplatePrefix+details.job)
+	} else {
+		commandParts = append(commandParts, details.job)
+	}
 	commandParts = append(commandParts, namespace)
 
 	// Add options in the same order as they appear in the script output
+	var overrides, args []string
+	var positional []positionalArg
 	for _, option := range options {
 		// Construct the key as used in the state map
-		flagKey := fmt.Sprintf("%s-%s", details.job, option.Flag)
-		part := fmt.Sprintf("%s %s",option.Flag, details.params[flagKey])
-		if option.Type == "bool" {
+		flagKey := argKey(details.job, option)
+		if option.Optional && details.params[flagKey] == "" {
+			continue
+		}
+		if option.Type == argTypeSecret {
+			override, part := secretArgParts(option, details.params[flagKey])
+			overrides = append(overrides, override)
+			args = append(args, strings.Fields(part)...)
+			continue
+		}
+		if option.Target == argTargetImage {
+			overrides = append(overrides, shellQuote(fmt.Sprintf("%s:%s=%s", overrideImage, option.Container, argValue(details, option))))
+			continue
+		}
+		if option.Target == argTargetLimit {
+			overrides = append(overrides, shellQuote(fmt.Sprintf("%s:%s=%s", overrideLimit, option.Flag, strings.TrimSpace(argValue(details, option)))))
+			continue
+		}
+		if option.Target == argTargetResources {
+			overrides = append(overrides, shellQuote(resourceOverride(option, strings.TrimSpace(argValue(details, option)))))
+			continue
+		}
+		// several values of a dropdown run one job per value, see expandMatrix
+		if items := listItems(argValue(details, option)); option.Multiple && len(items) > 1 {
+			overrides = append(overrides, shellQuote(fmt.Sprintf("%s:%s=%s", overrideMatrix, option.Flag, strings.Join(items, ","))))
+			continue
+		}
+		if option.Target == argTargetEnv {
+			overrides = append(overrides, shellQuote(fmt.Sprintf("%s:%s=%s", overrideEnv, argEnvName(option), argValue(details, option))))
+			continue
+		}
+		// values are quoted, so they are passed as a single container arg whatever they contain
+		value := shellQuote(escapeEnvRefs(argValue(details, option)))
+		switch {
+		case option.Flag == "" && option.Position != nil:
+			positional = append(positional, positionalArg{Position: *option.Position, Value: value})
+		case option.Type == argTypeList:
+			for _, item := range listItems(argValue(details, option)) {
+				args = append(args, option.Flag, shellQuote(escapeEnvRefs(item)))
+			}
+		case option.Type == "bool":
 			if strings.Fields(details.params[flagKey])[1] == "true" {
-				part = strings.Fields(details.params[flagKey])[0]
-			} else {
-				continue
+				args = append(args, strings.Fields(details.params[flagKey])[0])
 			}
+		default:
+			args = append(args, option.Flag, value)
 		}
-		commandParts = append(commandParts, part)
 	}
+	args = insertPositional(args, positional)
+
+	// Overrides are separated from container args with "--"
+	if len(overrides) > 0 {
+		commandParts = append(commandParts, overrides...)
+		commandParts = append(commandParts, runArgsSeparator)
+	}
+	commandParts = append(commandParts, args...)
 
 	return fmt.Sprintf("job run %s", strings.Join(commandParts, " "))
 }
@@ -457,6 +983,17 @@ func buildFinalCommand(options []Arg, namespace string, details stateDetails) st
 func (MsgExecutor) Help(context.Context) (api.Message, error) {
 	msg := description
 	msg += fmt.Sprintf("\nJust type `%s %s`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nDescribe the parameters of a job with `%s %s help <name> [namespace]`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nCheck a launched job with `%s %s status <name> [namespace] [-o json|yaml]`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nPause or resume a CronJob schedule with `%s %s suspend|resume <name> [namespace]`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nChange a CronJob schedule with `%s %s schedule-edit <name> <namespace>`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nRestart an annotated deployment with `%s %s restart <deployment> <namespace>`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nList recent runs with `%s %s list [--mine] [--job <cronjob>] [-o json|yaml]`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nRead the logs of a launched job with `%s %s logs <name> [namespace] [--tail <lines>]`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nShow recorded runs with `%s %s history [cronjob]`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nFind jobs with invalid args definitions with `%s %s doctor`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nRun a job later with `%s %s schedule <delay|RFC 3339 time> <cronjob> <namespace> [args...]`", api.MessageBotNamePlaceholder, pluginName)
+	msg += fmt.Sprintf("\nDelete finished runs with `%s %s cleanup [--older-than 24h]`", api.MessageBotNamePlaceholder, pluginName)
 
 	return api.NewPlaintextMessage(msg, false), nil
 }
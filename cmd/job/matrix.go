@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+)
+
+// overrideMatrix runs one job per value of the flag: matrix:<flag>=<value>,<value>
+// With several matrix overrides, one job is run per combination of their values.
+const overrideMatrix = "matrix"
+
+// plannedRun is a single job of a run in several namespaces or with matrix args.
+type plannedRun struct {
+	Request runRequest
+	// Label describes the matrix values of the run, e.g. --region=eu --tier=web
+	Label string
+}
+
+// matrixSection renders the multi-select of a dropdown arg accepting several values.
+func matrixSection(arg Arg, values []string, selected string, command string) api.Section {
+	var options, initial []api.OptionItem
+	chosen := listItems(selected)
+	for _, value := range values {
+		option := api.OptionItem{Name: value, Value: value}
+		options = append(options, option)
+		if slices.Contains(chosen, value) {
+			initial = append(initial, option)
+		}
+	}
+	return api.Section{
+		MultiSelect: api.MultiSelect{
+			Name: arg.Description,
+			Description: api.Body{
+				Plaintext: fmt.Sprintf("%s, one job is run per selected value", arg.Description),
+			},
+			Command:        command,
+			Options:        options,
+			InitialOptions: initial,
+		},
+	}
+}
+
+// selectParam returns the value of the arg as stored in the form state: several values are kept
+// comma-separated, without the flag prefix of single-value selects.
+func selectParam(arg Arg, value string) string {
+	if arg.Multiple {
+		return value
+	}
+	return fmt.Sprintf("%s %s", arg.Flag, value)
+}
+
+// hasMatrix returns true if the run has matrix overrides.
+func hasMatrix(req runRequest) bool {
+	return slices.ContainsFunc(req.Overrides, func(o runOverride) bool { return o.Kind == overrideMatrix })
+}
+
+// expandMatrix returns one run per combination of the matrix values, with the matrix flags appended to the args.
+// Each run gets its own command, so it can be run again on its own.
+func expandMatrix(req runRequest) []plannedRun {
+	if !hasMatrix(req) {
+		return []plannedRun{{Request: req}}
+	}
+	base := req
+	base.Overrides = slices.DeleteFunc(slices.Clone(req.Overrides), func(o runOverride) bool { return o.Kind == overrideMatrix })
+	runs := []plannedRun{{Request: base}}
+	for _, override := range req.Overrides {
+		if override.Kind != overrideMatrix {
+			continue
+		}
+		var next []plannedRun
+		for _, run := range runs {
+			for _, value := range listItems(override.Value) {
+				expanded := run.Request
+				expanded.Args = append(slices.Clone(run.Request.Args), override.Key, value)
+				next = append(next, plannedRun{
+					Request: expanded,
+					Label:   strings.TrimSpace(fmt.Sprintf("%s %s=%s", run.Label, override.Key, value)),
+				})
+			}
+		}
+		runs = next
+	}
+	for i := range runs {
+		runs[i].Request.Command = withClusterFlag(fmt.Sprintf("%s run %s", pluginName, formatRunValue(runs[i].Request)), req.Cluster)
+		runs[i].Request.MatrixIndex = i + 1
+	}
+	return runs
+}
+
+// formatRunValue writes the request in the form parsed by parseRunRequest.
+func formatRunValue(req runRequest) string {
+	name := req.Name
+	if req.Kind == kindJob {
+		name = jobTemplatePrefix + name
+	}
+	parts := []string{name, req.Namespace}
+	for _, override := range req.Overrides {
+		parts = append(parts, shellQuote(fmt.Sprintf("%s:%s=%s", override.Kind, override.Key, override.Value)))
+	}
+	if len(req.Overrides) > 0 {
+		parts = append(parts, runArgsSeparator)
+	}
+	for _, arg := range req.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const argTypeNamespace = "namespace"
+
+// getNamespaces returns names of cluster namespaces, optionally filtered by a label selector.
+func getNamespaces(ctx context.Context, envs map[string]string, selector string) ([]string, error) {
+	getCmd := "kubectl get namespaces -o jsonpath={.items[*].metadata.name}"
+	if selector != "" {
+		getCmd = fmt.Sprintf("%s -l %s", getCmd, selector)
+	}
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return nil, fmt.Errorf("while listing namespaces: %w", err)
+	}
+	return strings.Fields(out.Stdout), nil
+}
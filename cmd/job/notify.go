@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// maxSectionTextLen is the Slack limit for the text of a section block.
+	maxSectionTextLen = 3000
+	// cmdButtonActionIDPrefix must match the prefix used by Botkube to recognize command buttons.
+	cmdButtonActionIDPrefix = "cmd:"
+	maxActionIDLen          = 255
+	codeBlockFence          = "```"
+)
+
+// permalinkRegex extracts the channel ID from a Slack message permalink,
+// e.g. https://example.slack.com/archives/C0123456789/p1700000000000100
+var permalinkRegex = regexp.MustCompile(`/archives/([A-Z0-9]+)/p\d+`)
+
+// channelFromURL returns the channel ID from the permalink of the triggering message.
+func channelFromURL(url string) string {
+	matches := permalinkRegex.FindStringSubmatch(url)
+	if len(matches) != 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// notifier posts follow-up messages to Slack outside of the Execute request/response cycle.
+type notifier struct {
+	client   *slack.Client
+	botName  string
+	channel  string
+	threadTS string
+	// user receives the messages as ephemeral messages in the channel when set.
+	user string
+}
+
+func newNotifier(cfg Config, msg executor.Message) (*notifier, error) {
+	if cfg.BotToken == "" {
+		return nil, errors.New("botToken is not configured")
+	}
+
+	channel := channelFromURL(msg.URL)
+	if channel == "" {
+		channel = cfg.ChannelID
+	}
+	if channel == "" {
+		return nil, errors.New("cannot resolve channel for follow-up messages")
+	}
+
+	return &notifier{
+		client:   slack.New(cfg.BotToken),
+		botName:  cfg.BotName,
+		channel:  channel,
+		threadTS: msg.ParentActivityID,
+	}, nil
+}
+
+// Post sends a markdown message to the channel, in the thread of the triggering message if known.
+// Buttons are rendered the same way as Botkube does, so clicking them runs the plugin command.
+func (n *notifier) Post(ctx context.Context, text string, buttons ...api.Button) error {
+	_, err := n.PostMessage(ctx, text, buttons...)
+	return err
+}
+
+// PostMessage posts the message like Post and returns its timestamp, so it can be updated later.
+func (n *notifier) PostMessage(ctx context.Context, text string, buttons ...api.Button) (string, error) {
+	opts := n.messageOptions(text, buttons)
+	if n.threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(n.threadTS))
+	}
+	if n.user != "" {
+		ts, err := n.client.PostEphemeralContext(ctx, n.channel, n.user, opts...)
+		if err != nil {
+			return "", fmt.Errorf("while posting ephemeral message to channel %s: %w", n.channel, err)
+		}
+		return ts, nil
+	}
+
+	_, ts, err := n.client.PostMessageContext(ctx, n.channel, opts...)
+	if err != nil {
+		return "", fmt.Errorf("while posting message to channel %s: %w", n.channel, err)
+	}
+	return ts, nil
+}
+
+// Update replaces the text and buttons of a message posted by the notifier.
+func (n *notifier) Update(ctx context.Context, ts, text string, buttons ...api.Button) error {
+	if _, _, _, err := n.client.UpdateMessageContext(ctx, n.channel, ts, n.messageOptions(text, buttons)...); err != nil {
+		return fmt.Errorf("while updating message in channel %s: %w", n.channel, err)
+	}
+	return nil
+}
+
+func (n *notifier) messageOptions(text string, buttons []api.Button) []slack.MsgOption {
+	opts := []slack.MsgOption{
+		slack.MsgOptionText(text, false),
+	}
+	if len(buttons) > 0 {
+		opts = append(opts, slack.MsgOptionBlocks(n.renderBlocks(text, buttons)...))
+	}
+	return opts
+}
+
+func (n *notifier) renderBlocks(text string, buttons []api.Button) []slack.Block {
+	var blocks []slack.Block
+	for _, chunk := range splitText(text, maxSectionTextLen) {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, chunk, false, false), nil, nil))
+	}
+
+	var elems []slack.BlockElement
+	for _, btn := range buttons {
+		cmd := strings.ReplaceAll(btn.Command, api.MessageBotNamePlaceholder, n.botName)
+		actionID := cmdButtonActionIDPrefix + cmd
+		if len(actionID) > maxActionIDLen {
+			actionID = actionID[:maxActionIDLen]
+		}
+		elem := slack.NewButtonBlockElement(actionID, cmd, slack.NewTextBlockObject(slack.PlainTextType, btn.Name, true, false))
+		if btn.Style != api.ButtonStyleDefault {
+			elem.Style = slack.Style(btn.Style)
+		}
+		elems = append(elems, elem)
+	}
+	return append(blocks, slack.NewActionBlock("", elems...))
+}
+
+// splitText splits text into chunks of at most maxLen bytes on line boundaries.
+// Code blocks cut in half are closed and reopened, so each chunk renders correctly.
+func splitText(text string, maxLen int) []string {
+	// reserve space for closing and reopening a code block
+	limit := maxLen - 2*(len(codeBlockFence)+1)
+
+	var (
+		chunks []string
+		cur    strings.Builder
+		inCode bool
+	)
+	flush := func() {
+		if cur.Len() == 0 {
+			return
+		}
+		chunk := cur.String()
+		if inCode {
+			chunk += "\n" + codeBlockFence
+		}
+		chunks = append(chunks, chunk)
+		cur.Reset()
+		if inCode {
+			cur.WriteString(codeBlockFence)
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if len(line) > limit {
+			line = line[:limit]
+		}
+		if cur.Len()+len(line)+1 > limit {
+			flush()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString("\n")
+		}
+		cur.WriteString(line)
+		if strings.Count(line, codeBlockFence)%2 == 1 {
+			inCode = !inCode
+		}
+	}
+	if cur.Len() > 0 {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
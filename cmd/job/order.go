@@ -0,0 +1,26 @@
+package main
+
+import (
+	"slices"
+)
+
+// orderArgs sorts args by their order field. Args without an order keep their definition order after the ordered ones.
+func orderArgs(args []Arg) []Arg {
+	slices.SortStableFunc(args, func(a, b Arg) int {
+		switch {
+		case a.Order == nil && b.Order == nil:
+			return 0
+		case a.Order == nil:
+			return 1
+		case b.Order == nil:
+			return -1
+		}
+		return *a.Order - *b.Order
+	})
+	return args
+}
+
+// hasArgOrder returns true if any arg has an explicit order, the form then renders args in sequence.
+func hasArgOrder(args []Arg) bool {
+	return slices.ContainsFunc(args, func(a Arg) bool { return a.Order != nil })
+}
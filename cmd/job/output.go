@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	batchv1 "k8s.io/api/batch/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Machine-readable output formats of the list and status subcommands, selected with -o.
+const (
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// jobSummary is the machine-readable form of a botkube-created job.
+type jobSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Phase     string `json:"phase"`
+	// Source is the CronJob or Job template the job was created from.
+	Source         string     `json:"source,omitempty"`
+	SourceKind     string     `json:"sourceKind,omitempty"`
+	Requester      string     `json:"requester,omitempty"`
+	RequesterID    string     `json:"requesterID,omitempty"`
+	Approver       string     `json:"approver,omitempty"`
+	Channel        string     `json:"channel,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+	Active         int32      `json:"active"`
+	Succeeded      int32      `json:"succeeded"`
+	Failed         int32      `json:"failed"`
+	runAudit
+	// Command runs the job again with the same parameters.
+	Command string `json:"command,omitempty"`
+}
+
+// summarizeJob returns the status and audit metadata of the job.
+func summarizeJob(job batchv1.Job) jobSummary {
+	summary := jobSummary{
+		Name:        job.Name,
+		Namespace:   job.Namespace,
+		Phase:       jobPhase(job),
+		Source:      job.Labels[sourceLabel],
+		SourceKind:  job.Labels[sourceKindLabel],
+		Requester:   job.Annotations[requesterAnnotation],
+		RequesterID: job.Labels[requesterIDLabel],
+		Approver:    job.Annotations[approverAnnotation],
+		Channel:     job.Labels[channelLabel],
+		CreatedAt:   job.CreationTimestamp.UTC(),
+		Active:      job.Status.Active,
+		Succeeded:   job.Status.Succeeded,
+		Failed:      job.Status.Failed,
+		Command:     job.Annotations[runCommandAnnotation],
+	}
+	if job.Status.StartTime != nil {
+		start := job.Status.StartTime.UTC()
+		summary.StartTime = &start
+	}
+	if job.Status.CompletionTime != nil {
+		completion := job.Status.CompletionTime.UTC()
+		summary.CompletionTime = &completion
+	}
+	// jobs created before the args were recorded have no audit annotation
+	_ = json.Unmarshal([]byte(job.Annotations[argsAnnotation]), &summary.runAudit)
+	return summary
+}
+
+// cutOutputFormat removes "-o <format>" from the subcommand value and returns the format, empty if not given.
+func cutOutputFormat(value string) (format, rest string, err error) {
+	var kept []string
+	fields := strings.Fields(value)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case field == "-o" || field == "--output":
+			if i+1 >= len(fields) {
+				return "", "", fmt.Errorf("%s requires a format, %s or %s", field, outputJSON, outputYAML)
+			}
+			format = fields[i+1]
+			i++
+		case strings.HasPrefix(field, "-o=") || strings.HasPrefix(field, "--output="):
+			_, format, _ = strings.Cut(field, "=")
+		default:
+			kept = append(kept, field)
+			continue
+		}
+		if format != outputJSON && format != outputYAML {
+			return "", "", fmt.Errorf("unsupported output format %q, use %s or %s", format, outputJSON, outputYAML)
+		}
+	}
+	return format, strings.Join(kept, " "), nil
+}
+
+// formatOutput encodes the value in the output format.
+func formatOutput(format string, v interface{}) (string, error) {
+	var out []byte
+	var err error
+	if format == outputYAML {
+		out, err = yaml.Marshal(v)
+	} else {
+		out, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return "", fmt.Errorf("while encoding %s output: %w", format, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// machineOutput replies with the encoded value only, in a code block without buttons, so it can be parsed as is.
+func machineOutput(format string, v interface{}) executor.ExecuteOutput {
+	out, err := formatOutput(format, v)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(out, true),
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+)
+
+var (
+	// webhookDenialRegex matches admission webhook denials, e.g. of Gatekeeper or Kyverno.
+	webhookDenialRegex = regexp.MustCompile(`(?s)admission webhook "([^"]+)" denied the request:(.*)`)
+	// admissionPolicyDenialRegex matches denials of ValidatingAdmissionPolicies.
+	admissionPolicyDenialRegex = regexp.MustCompile(`(?s)ValidatingAdmissionPolicy '([^']+)'.* denied request:(.*)`)
+)
+
+// policyViolations is returned when admission policies deny the job, e.g. Gatekeeper constraints or Kyverno policies.
+type policyViolations struct {
+	Job string
+	// Policy is the admission webhook or ValidatingAdmissionPolicy that denied the job.
+	Policy     string
+	Violations []string
+}
+
+func (p policyViolations) Error() string {
+	return fmt.Sprintf("job %s violates cluster policies (%s):\n- %s", p.Job, p.Policy, strings.Join(p.Violations, "\n- "))
+}
+
+// serverDryRun sends the manifest to the API server with a server-side dry run, so validation and admission
+// webhooks run without creating anything. Denials by admission policies are returned as policyViolations.
+func serverDryRun(ctx context.Context, envs map[string]string, job string, manifest []byte) error {
+	createCmd := "kubectl create -f - --dry-run=server -ojson"
+	out, err := kubectlStdin(ctx, envs, createCmd, manifest)
+	if err == nil {
+		return nil
+	}
+	reason := strings.TrimSpace(out.Stderr)
+	if reason == "" {
+		return err
+	}
+	if violations, ok := parsePolicyViolations(job, reason); ok {
+		return violations
+	}
+	return fmt.Errorf("%s", reason)
+}
+
+// parsePolicyViolations extracts the policy and its messages from a denial reported by kubectl.
+// Kyverno reports violations per policy, indented below the policy name, they are prefixed with it.
+func parsePolicyViolations(job, reason string) (policyViolations, bool) {
+	match := webhookDenialRegex.FindStringSubmatch(reason)
+	if match == nil {
+		match = admissionPolicyDenialRegex.FindStringSubmatch(reason)
+	}
+	if match == nil {
+		return policyViolations{}, false
+	}
+
+	out := policyViolations{Job: job, Policy: match[1]}
+	var prefix string
+	var prefixIndent, lastIndent int
+	continued := false
+	for _, line := range strings.Split(match[2], "\n") {
+		text := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		switch {
+		case text == "" || strings.HasPrefix(text, "policy ") || strings.HasPrefix(text, "resource "):
+			// Kyverno headers naming the denied resource
+			continued = false
+		case strings.HasSuffix(text, ":"):
+			prefix, prefixIndent = strings.TrimSuffix(text, ":"), indent
+			continued = false
+		case continued && indent > lastIndent:
+			// wrapped message of the previous violation
+			out.Violations[len(out.Violations)-1] += " " + text
+		default:
+			if prefix != "" && indent > prefixIndent {
+				text = fmt.Sprintf("%s: %s", prefix, text)
+			}
+			out.Violations = append(out.Violations, text)
+			lastIndent, continued = indent, true
+		}
+	}
+	if len(out.Violations) == 0 {
+		out.Violations = []string{"denied without a message"}
+	}
+	return out, true
+}
+
+// policyViolationsMessage lists the violations, so the user can fix the args instead of retrying.
+func policyViolationsMessage(p policyViolations) api.Message {
+	return api.Message{
+		Sections: []api.Section{
+			{
+				Base: api.Base{
+					Header:      fmt.Sprintf(":no_entry: Job %s violates cluster policies", p.Job),
+					Description: fmt.Sprintf("The job was not created, %s denied it:", p.Policy),
+				},
+				BulletLists: api.BulletLists{
+					{Items: p.Violations},
+				},
+				Context: api.ContextItems{
+					{Text: "Change the parameters to comply with the policies, or ask your cluster administrator for an exception."},
+				},
+			},
+		},
+		OnlyVisibleForYou: true,
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// positionalArg is the value of an arg without a flag and its index in the container args.
+type positionalArg struct {
+	Position int
+	Value    string
+}
+
+// argName identifies the arg, positional args by their position, e.g. #0.
+func argName(arg Arg) string {
+	if arg.Flag == "" && arg.Position != nil {
+		return fmt.Sprintf("#%d", *arg.Position)
+	}
+	return arg.Flag
+}
+
+// argKey is the key of the arg value in the form state.
+func argKey(job string, arg Arg) string {
+	return fmt.Sprintf("%s-%s", job, argName(arg))
+}
+
+// insertPositional places positional values at their index in the container args, in the order of positions.
+// Positions past the end of the args are appended.
+func insertPositional(args []string, positional []positionalArg) []string {
+	slices.SortStableFunc(positional, func(a, b positionalArg) int { return a.Position - b.Position })
+	for _, p := range positional {
+		idx := min(p.Position, len(args))
+		args = slices.Insert(args, idx, p.Value)
+	}
+	return args
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	"sigs.k8s.io/yaml"
+)
+
+// maxManifestLen keeps the manifest within the Slack limit of a section text.
+const maxManifestLen = 2800
+
+// previewJob renders the changes of the Job relative to its CronJob or Job template, with buttons to confirm or cancel the run.
+// The full manifest is shown on request to keep the preview short.
+func previewJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string, withManifest bool) executor.ExecuteOutput {
+	req, err := parseRunRequest(value, scope)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	source, err := authorizeRun(ctx, envs, cfg, scope, req)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	req = withLimitDefaults(req, source.Metadata.Annotations)
+
+	jobName, err := renderJobName(cfg, req, time.Now())
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	job, base, err := renderJob(ctx, envs, cfg, req, jobName)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	diff, err := manifestDiff(base, job)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	btnBuilder := api.NewMessageButtonBuilder()
+	buttons := []api.Button{
+		btnBuilder.ForCommandWithoutDesc("Confirm", fmt.Sprintf("%s run %s", pluginName, value), api.ButtonStylePrimary),
+	}
+	sections := []api.Section{
+		{
+			Base: api.Base{
+				Header:      fmt.Sprintf("Run %s in %s", req.Name, req.Namespace),
+				Description: "Changes relative to the template:",
+				Body: api.Body{
+					CodeBlock: truncateManifest(diff, maxManifestLen),
+				},
+			},
+		},
+	}
+	// Quota problems don't fail the job, its pods would be rejected or stay Pending, so they are shown before confirming
+	if warnings := quotaWarnings(ctx, envs, req.Namespace, job); len(warnings) > 0 {
+		sections = append(sections, api.Section{
+			Base: api.Base{
+				Header:      ":warning: The run may exceed the namespace quota",
+				Description: fmt.Sprintf("The job pods may be rejected or stay Pending in %s:", req.Namespace),
+			},
+			BulletLists: api.BulletLists{
+				{Items: warnings},
+			},
+		})
+	}
+	if withManifest {
+		manifest, err := yaml.Marshal(job)
+		if err != nil {
+			return executor.ExecuteOutput{
+				Message: api.NewCodeBlockMessage(fmt.Sprintf("while rendering manifest: %v", err), true),
+			}
+		}
+		sections = append(sections, api.Section{
+			Base: api.Base{
+				Description: "Job manifest:",
+				Body: api.Body{
+					CodeBlock: truncateManifest(string(manifest), maxManifestLen),
+				},
+			},
+		})
+	} else {
+		buttons = append(buttons, btnBuilder.ForCommandWithoutDesc("Show manifest", fmt.Sprintf("%s manifest %s", pluginName, value)))
+	}
+	buttons = append(buttons, btnBuilder.ForCommandWithoutDesc("Cancel", fmt.Sprintf("%s cancel", pluginName), api.ButtonStyleDanger))
+	sections[len(sections)-1].Buttons = buttons
+
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections:          sections,
+			OnlyVisibleForYou: true,
+			ReplaceOriginal:   true,
+		},
+	}
+}
+
+// runButtons returns buttons of the final command. Job runs are previewed first and can be dry-run,
+// or scheduled if a delay is selected. Other commands, e.g. restarts, are run as is.
+func runButtons(btnBuilder *api.ButtonBuilder, cmd, delay string) []api.Button {
+	value, found := strings.CutPrefix(cmd, pluginName+" run ")
+	if !found {
+		return []api.Button{
+			btnBuilder.ForCommandWithoutDesc("Run command", cmd, api.ButtonStylePrimary),
+		}
+	}
+	// runs creating several jobs are neither previewed nor delayed
+	if isMultiRun(value) {
+		return []api.Button{
+			btnBuilder.ForCommandWithoutDesc("Run all", cmd, api.ButtonStylePrimary),
+		}
+	}
+	if delay != "" && delay != runNow {
+		return []api.Button{
+			btnBuilder.ForCommandWithoutDesc(fmt.Sprintf("Run in %s", delay), fmt.Sprintf("%s schedule %s %s", pluginName, delay, value), api.ButtonStylePrimary),
+		}
+	}
+	return []api.Button{
+		btnBuilder.ForCommandWithoutDesc("Run command", fmt.Sprintf("%s preview %s", pluginName, value), api.ButtonStylePrimary),
+		btnBuilder.ForCommandWithoutDesc("Dry run", fmt.Sprintf("%s dryrun %s", pluginName, value)),
+	}
+}
+
+// cancelPreview replaces the preview, so the run cannot be confirmed anymore.
+func cancelPreview() executor.ExecuteOutput {
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			BaseBody: api.Body{
+				Plaintext: "Run cancelled",
+			},
+			OnlyVisibleForYou: true,
+			ReplaceOriginal:   true,
+		},
+	}
+}
+
+// manifestDiff lists fields that differ between the manifests, one "<path>: <value>" line per field
+// prefixed with "-" for the template value and "+" for the job value.
+func manifestDiff(before, after map[string]interface{}) (string, error) {
+	beforeFields, err := flattenManifest(before)
+	if err != nil {
+		return "", err
+	}
+	afterFields, err := flattenManifest(after)
+	if err != nil {
+		return "", err
+	}
+
+	paths := map[string]struct{}{}
+	for path := range beforeFields {
+		paths[path] = struct{}{}
+	}
+	for path := range afterFields {
+		paths[path] = struct{}{}
+	}
+	var sorted []string
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var out strings.Builder
+	for _, path := range sorted {
+		oldVal, hadOld := beforeFields[path]
+		newVal, hasNew := afterFields[path]
+		if hadOld && hasNew && oldVal == newVal {
+			continue
+		}
+		if hadOld {
+			fmt.Fprintf(&out, "- %s: %s\n", path, oldVal)
+		}
+		if hasNew {
+			fmt.Fprintf(&out, "+ %s: %s\n", path, newVal)
+		}
+	}
+	if out.Len() == 0 {
+		return "No changes", nil
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// flattenManifest maps field paths, e.g. spec.template.spec.containers[0].image, to their JSON-encoded values.
+func flattenManifest(manifest map[string]interface{}) (map[string]string, error) {
+	// normalize typed values, e.g. []string args, to their JSON representation
+	normalized, err := copyManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	flattenValue("", normalized, fields)
+	return fields, nil
+}
+
+func flattenValue(path string, value interface{}, fields map[string]string) {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			itemPath := key
+			if path != "" {
+				itemPath = path + "." + key
+			}
+			flattenValue(itemPath, item, fields)
+		}
+	case []interface{}:
+		for i, item := range val {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), item, fields)
+		}
+	default:
+		raw, _ := json.Marshal(val)
+		fields[path] = string(raw)
+	}
+}
+
+// copyManifest returns a deep copy of the manifest.
+func copyManifest(manifest map[string]interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("while copying manifest: %w", err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("while copying manifest: %w", err)
+	}
+	return out, nil
+}
+
+// truncateManifest keeps the beginning of the manifest, which holds the fields most likely changed.
+func truncateManifest(manifest string, maxLen int) string {
+	if len(manifest) <= maxLen {
+		return manifest
+	}
+	cut := manifest[:maxLen]
+	if idx := strings.LastIndex(cut, "\n"); idx > 0 {
+		cut = cut[:idx]
+	}
+	return cut + "\n... (truncated)"
+}
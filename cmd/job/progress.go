@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// jobProgress is a live message of a running job, edited in place until the job finishes.
+// The ephemeral "Job X is started" reply cannot be edited outside of the Execute call,
+// so the progress is posted as a separate message of the bot.
+type jobProgress struct {
+	n         *notifier
+	ts        string
+	namespace string
+	name      string
+	started   time.Time
+}
+
+// startProgress posts the initial progress message. It returns nil if the message cannot be posted.
+func startProgress(ctx context.Context, n *notifier, namespace, name string) *jobProgress {
+	p := &jobProgress{n: n, namespace: namespace, name: name, started: time.Now()}
+	ts, err := n.PostMessage(ctx, p.text("Pending"), p.buttons()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to post progress of job %s: %v", name, err)
+		return nil
+	}
+	p.ts = ts
+	return p
+}
+
+// run updates the message with the elapsed time and the phases of the job pods until ctx is done.
+func (p *jobProgress) run(ctx context.Context, envs map[string]string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		phase, err := podPhases(ctx, envs, p.namespace, p.name)
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "failed to get pods of job %s: %v", p.name, err)
+			}
+			continue
+		}
+		if err := p.n.Update(ctx, p.ts, p.text(phase), p.buttons()...); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "failed to update progress of job %s: %v", p.name, err)
+		}
+	}
+}
+
+// finish replaces the progress with the final result of the job.
+func (p *jobProgress) finish(ctx context.Context, text string, buttons ...api.Button) error {
+	return p.n.Update(ctx, p.ts, text, buttons...)
+}
+
+func (p *jobProgress) text(phase string) string {
+	elapsed := time.Since(p.started).Round(time.Second)
+	return fmt.Sprintf("Job *%s* in namespace *%s* is running for %s\n```\nPods: %s\n```", p.name, p.namespace, elapsed, phase)
+}
+
+func (p *jobProgress) buttons() []api.Button {
+	btnBuilder := api.NewMessageButtonBuilder()
+	return []api.Button{
+		btnBuilder.ForCommandWithoutDesc("Status", fmt.Sprintf("%s status %s %s", pluginName, p.name, p.namespace)),
+		btnBuilder.ForCommandWithoutDesc("Cancel", fmt.Sprintf("%s delete %s %s", pluginName, p.name, p.namespace), api.ButtonStyleDanger),
+	}
+}
+
+// podPhases summarizes the phases of the job pods, e.g. "Running 1, Failed 2".
+func podPhases(ctx context.Context, envs map[string]string, namespace, name string) (string, error) {
+	podsCmd := fmt.Sprintf("kubectl get pods -n %s -l job-name=%s -ojson", namespace, name)
+	out, err := kubectl(ctx, envs, podsCmd)
+	if err != nil {
+		return "", err
+	}
+	var pods corev1.PodList
+	if err := json.Unmarshal([]byte(out.Stdout), &pods); err != nil {
+		return "", fmt.Errorf("while unmarshalling pods: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return "Pending", nil
+	}
+
+	counts := map[string]int{}
+	for _, pod := range pods.Items {
+		counts[string(pod.Status.Phase)]++
+	}
+	var phases []string
+	for phase, count := range counts {
+		phases = append(phases, fmt.Sprintf("%s %d", phase, count))
+	}
+	sort.Strings(phases)
+	return strings.Join(phases, ", "), nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// quotaWarnings checks the job against the ResourceQuotas and LimitRanges of its namespace and describes why
+// its pods would not be created or would stay Pending. Lookup failures are logged, they don't block the run.
+func quotaWarnings(ctx context.Context, envs map[string]string, namespace string, manifest map[string]interface{}) []string {
+	raw, err := json.Marshal(manifest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to check quota: while marshalling job: %v", err)
+		return nil
+	}
+	var job batchv1.Job
+	if err := json.Unmarshal(raw, &job); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to check quota: while unmarshalling job: %v", err)
+		return nil
+	}
+
+	var ranges corev1.LimitRangeList
+	if err := getList(ctx, envs, "limitranges", namespace, &ranges); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to check limit ranges of namespace %s: %v", namespace, err)
+	}
+	var quotas corev1.ResourceQuotaList
+	if err := getList(ctx, envs, "resourcequotas", namespace, &quotas); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to check resource quotas of namespace %s: %v", namespace, err)
+	}
+
+	pods := int64(1)
+	if job.Spec.Parallelism != nil {
+		pods = int64(*job.Spec.Parallelism)
+	}
+	if job.Spec.Completions != nil && int64(*job.Spec.Completions) < pods {
+		pods = int64(*job.Spec.Completions)
+	}
+	spec := job.Spec.Template.Spec
+	warnings := applyLimitRanges(&spec, ranges.Items)
+	return append(warnings, checkQuotas(spec, pods, quotas.Items)...)
+}
+
+// getList gets all objects of the resource in the namespace.
+func getList(ctx context.Context, envs map[string]string, resource, namespace string, into interface{}) error {
+	getCmd := fmt.Sprintf("kubectl get %s -n %s -ojson", resource, namespace)
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return fmt.Errorf("while getting %s: %w", resource, err)
+	}
+	if err := json.Unmarshal([]byte(out.Stdout), into); err != nil {
+		return fmt.Errorf("while unmarshalling %s: %w", resource, err)
+	}
+	return nil
+}
+
+// applyLimitRanges sets the container defaults of the LimitRanges, the same way the API server admits the pod,
+// and reports containers exceeding their minimum or maximum.
+func applyLimitRanges(spec *corev1.PodSpec, ranges []corev1.LimitRange) []string {
+	var warnings []string
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			c := &containers[i]
+			for _, lr := range ranges {
+				for _, item := range lr.Spec.Limits {
+					if item.Type != corev1.LimitTypeContainer {
+						continue
+					}
+					c.Resources.Limits = withDefaults(c.Resources.Limits, item.Default)
+					c.Resources.Requests = withDefaults(c.Resources.Requests, item.DefaultRequest)
+					// without a default request, the request defaults to the limit
+					c.Resources.Requests = withDefaults(c.Resources.Requests, c.Resources.Limits)
+
+					for name, max := range item.Max {
+						if limit, ok := c.Resources.Limits[name]; ok && limit.Cmp(max) > 0 {
+							warnings = append(warnings, fmt.Sprintf("LimitRange %s: container %s limits %s to %s, the maximum is %s", lr.Name, c.Name, name, limit.String(), max.String()))
+						}
+					}
+					for name, min := range item.Min {
+						if request, ok := c.Resources.Requests[name]; ok && request.Cmp(min) < 0 {
+							warnings = append(warnings, fmt.Sprintf("LimitRange %s: container %s requests %s %s, the minimum is %s", lr.Name, c.Name, request.String(), name, min.String()))
+						}
+					}
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+func withDefaults(list, defaults corev1.ResourceList) corev1.ResourceList {
+	for name, value := range defaults {
+		if _, ok := list[name]; ok {
+			continue
+		}
+		if list == nil {
+			list = corev1.ResourceList{}
+		}
+		list[name] = value.DeepCopy()
+	}
+	return list
+}
+
+// checkQuotas reports quotas the pods of the job would exceed, and compute quotas requiring requests or limits
+// the containers don't set. Scoped quotas are skipped, as they may not apply to the job pods.
+func checkQuotas(spec corev1.PodSpec, pods int64, quotas []corev1.ResourceQuota) []string {
+	var warnings []string
+	for _, quota := range quotas {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		names := make([]string, 0, len(quota.Status.Hard))
+		for name := range quota.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			hard := quota.Status.Hard[corev1.ResourceName(name)]
+			needed, missing, ok := quotaUsage(spec, pods, name)
+			if !ok {
+				continue
+			}
+			if missing {
+				warnings = append(warnings, fmt.Sprintf("ResourceQuota %s: every container must set %s, the pods would be rejected", quota.Name, name))
+				continue
+			}
+			used := quota.Status.Used[corev1.ResourceName(name)]
+			total := used.DeepCopy()
+			total.Add(needed)
+			if total.Cmp(hard) > 0 {
+				warnings = append(warnings, fmt.Sprintf("ResourceQuota %s: %s would reach %s of %s (%s used, the job needs %s)", quota.Name, name, total.String(), hard.String(), used.String(), needed.String()))
+			}
+		}
+	}
+	return warnings
+}
+
+// quotaUsage returns how much of the quota resource the job takes, and whether a container lacks the request
+// or limit the quota counts. ok is false for resources not affected by the job.
+func quotaUsage(spec corev1.PodSpec, pods int64, name string) (needed resource.Quantity, missing bool, ok bool) {
+	switch name {
+	case string(corev1.ResourcePods):
+		return *resource.NewQuantity(pods, resource.DecimalSI), false, true
+	case "count/jobs.batch":
+		return *resource.NewQuantity(1, resource.DecimalSI), false, true
+	}
+
+	limits := strings.HasPrefix(name, "limits.")
+	resourceName := corev1.ResourceName(strings.TrimPrefix(strings.TrimPrefix(name, "limits."), "requests."))
+	switch resourceName {
+	case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage:
+	default:
+		return resource.Quantity{}, false, false
+	}
+
+	// the pod takes the larger of its containers sum and its largest init container
+	var sum, initMax resource.Quantity
+	for _, c := range spec.Containers {
+		list := c.Resources.Requests
+		if limits {
+			list = c.Resources.Limits
+		}
+		value, found := list[resourceName]
+		if !found {
+			return resource.Quantity{}, true, true
+		}
+		sum.Add(value)
+	}
+	for _, c := range spec.InitContainers {
+		list := c.Resources.Requests
+		if limits {
+			list = c.Resources.Limits
+		}
+		if value := list[resourceName]; value.Cmp(initMax) > 0 {
+			initMax = value.DeepCopy()
+		}
+	}
+	if initMax.Cmp(sum) > 0 {
+		sum = initMax
+	}
+
+	needed = sum.DeepCopy()
+	for i := int64(1); i < pods; i++ {
+		needed.Add(sum)
+	}
+	return needed, false, true
+}
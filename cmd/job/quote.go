@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Run commands travel through Slack buttons and code blocks as text, so arg values that contain spaces or quotes
+// are shell-quoted when the command is built and split back with splitArgs. The values are never passed to a shell,
+// the args end up in the Job manifest.
+
+// shellQuote returns the value unchanged if it is safe to use as a single word, otherwise single-quoted.
+func shellQuote(value string) string {
+	if value != "" && strings.IndexFunc(value, func(r rune) bool { return !isSafeArgRune(r) }) < 0 {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func isSafeArgRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./:=,@%+$()", r)
+}
+
+// splitArgs splits the command text into words. Single quotes keep the text as is, double quotes and
+// backslashes work as in a POSIX shell. Unlike a shell, no other characters are special.
+func splitArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				cur.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				args = append(args, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote in command")
+	}
+	if inWord {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// cutField returns the first whitespace-separated word of s and the rest of s with its spacing preserved.
+func cutField(s string) (field, rest string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	idx := strings.IndexFunc(s, unicode.IsSpace)
+	if idx < 0 {
+		return s, ""
+	}
+	return s[:idx], strings.TrimLeftFunc(s[idx:], unicode.IsSpace)
+}
+
+// escapeEnvRefs escapes $(VAR) references, which Kubernetes would otherwise expand in container args
+// with values of the container env, such as injected secrets. Escaped references, $$(VAR), are kept,
+// so escaping a value again doesn't change it.
+func escapeEnvRefs(value string) string {
+	var out strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '$' && i+1 < len(value) && (value[i+1] == '$' || value[i+1] == '(') {
+			// $$ is the escaped $, only $( starts a reference
+			out.WriteString("$$")
+			i++
+			if value[i] == '(' {
+				out.WriteByte('(')
+			}
+			continue
+		}
+		out.WriteByte(value[i])
+	}
+	return out.String()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		quoted string
+	}{
+		{name: "safe word", value: "team-a/app:v1.2", quoted: "team-a/app:v1.2"},
+		{name: "empty", value: "", quoted: "''"},
+		{name: "space", value: "hello world", quoted: "'hello world'"},
+		{name: "single quote", value: "it's", quoted: `'it'\''s'`},
+		{name: "double quote", value: `say "hi"`, quoted: `'say "hi"'`},
+		{name: "backslash", value: `C:\tmp`, quoted: `'C:\tmp'`},
+		{name: "shell syntax", value: "a;b|c&d`e`", quoted: "'a;b|c&d`e`'"},
+		{name: "env reference", value: "$(TOKEN)", quoted: "$(TOKEN)"},
+		{name: "newline", value: "a\nb", quoted: "'a\nb'"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			quoted := shellQuote(tc.value)
+			if quoted != tc.quoted {
+				t.Errorf("shellQuote(%q) = %q, want %q", tc.value, quoted, tc.quoted)
+			}
+			args, err := splitArgs("--flag " + quoted)
+			if err != nil {
+				t.Fatalf("splitArgs(%q) failed: %v", quoted, err)
+			}
+			if want := []string{"--flag", tc.value}; !reflect.DeepEqual(args, want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", quoted, args, want)
+			}
+		})
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{name: "words", in: "  backup  team-a --env prod ", want: []string{"backup", "team-a", "--env", "prod"}},
+		{name: "single quotes keep backslashes", in: `'a \" b'`, want: []string{`a \" b`}},
+		{name: "double quotes unescape", in: `"a \" b"`, want: []string{`a " b`}},
+		{name: "escaped space", in: `a\ b c`, want: []string{"a b", "c"}},
+		{name: "adjacent quotes join", in: `'a'"b"c`, want: []string{"abc"}},
+		{name: "empty quotes", in: `--msg ''`, want: []string{"--msg", ""}},
+		{name: "no special characters", in: "a;b|c $(X)", want: []string{"a;b|c", "$(X)"}},
+		{name: "unterminated single quote", in: "'a", wantErr: true},
+		{name: "unterminated double quote", in: `"a`, wantErr: true},
+		{name: "trailing backslash", in: `a\`, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := splitArgs(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("splitArgs(%q) error = %v, wantErr %v", tc.in, err, tc.wantErr)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEscapeEnvRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "no reference", in: "plain $HOME value", want: "plain $HOME value"},
+		{name: "reference", in: "$(TOKEN)", want: "$$(TOKEN)"},
+		{name: "reference in value", in: "a$(X)b", want: "a$$(X)b"},
+		{name: "escaped reference", in: "$$(TOKEN)", want: "$$(TOKEN)"},
+		{name: "escaped dollar before reference", in: "$$$(Z)", want: "$$$$(Z)"},
+		{name: "trailing dollar", in: "cost $", want: "cost $"},
+		{name: "several", in: "a$(X) $$(Y) $$$(Z) $", want: "a$$(X) $$(Y) $$$$(Z) $"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := escapeEnvRefs(tc.in)
+			if got != tc.want {
+				t.Errorf("escapeEnvRefs(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+			if again := escapeEnvRefs(got); again != got {
+				t.Errorf("escapeEnvRefs is not idempotent: %q becomes %q", got, again)
+			}
+		})
+	}
+}
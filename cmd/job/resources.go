@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// resourceOverridesAnnotation enables resource override inputs on the CronJob.
+	// The value is "true" for all fields or a comma-separated list of fields, e.g. "requests.memory,limits.memory".
+	resourceOverridesAnnotation = "botkubeResourceOverrides"
+	// resourceContainerAnnotation names the container whose resources are overridden. Defaults to the first container.
+	resourceContainerAnnotation = "botkubeResourceContainer"
+	// overrideResources sets a container resource: resources:[<container>/]<requests|limits>.<cpu|memory>=<quantity>
+	overrideResources = "resources"
+	// argTargetResources makes the arg value a resource request or limit of a job container.
+	argTargetResources = "resources"
+	// argTypeQuantity is a plaintext input holding a Kubernetes quantity, e.g. 500m or 2Gi.
+	argTypeQuantity = "quantity"
+	resourcesGroup  = "Resources"
+)
+
+// resourceFields lists the resource fields that can be overridden, with their input labels.
+var resourceFields = []struct {
+	Field       string
+	Description string
+}{
+	{"requests.cpu", "CPU request"},
+	{"requests.memory", "Memory request"},
+	{"limits.cpu", "CPU limit"},
+	{"limits.memory", "Memory limit"},
+}
+
+// resourceArgs returns optional inputs for the resource fields enabled with the CronJob annotations.
+func resourceArgs(annotations map[string]string) []Arg {
+	enabled := strings.TrimSpace(annotations[resourceOverridesAnnotation])
+	if enabled == "" || enabled == "false" {
+		return nil
+	}
+	fields := splitList(enabled)
+
+	var args []Arg
+	for _, rf := range resourceFields {
+		if enabled != "true" && !slices.Contains(fields, rf.Field) {
+			continue
+		}
+		args = append(args, Arg{
+			Flag:        rf.Field,
+			Description: rf.Description,
+			Type:        argTypeQuantity,
+			Target:      argTargetResources,
+			Container:   annotations[resourceContainerAnnotation],
+			Group:       resourcesGroup,
+			Optional:    true,
+		})
+	}
+	return args
+}
+
+func validateQuantity(arg Arg, value string) error {
+	if _, err := resource.ParseQuantity(value); err != nil {
+		return fmt.Errorf("%s: %q is not a valid quantity, e.g. 500m or 2Gi", arg.Description, value)
+	}
+	return nil
+}
+
+// resourceOverride returns the run override for the arg with the resources target.
+func resourceOverride(arg Arg, value string) string {
+	return fmt.Sprintf("%s:%s=%s", overrideResources, resourceKey(arg), value)
+}
+
+// resourceKey returns the key of the resources override of the arg, [<container>/]<field>.
+func resourceKey(arg Arg) string {
+	if arg.Container != "" {
+		return fmt.Sprintf("%s/%s", arg.Container, arg.Flag)
+	}
+	return arg.Flag
+}
+
+// applyResource sets the resource field, e.g. limits.memory, on the named container or the first one.
+func applyResource(job map[string]interface{}, key, value string) error {
+	containerName, field, found := strings.Cut(key, "/")
+	if !found {
+		containerName, field = "", key
+	}
+	kind, name, found := strings.Cut(field, ".")
+	if !found || (kind != "requests" && kind != "limits") {
+		return fmt.Errorf("invalid resource field %q, expected requests.<name> or limits.<name>", field)
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return fmt.Errorf("invalid quantity %q for %s: %v", value, field, err)
+	}
+
+	for _, container := range jobContainers(job) {
+		if containerName != "" && container["name"] != containerName {
+			continue
+		}
+		resources, _ := container["resources"].(map[string]interface{})
+		if resources == nil {
+			resources = map[string]interface{}{}
+			container["resources"] = resources
+		}
+		values, _ := resources[kind].(map[string]interface{})
+		if values == nil {
+			values = map[string]interface{}{}
+			resources[kind] = values
+		}
+		values[name] = quantity.String()
+		return nil
+	}
+	return fmt.Errorf("container %q not found in the job", containerName)
+}
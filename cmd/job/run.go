@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+const (
+	// runArgsSeparator separates run overrides from container args in the run command.
+	runArgsSeparator = "--"
+	// overrideEnv sets an env var on all containers: env:<ENV>=<value>
+	overrideEnv = "env"
+	// argTargetEnv makes the arg value an env var instead of a container arg.
+	argTargetEnv = "env"
+)
+
+// runOverride modifies the generated Job besides container args. It is written as <kind>:<key>=<value>.
+type runOverride struct {
+	Kind  string
+	Key   string
+	Value string
+}
+
+func parseRunOverride(in string) (runOverride, error) {
+	kind, rest, found := strings.Cut(in, ":")
+	if !found {
+		return runOverride{}, fmt.Errorf("invalid override %q, expected <kind>:<key>=<value>", in)
+	}
+	key, value, found := strings.Cut(rest, "=")
+	if !found {
+		return runOverride{}, fmt.Errorf("invalid override %q, expected <kind>:<key>=<value>", in)
+	}
+	return runOverride{Kind: kind, Key: key, Value: value}, nil
+}
+
+// runRequest holds the details of a job launch.
+type runRequest struct {
+	// Kind is CronJob or Job, for runs from a Job template. Restarts waiting for an approval are of kind Deployment.
+	Kind      string
+	Name      string
+	Namespace string
+	Args      []string
+	Overrides []runOverride
+	// Command is the plugin command used to launch the job, so it can be run again.
+	Command   string
+	Requester executor.User
+	// Channel is the Slack channel ID the run was requested from.
+	Channel  string
+	Approver executor.User
+	// Cluster is the configured cluster the job runs on.
+	Cluster string
+	// Wait is how long the run waits for the job to finish before replying. Zero replies right after creating the job.
+	Wait time.Duration
+	// MatrixIndex numbers the runs of a matrix from 1, so their jobs get different names. It is 0 for other runs.
+	MatrixIndex int
+}
+
+// parseRunRequest parses "<cronjob>|job/<template> <namespace> [<override>... --] [args...]".
+// Arg values may be shell-quoted, see splitArgs.
+func parseRunRequest(value string, scope jobScope) (runRequest, error) {
+	fields, err := splitArgs(value)
+	if err != nil {
+		return runRequest{}, err
+	}
+	if len(fields) < 2 {
+		return runRequest{}, fmt.Errorf("usage: %s run <cronjob>|job/<template> <namespace> [<override>... %s] [args...]", pluginName, runArgsSeparator)
+	}
+
+	req := runRequest{
+		Kind:      kindCronJob,
+		Name:      fields[0],
+		Namespace: fields[1],
+		Args:      fields[2:],
+		Command:   withClusterFlag(fmt.Sprintf("%s run %s", pluginName, value), scope.Cluster),
+		Cluster:   scope.Cluster,
+		Requester: scope.User,
+		Channel:   scope.Channel,
+	}
+	if name, found := strings.CutPrefix(req.Name, jobTemplatePrefix); found {
+		req.Kind, req.Name = kindJob, name
+	}
+	if idx := slices.Index(req.Args, runArgsSeparator); idx >= 0 {
+		for _, raw := range req.Args[:idx] {
+			override, err := parseRunOverride(raw)
+			if err != nil {
+				return runRequest{}, err
+			}
+			req.Overrides = append(req.Overrides, override)
+		}
+		req.Args = req.Args[idx+1:]
+	}
+	return req, nil
+}
+
+// runJob checks if the user can run the job and launches it, or requests an approval first.
+// Unless force is set, the run is stopped when another run of the same job is still active.
+func runJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, in executor.ExecuteInput, value string, force bool) executor.ExecuteOutput {
+	req, err := parseRunRequest(value, scope)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	if isFanOut(value) || hasMatrix(req) {
+		return runFanOut(ctx, envs, cfg, scope, in, value, force)
+	}
+
+	source, err := authorizeRun(ctx, envs, cfg, scope, req)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	req = withLimitDefaults(req, source.Metadata.Annotations)
+
+	if !force && source.Metadata.Annotations[allowConcurrentAnnotation] != "true" {
+		active, err := activeRuns(ctx, envs, req)
+		if err != nil {
+			return executor.ExecuteOutput{
+				Message: api.NewCodeBlockMessage(err.Error(), true),
+			}
+		}
+		if len(active) > 0 {
+			return duplicateRunMessage(req, active, value)
+		}
+	}
+
+	if source.Metadata.Annotations[requireApprovalAnnotation] == "true" {
+		return requestApproval(ctx, cfg, in.Context.Message, req)
+	}
+
+	req.Wait, err = runWait(cfg, req, source)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	return launchJob(ctx, envs, cfg, in.Context.KubeConfig, in.Context.Message, req)
+}
+
+// authorizeRun checks that the job is allowed in the channel, the user can run it and the overrides and args
+// are valid args of the job. It returns the job source metadata.
+func authorizeRun(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, req runRequest) (CronJobs, error) {
+	if !cfg.isJobAllowed(scope, req.Namespace, req.Name) {
+		return CronJobs{}, fmt.Errorf("job %s/%s is not allowed in this channel", req.Namespace, req.Name)
+	}
+	source, err := getWorkload(ctx, envs, sourceResource(req.Kind), req.Namespace, req.Name)
+	if err == nil && req.Kind == kindJob && source.Metadata.Annotations[jobTemplateAnnotation] != "true" && !hasJobDefinition(ctx, envs, cfg, kindJob, req.Namespace, req.Name) {
+		err = fmt.Errorf("job %s is not a template", req.Name)
+	}
+	if err != nil || !isUserAllowed(ctx, cfg, scope, source.Metadata.Annotations) {
+		return CronJobs{}, fmt.Errorf("you are not authorized to run job %s/%s", req.Namespace, req.Name)
+	}
+	// typed commands can carry any override, they are limited to the args of the job as in the form
+	job, err := findRunJob(ctx, envs, cfg, scope, req)
+	if err != nil {
+		return CronJobs{}, err
+	}
+	if err := checkRunOverrides(ctx, envs, job, req); err != nil {
+		return CronJobs{}, err
+	}
+	if err := checkRunArgs(ctx, envs, job, req); err != nil {
+		return CronJobs{}, err
+	}
+	return source, nil
+}
+
+// launchJob creates the job from the CronJob template with the requested args.
+// Follow-up messages are posted in the context of the origin message.
+func launchJob(ctx context.Context, envs map[string]string, cfg Config, kubeConfig []byte, origin executor.Message, req runRequest) executor.ExecuteOutput {
+	jobName, err := createJob(ctx, envs, cfg, kubeConfig, origin, req)
+	var violations policyViolations
+	if errors.As(err, &violations) {
+		return executor.ExecuteOutput{
+			Message: policyViolationsMessage(violations),
+		}
+	}
+	if err != nil {
+		return errorOutput(err, req.Command)
+	}
+	recordRecentJob(req)
+	if req.Wait > 0 {
+		return waitForCompletion(ctx, envs, cfg, kubeConfig, origin, req, jobName)
+	}
+	return executor.ExecuteOutput{
+		Message: cfg.withResultVisibility(req.Channel, jobStartedMessage(cfg, req, jobName)),
+	}
+}
+
+// createJob creates the rendered job and starts watching it, unless the run waits for completion. It returns the name of the created job.
+func createJob(ctx context.Context, envs map[string]string, cfg Config, kubeConfig []byte, origin executor.Message, req runRequest) (string, error) {
+	jobName, err := renderJobName(cfg, req, time.Now())
+	if err != nil {
+		return "", err
+	}
+	job, _, err := renderJob(ctx, envs, cfg, req, jobName)
+	if err != nil {
+		return "", err
+	}
+	manifest, err := json.Marshal(job)
+	if err != nil {
+		return "", fmt.Errorf("while marshalling job %s: %w", jobName, err)
+	}
+
+	// Policies are checked before creating, so violations are reported instead of a failed create
+	if cfg.Policy.Check {
+		if err := serverDryRun(ctx, envs, req.Name, manifest); err != nil {
+			return "", fmt.Errorf("while checking job %s against cluster policies: %w", jobName, err)
+		}
+	}
+
+	// The manifest is passed on stdin, so no file is left behind if the plugin stops.
+	// Unlike apply, create fails instead of changing a job that already has the name.
+	createCmd := "kubectl create -f -"
+	if _, err := kubectlStdin(ctx, envs, createCmd, manifest); err != nil {
+		return "", fmt.Errorf("while creating job %s: %w", jobName, err)
+	}
+	recordRun(ctx, envs, cfg, req, jobName, time.Now())
+	notifyWebhook(cfg, req, jobName, time.Now())
+	announceRun(ctx, cfg, req, jobName)
+	// waiting runs report the result in the reply and start the watch only if the wait times out
+	if req.Wait == 0 {
+		startWatch(cfg, kubeConfig, origin, req, jobName)
+	}
+	return jobName, nil
+}
+
+// renderJob returns the Job manifest generated from the CronJob or Job template with the requested args and overrides,
+// together with the manifest before they were applied.
+func renderJob(ctx context.Context, envs map[string]string, cfg Config, req runRequest, jobName string) (job, base map[string]interface{}, err error) {
+	var cronJob map[string]interface{}
+	if req.Kind == kindJob {
+		cronJob, err = jobFromTemplate(ctx, envs, req.Namespace, req.Name, jobName)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		runCmd := fmt.Sprintf("kubectl create job --from=cronjob/%s -n %s %s --dry-run=client -ojson", req.Name, req.Namespace, jobName)
+		out, err := kubectl(ctx, envs, runCmd)
+		if err != nil {
+			return nil, nil, fmt.Errorf("while generating job from cronjob %s: %w", req.Name, err)
+		}
+		if err := json.Unmarshal([]byte(out.Stdout), &cronJob); err != nil {
+			return nil, nil, fmt.Errorf("while generating job from cronjob %s: %w", req.Name, err)
+		}
+		if err := ensureCronJobOwner(ctx, envs, cronJob, req); err != nil {
+			return nil, nil, err
+		}
+	}
+	base, err = copyManifest(cronJob)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata, ok := cronJob["metadata"].(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("generated job %s has no metadata", jobName)
+	}
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+		metadata["annotations"] = annotations
+	}
+	annotations[botkubeAnnotation] = "true"
+	annotations[requesterAnnotation] = requesterName(req.Requester)
+	annotations[runCommandAnnotation] = req.Command
+	if req.Approver != (executor.User{}) {
+		annotations[approverAnnotation] = requesterName(req.Approver)
+	}
+	if err := stampJob(cronJob, req, time.Now()); err != nil {
+		return nil, nil, err
+	}
+	containers := jobContainers(cronJob)
+	if len(containers) == 0 {
+		return nil, nil, fmt.Errorf("generated job %s has no containers", jobName)
+	}
+	if cfg.Cleanup.TTLAfterFinished > 0 {
+		cronJob["spec"].(map[string]interface{})["ttlSecondsAfterFinished"] = int64(cfg.Cleanup.TTLAfterFinished.Seconds())
+	}
+
+	// Modify the first container args
+	containers[0]["args"] = containerArgs(req)
+	if err := applyOverrides(cronJob, req.Overrides); err != nil {
+		return nil, nil, err
+	}
+	if cfg.RequesterEnv {
+		setRequesterEnv(cronJob, req)
+	}
+	return cronJob, base, nil
+}
+
+// applyOverrides applies run overrides to the generated Job.
+func applyOverrides(job map[string]interface{}, overrides []runOverride) error {
+	for _, override := range overrides {
+		switch override.Kind {
+		case overrideSecretEnv:
+			envVar, err := secretEnvVar(override.Key, override.Value)
+			if err != nil {
+				return err
+			}
+			for _, container := range jobContainers(job) {
+				setContainerEnv(container, envVar)
+			}
+		case overrideEnv:
+			envVar := map[string]interface{}{
+				"name":  override.Key,
+				"value": override.Value,
+			}
+			for _, container := range jobContainers(job) {
+				setContainerEnv(container, envVar)
+			}
+		case overrideImage:
+			if err := applyImageTag(job, override.Key, override.Value); err != nil {
+				return err
+			}
+		case overrideResources:
+			if err := applyResource(job, override.Key, override.Value); err != nil {
+				return err
+			}
+		case overrideLimit:
+			if err := applyLimit(job, override.Key, override.Value); err != nil {
+				return err
+			}
+		case overrideWait:
+			// not part of the manifest, see runWait
+		case overrideMatrix:
+			return fmt.Errorf("runs with several values of %s create one job per value and cannot be previewed or scheduled", override.Key)
+		default:
+			return fmt.Errorf("unknown override %q", override.Kind)
+		}
+	}
+	return nil
+}
+
+// jobContainers returns the containers of the Job pod template.
+func jobContainers(job map[string]interface{}) []map[string]interface{} {
+	spec, _ := job["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	items, _ := podSpec["containers"].([]interface{})
+
+	var containers []map[string]interface{}
+	for _, item := range items {
+		if container, ok := item.(map[string]interface{}); ok {
+			containers = append(containers, container)
+		}
+	}
+	return containers
+}
+
+// setContainerEnv adds the env var to the container, replacing an existing one with the same name.
+func setContainerEnv(container map[string]interface{}, envVar map[string]interface{}) {
+	env, _ := container["env"].([]interface{})
+	for i, item := range env {
+		if existing, ok := item.(map[string]interface{}); ok && existing["name"] == envVar["name"] {
+			env[i] = envVar
+			return
+		}
+	}
+	container["env"] = append(env, envVar)
+}
+
+// jobStartedMessage confirms the job launch and allows to cancel it, with links to the configured dashboards.
+func jobStartedMessage(cfg Config, req runRequest, name string) api.Message {
+	namespace := req.Namespace
+	btnBuilder := api.NewMessageButtonBuilder()
+	return api.Message{
+		Sections: []api.Section{
+			{
+				Base: api.Base{
+					Body: api.Body{
+						CodeBlock: fmt.Sprintf("Job %s is started", name),
+					},
+				},
+				Buttons: append([]api.Button{
+					btnBuilder.ForCommandWithoutDesc("Status", fmt.Sprintf("%s status %s %s", pluginName, name, namespace)),
+					btnBuilder.ForCommandWithoutDesc("Cancel", fmt.Sprintf("%s delete %s %s", pluginName, name, namespace), api.ButtonStyleDanger),
+				}, dashboardButtons(cfg, req, name, time.Now())...),
+			},
+		},
+		OnlyVisibleForYou: true,
+	}
+}
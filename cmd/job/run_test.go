@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+func TestParseRunRequest(t *testing.T) {
+	scope := jobScope{Channel: "C0123456", User: executor.User{Mention: "<@U0123456>"}}
+	tests := []struct {
+		name    string
+		value   string
+		scope   jobScope
+		want    runRequest
+		wantErr bool
+	}{
+		{
+			name:  "cronjob without args",
+			value: "backup team-a",
+			scope: scope,
+			want: runRequest{
+				Kind:      kindCronJob,
+				Name:      "backup",
+				Namespace: "team-a",
+				Args:      []string{},
+				Command:   "job run backup team-a",
+				Requester: scope.User,
+				Channel:   "C0123456",
+			},
+		},
+		{
+			name:  "job template with quoted args",
+			value: "job/migrate apps --msg 'hello world' --dry-run",
+			scope: scope,
+			want: runRequest{
+				Kind:      kindJob,
+				Name:      "migrate",
+				Namespace: "apps",
+				Args:      []string{"--msg", "hello world", "--dry-run"},
+				Command:   "job run job/migrate apps --msg 'hello world' --dry-run",
+				Requester: scope.User,
+				Channel:   "C0123456",
+			},
+		},
+		{
+			name:  "overrides before the separator",
+			value: "backup team-a secret-env:TOKEN=api/token image:app=v2 -- --token $(TOKEN)",
+			want: runRequest{
+				Kind:      kindCronJob,
+				Name:      "backup",
+				Namespace: "team-a",
+				Args:      []string{"--token", "$(TOKEN)"},
+				Overrides: []runOverride{
+					{Kind: overrideSecretEnv, Key: "TOKEN", Value: "api/token"},
+					{Kind: overrideImage, Key: "app", Value: "v2"},
+				},
+				Command: "job run backup team-a secret-env:TOKEN=api/token image:app=v2 -- --token $(TOKEN)",
+			},
+		},
+		{
+			name:  "override value with an equal sign",
+			value: "backup team-a env:FILTER=a=b --",
+			want: runRequest{
+				Kind:      kindCronJob,
+				Name:      "backup",
+				Namespace: "team-a",
+				Args:      []string{},
+				Overrides: []runOverride{{Kind: overrideEnv, Key: "FILTER", Value: "a=b"}},
+				Command:   "job run backup team-a env:FILTER=a=b --",
+			},
+		},
+		{
+			name:  "cluster",
+			value: "backup team-a",
+			scope: jobScope{Cluster: "prod"},
+			want: runRequest{
+				Kind:      kindCronJob,
+				Name:      "backup",
+				Namespace: "team-a",
+				Args:      []string{},
+				Command:   withClusterFlag("job run backup team-a", "prod"),
+				Cluster:   "prod",
+			},
+		},
+		{name: "missing namespace", value: "backup", wantErr: true},
+		{name: "override without kind", value: "backup team-a TOKEN=x --", wantErr: true},
+		{name: "override without value", value: "backup team-a env:TOKEN --", wantErr: true},
+		{name: "unterminated quote", value: "backup team-a --msg 'hello", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseRunRequest(tc.value, tc.scope)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseRunRequest(%q) error = %v, wantErr %v", tc.value, err, tc.wantErr)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseRunRequest(%q) = %+v, want %+v", tc.value, got, tc.want)
+			}
+		})
+	}
+}
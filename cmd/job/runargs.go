@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Run commands are typed, replayed from history or Run again buttons, not only built by the form, so a run
+// is checked against the args of the job again before anything is created: each override and container arg
+// must belong to an arg of the job, with a value the form accepts.
+
+// findRunJob returns the discovered job of the request, with the args definition runs are checked against.
+func findRunJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, req runRequest) (Job, error) {
+	jobs, err := cachedJobs(ctx, envs, cfg, scope.Cluster)
+	for _, job := range jobs {
+		if job.Kind != req.Kind || job.Namespace != req.Namespace || job.Name != req.Name {
+			continue
+		}
+		if len(job.Issues) > 0 {
+			return Job{}, fmt.Errorf("job %s has an invalid definition, see `%s doctor`", req.Name, pluginName)
+		}
+		return job, nil
+	}
+	if err != nil {
+		return Job{}, fmt.Errorf("while listing jobs: %w", err)
+	}
+	return Job{}, fmt.Errorf("job %s/%s has no args definition and cannot be run", req.Namespace, req.Name)
+}
+
+// checkRunOverrides returns an error if an override doesn't belong to an arg of the job or has a value the form
+// doesn't accept, e.g. an image tag that is not offered, or a secret-env override references another Secret
+// or key than the ones its secret arg offers.
+func checkRunOverrides(ctx context.Context, envs map[string]string, job Job, req runRequest) error {
+	for _, override := range req.Overrides {
+		// the wait is capped with watch.maxWait, see runWait
+		if override.Kind == overrideWait {
+			continue
+		}
+		arg, ok := overrideArg(job.Args, override)
+		if !ok {
+			return fmt.Errorf("override %s:%s is not a parameter of job %s", override.Kind, override.Key, job.Name)
+		}
+		if override.Kind == overrideSecretEnv {
+			if err := checkSecretRef(ctx, envs, job.Namespace, arg, override.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := checkArgValue(ctx, envs, job.Namespace, arg, override.Value); err != nil {
+			return err
+		}
+	}
+	return checkSecretArgRefs(job, req)
+}
+
+// checkRunArgs returns an error if a container arg is neither a flag of the job nor the value of a positional arg,
+// or a value is not accepted by the form, e.g. a number out of its range. Values are checked as the container
+// gets them, after Kubernetes unescapes $$.
+func checkRunArgs(ctx context.Context, envs map[string]string, job Job, req runRequest) error {
+	var positional []Arg
+	for _, arg := range job.Args {
+		if isContainerArg(arg) && arg.Flag == "" && arg.Position != nil {
+			positional = append(positional, arg)
+		}
+	}
+	slices.SortStableFunc(positional, func(a, b Arg) int { return *a.Position - *b.Position })
+
+	for i := 0; i < len(req.Args); i++ {
+		value := req.Args[i]
+		idx := slices.IndexFunc(job.Args, func(arg Arg) bool {
+			return arg.Flag == value && (isContainerArg(arg) || arg.Type == argTypeSecret)
+		})
+		if idx < 0 {
+			if len(positional) == 0 || strings.HasPrefix(value, "-") {
+				return fmt.Errorf("unknown parameter %q of job %s", value, job.Name)
+			}
+			arg := positional[0]
+			positional = positional[1:]
+			if err := checkArgValue(ctx, envs, job.Namespace, arg, containerValue(value)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		arg := job.Args[idx]
+		if arg.Type == "bool" {
+			continue
+		}
+		if i+1 >= len(req.Args) {
+			return fmt.Errorf("%s: missing value", arg.Description)
+		}
+		i++
+		value = req.Args[i]
+		if arg.Type == argTypeSecret {
+			// the key is chosen with the secret-env override, see checkSecretArgRefs
+			if value != fmt.Sprintf("$(%s)", secretEnvName(arg)) {
+				return fmt.Errorf("%s: the value is the chosen key of secret %s", arg.Description, arg.Secret)
+			}
+			continue
+		}
+		if err := checkArgValue(ctx, envs, job.Namespace, arg, containerValue(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isContainerArg returns true if the value of the arg is passed in the container args.
+func isContainerArg(arg Arg) bool {
+	return arg.Type != argTypeSecret && (arg.Target == "" || arg.Target == "args")
+}
+
+// containerValue returns the value the container gets for the arg value, see containerArgs.
+func containerValue(value string) string {
+	return strings.ReplaceAll(escapeEnvRefs(value), "$$", "$")
+}
+
+// overrideArg returns the arg of the job the override is generated from, see buildFinalCommand.
+func overrideArg(args []Arg, override runOverride) (Arg, bool) {
+	idx := slices.IndexFunc(args, func(arg Arg) bool {
+		switch override.Kind {
+		case overrideSecretEnv:
+			return arg.Type == argTypeSecret && secretEnvName(arg) == override.Key
+		case overrideEnv:
+			return arg.Type != argTypeSecret && arg.Target == argTargetEnv && argEnvName(arg) == override.Key
+		case overrideImage:
+			return arg.Target == argTargetImage && arg.Container == override.Key
+		case overrideResources:
+			return arg.Target == argTargetResources && resourceKey(arg) == override.Key
+		case overrideLimit:
+			return arg.Target == argTargetLimit && arg.Flag == override.Key
+		case overrideMatrix:
+			return arg.Multiple && arg.Flag == override.Key
+		}
+		return false
+	})
+	if idx < 0 {
+		return Arg{}, false
+	}
+	return args[idx], true
+}
+
+// checkArgValue returns an error unless the value is accepted by the form: one of the offered values of a select,
+// each of them for args accepting several values, or an input passing the validation of the arg.
+func checkArgValue(ctx context.Context, envs map[string]string, namespace string, arg Arg, value string) error {
+	if !isSelectArg(arg) {
+		return validateArg(arg, value)
+	}
+	items := []string{value}
+	if arg.Multiple {
+		items = listItems(value)
+	}
+	values := optionValues(ctx, envs, namespace, arg)
+	for _, item := range items {
+		if !slices.Contains(values, item) {
+			return fmt.Errorf("%s: %q is not one of the offered values", arg.Description, item)
+		}
+	}
+	return nil
+}
+
+// checkSecretRef returns an error unless the <secret>/<key> reference is a key of the Secret of the arg.
+func checkSecretRef(ctx context.Context, envs map[string]string, namespace string, arg Arg, ref string) error {
+	secret, key, found := strings.Cut(ref, "/")
+	if !found || secret != arg.Secret {
+		return fmt.Errorf("%s: only keys of secret %s can be used", arg.Description, arg.Secret)
+	}
+	keys, err := getSecretKeys(ctx, envs, namespace, arg.Secret)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(keys, key) {
+		return fmt.Errorf("%s: secret %s has no key %q", arg.Description, arg.Secret, key)
+	}
+	return nil
+}
+
+// checkSecretArgRefs returns an error if the $(ENV) reference of an injected Secret key is used in the container
+// args other than as the value of the flag of its secret arg, as the value would be expanded into any arg.
+func checkSecretArgRefs(job Job, req runRequest) error {
+	refs := secretArgRefs(req)
+	for i, value := range req.Args {
+		env, ok := refs[value]
+		if !ok {
+			continue
+		}
+		idx := slices.IndexFunc(job.Args, func(arg Arg) bool {
+			return arg.Type == argTypeSecret && secretEnvName(arg) == env
+		})
+		if idx < 0 || job.Args[idx].Flag == "" || i == 0 || req.Args[i-1] != job.Args[idx].Flag {
+			return fmt.Errorf("secret %s can only be passed with the flag of its parameter", env)
+		}
+	}
+	return nil
+}
+
+// secretArgRefs returns the env names of the secret-env overrides of the request by their $(ENV) reference.
+func secretArgRefs(req runRequest) map[string]string {
+	refs := map[string]string{}
+	for _, override := range req.Overrides {
+		if override.Kind == overrideSecretEnv {
+			refs[fmt.Sprintf("$(%s)", override.Key)] = override.Key
+		}
+	}
+	return refs
+}
+
+// containerArgs returns the container args of the request with $(VAR) references escaped, except the references
+// of the Secret keys injected with secret-env overrides, which Kubernetes expands.
+func containerArgs(req runRequest) []string {
+	refs := secretArgRefs(req)
+	args := make([]string, 0, len(req.Args))
+	for _, value := range req.Args {
+		if _, ok := refs[value]; !ok {
+			value = escapeEnvRefs(value)
+		}
+		args = append(args, value)
+	}
+	return args
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+const testJobArgs = `[
+	{"flag": "--count", "description": "Count", "type": "int", "min": 1, "max": 5},
+	{"flag": "--env", "description": "Environment", "type": "dropdown", "values": ["dev", "prod"]},
+	{"flag": "--name", "description": "Name", "type": "text", "pattern": "[a-z-]+"},
+	{"flag": "--dry-run", "description": "Dry run", "type": "bool"},
+	{"flag": "--token", "description": "Token", "type": "secret", "secret": "api"}
+]`
+
+func TestAuthorizeRun(t *testing.T) {
+	annotations := map[string]string{
+		"botkubeJobArgs":       testJobArgs,
+		allowedUsersAnnotation: "U0123456",
+	}
+	fakeKubectl(t, map[string]string{
+		"get cronjobs -A -ojson":              `{"items": [` + fakeWorkload(t, "team-a", "backup", annotations) + `]}`,
+		"get cronjob -n team-a backup -ojson": fakeWorkload(t, "team-a", "backup", annotations),
+		"get secret -n team-a api -ojson":     `{"data": {"token": "dG9rZW4="}}`,
+	})
+	allowed := jobScope{Channel: "C0123456", User: executor.User{Mention: "<@U0123456>"}, memberOf: map[string]bool{}}
+
+	tests := []struct {
+		name    string
+		value   string
+		scope   jobScope
+		cfg     func(*Config)
+		wantErr bool
+	}{
+		{name: "valid args", value: "backup team-a --count 3 --env prod --name app-a --dry-run"},
+		{name: "no args", value: "backup team-a"},
+		{name: "secret key", value: "backup team-a secret-env:BOTKUBE_SECRET_TOKEN=api/token -- --token $(BOTKUBE_SECRET_TOKEN)"},
+		{name: "other user", value: "backup team-a", scope: jobScope{User: executor.User{Mention: "<@U0999999>", DisplayName: "U0123456"}}, wantErr: true},
+		{name: "other channel", value: "backup team-a", cfg: func(cfg *Config) {
+			cfg.Channels = map[string]ChannelConfig{"C0999999": {Jobs: []string{"backup"}}}
+		}, wantErr: true},
+		{name: "unknown job", value: "restore team-a", wantErr: true},
+		{name: "count above max", value: "backup team-a --count 6", wantErr: true},
+		{name: "count NaN", value: "backup team-a --count NaN", wantErr: true},
+		{name: "value not offered", value: "backup team-a --env staging", wantErr: true},
+		{name: "partial pattern match", value: "backup team-a --name 'app; rm'", wantErr: true},
+		{name: "unknown flag", value: "backup team-a --privileged", wantErr: true},
+		{name: "missing value", value: "backup team-a --count", wantErr: true},
+		{name: "extra positional value", value: "backup team-a --dry-run yes", wantErr: true},
+		{name: "other secret", value: "backup team-a secret-env:BOTKUBE_SECRET_TOKEN=admin/password -- --token $(BOTKUBE_SECRET_TOKEN)", wantErr: true},
+		{name: "missing secret key", value: "backup team-a secret-env:BOTKUBE_SECRET_TOKEN=api/password -- --token $(BOTKUBE_SECRET_TOKEN)", wantErr: true},
+		{name: "secret in another flag", value: "backup team-a secret-env:BOTKUBE_SECRET_TOKEN=api/token -- --name $(BOTKUBE_SECRET_TOKEN)", wantErr: true},
+		{name: "undeclared env override", value: "backup team-a env:LD_PRELOAD=/tmp/x.so --", wantErr: true},
+		{name: "undeclared image override", value: "backup team-a image:app=evil --", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scope := allowed
+			if tc.scope.User.Mention != "" {
+				scope = tc.scope
+				scope.memberOf = map[string]bool{}
+			}
+			cfg := testConfig()
+			if tc.cfg != nil {
+				tc.cfg(&cfg)
+			}
+			req, err := parseRunRequest(tc.value, scope)
+			if err != nil {
+				t.Fatalf("parseRunRequest(%q) failed: %v", tc.value, err)
+			}
+			_, err = authorizeRun(context.Background(), nil, cfg, scope, req)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("authorizeRun(%q) error = %v, wantErr %v", tc.value, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckRunArgsPositional(t *testing.T) {
+	first, second := 0, 1
+	job := Job{
+		Name: "migrate",
+		Args: []Arg{
+			{Description: "Source", Type: argTypeText, Pattern: "[a-z]+", Position: &first},
+			{Description: "Target", Type: argTypeText, Pattern: "[a-z]+", Position: &second},
+			{Flag: "--verbose", Description: "Verbose", Type: "bool"},
+		},
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "positional values", args: []string{"app", "--verbose", "db"}},
+		{name: "escaped reference", args: []string{"$(HOME)"}, wantErr: true},
+		{name: "invalid positional value", args: []string{"app", "db1"}, wantErr: true},
+		{name: "too many values", args: []string{"app", "db", "cache"}, wantErr: true},
+		{name: "unknown flag", args: []string{"--force"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkRunArgs(context.Background(), nil, job, runRequest{Args: tc.args})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("checkRunArgs(%q) error = %v, wantErr %v", tc.args, err, tc.wantErr)
+			}
+		})
+	}
+}
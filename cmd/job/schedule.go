@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	"github.com/kubeshop/botkube/pkg/plugin"
+)
+
+// runNow is the delay select option running the job immediately.
+const runNow = "now"
+
+// delayOptions are offered by the "Run at" select of the job form.
+var delayOptions = []string{runNow, "15m", "30m", "1h", "2h", "4h", "8h", "24h"}
+
+// scheduledRun is a job launch waiting for its start time.
+type scheduledRun struct {
+	At      time.Time
+	Request runRequest
+	timer   *time.Timer
+}
+
+// schedules holds pending scheduled runs. They are kept in memory, so they don't survive plugin restarts.
+var schedules = struct {
+	sync.Mutex
+	pending map[string]*scheduledRun
+}{
+	pending: map[string]*scheduledRun{},
+}
+
+// parseRunAt parses a delay, e.g. 30m, or an RFC 3339 time, e.g. 2024-01-02T15:04:05Z.
+func parseRunAt(value string, now time.Time) (time.Time, error) {
+	if delay, err := time.ParseDuration(value); err == nil {
+		return now.Add(delay), nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid start time %q, expected a delay like 30m or an RFC 3339 time", value)
+	}
+	return at, nil
+}
+
+// scheduleRun delays the job launch. Usage: job schedule <delay|time> <cronjob> <namespace> [...]
+// The launch runs outside of the Execute call, so it uses its own copy of the kubeconfig.
+func scheduleRun(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, in executor.ExecuteInput, value string) executor.ExecuteOutput {
+	when, runValue, _ := strings.Cut(strings.TrimSpace(value), " ")
+	at, err := parseRunAt(when, time.Now())
+	if err == nil && !at.After(time.Now()) {
+		err = fmt.Errorf("start time %s is in the past", at.Format(time.RFC3339))
+	}
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	req, err := parseRunRequest(runValue, scope)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	source, err := authorizeRun(ctx, envs, cfg, scope, req)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	req = withLimitDefaults(req, source.Metadata.Annotations)
+	if source.Metadata.Annotations[requireApprovalAnnotation] == "true" {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Job %s requires approval and cannot be scheduled", req.Name), true),
+		}
+	}
+
+	id := uuid.New().String()
+	run := &scheduledRun{At: at, Request: req}
+	kubeConfig, origin := in.Context.KubeConfig, in.Context.Message
+	schedules.Lock()
+	run.timer = time.AfterFunc(time.Until(at), func() {
+		schedules.Lock()
+		delete(schedules.pending, id)
+		schedules.Unlock()
+		startScheduledRun(kubeConfig, cfg, scope, origin, req)
+	})
+	schedules.pending[id] = run
+	schedules.Unlock()
+
+	btnBuilder := api.NewMessageButtonBuilder()
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections: []api.Section{
+				{
+					Base: api.Base{
+						Body: api.Body{
+							CodeBlock: fmt.Sprintf("Job %s in namespace %s is scheduled to run at %s", req.Name, req.Namespace, at.UTC().Format(time.RFC1123)),
+						},
+					},
+					Buttons: []api.Button{
+						btnBuilder.ForCommandWithoutDesc("Cancel scheduled run", fmt.Sprintf("%s unschedule %s", pluginName, id), api.ButtonStyleDanger),
+					},
+				},
+			},
+			OnlyVisibleForYou: true,
+			ReplaceOriginal:   true,
+		},
+	}
+}
+
+// delaySelect renders the "Run at" select of the job form.
+func delaySelect(selected, cmd string) api.Select {
+	var options []api.OptionItem
+	for _, delay := range delayOptions {
+		options = append(options, api.OptionItem{Name: delay, Value: delay})
+	}
+	if selected == "" {
+		selected = runNow
+	}
+	return api.Select{
+		Name:    "Run at",
+		Command: cmd,
+		OptionGroups: []api.OptionGroup{
+			{
+				Name:    "Run at",
+				Options: options,
+			},
+		},
+		InitialOption: &api.OptionItem{Name: selected, Value: selected},
+	}
+}
+
+// unscheduleRun cancels a pending scheduled run, on behalf of the requester or a user who can run the job in the channel.
+func unscheduleRun(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, id string) executor.ExecuteOutput {
+	id = strings.TrimSpace(id)
+	schedules.Lock()
+	run, ok := schedules.pending[id]
+	schedules.Unlock()
+	if ok && userID(run.Request.Requester) != scope.UserID() && !canRunJob(ctx, envs, cfg, scope, run.Request) {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("You are not authorized to cancel runs of job %s", run.Request.Name), true),
+		}
+	}
+
+	schedules.Lock()
+	// the run may have started in the meantime
+	ok = ok && run.timer.Stop()
+	if ok {
+		delete(schedules.pending, id)
+	}
+	schedules.Unlock()
+
+	if !ok {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("Scheduled run not found, it has already started or was cancelled", true),
+		}
+	}
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			BaseBody: api.Body{
+				Plaintext: fmt.Sprintf("Scheduled run of job %s was cancelled by %s", run.Request.Name, scope.User.Mention),
+			},
+			ReplaceOriginal: true,
+		},
+	}
+}
+
+// canRunJob returns true if the job is allowed in the channel and the user can run it.
+func canRunJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, req runRequest) bool {
+	if !cfg.isJobAllowed(scope, req.Namespace, req.Name) {
+		return false
+	}
+	source, err := getWorkload(ctx, envs, sourceResource(req.Kind), req.Namespace, req.Name)
+	return err == nil && isUserAllowed(ctx, cfg, scope, source.Metadata.Annotations)
+}
+
+// startScheduledRun creates the job and reports the outcome in the context of the scheduling message.
+func startScheduledRun(kubeConfig []byte, cfg Config, scope jobScope, origin executor.Message, req runRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	kubeConfigPath, deleteFn, err := plugin.PersistKubeConfig(ctx, kubeConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write kubeconfig for scheduled job %s: %v", req.Name, err)
+		return
+	}
+	defer func() {
+		if deleteErr := deleteFn(context.Background()); deleteErr != nil {
+			fmt.Fprintf(os.Stderr, "failed to delete kubeconfig file %s: %v", kubeConfigPath, deleteErr)
+		}
+	}()
+	envs := map[string]string{
+		"KUBECONFIG": kubeConfigPath,
+	}
+
+	n, err := newNotifier(cfg, origin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot report scheduled run of job %s: %v", req.Name, err)
+	}
+	jobName, err := createScheduledJob(ctx, envs, cfg, scope, kubeConfig, origin, req)
+	if n == nil {
+		return
+	}
+	if err != nil {
+		if postErr := n.Post(ctx, fmt.Sprintf("Scheduled run of job *%s* failed: %v", req.Name, err)); postErr != nil {
+			fmt.Fprintf(os.Stderr, "failed to post result of scheduled job %s: %v", req.Name, postErr)
+		}
+		return
+	}
+	btnBuilder := api.NewMessageButtonBuilder()
+	status := btnBuilder.ForCommandWithoutDesc("Status", withClusterFlag(fmt.Sprintf("%s status %s %s", pluginName, jobName, req.Namespace), req.Cluster))
+	if err := n.Post(ctx, fmt.Sprintf("Scheduled run of job *%s* started as *%s*", req.Name, jobName), status); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to post result of scheduled job %s: %v", req.Name, err)
+	}
+}
+
+// createScheduledJob checks the run again, as the job, the user access and the active runs may have changed since
+// it was scheduled, and creates the job. It returns the name of the created job.
+func createScheduledJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, kubeConfig []byte, origin executor.Message, req runRequest) (string, error) {
+	// group membership is looked up again, it may have changed as well
+	scope.memberOf = map[string]bool{}
+	source, err := authorizeRun(ctx, envs, cfg, scope, req)
+	if err != nil {
+		return "", err
+	}
+	if source.Metadata.Annotations[requireApprovalAnnotation] == "true" {
+		return "", fmt.Errorf("job %s requires approval and cannot be scheduled", req.Name)
+	}
+	if source.Metadata.Annotations[allowConcurrentAnnotation] != "true" {
+		active, err := activeRuns(ctx, envs, req)
+		if err != nil {
+			return "", err
+		}
+		if len(active) > 0 {
+			return "", fmt.Errorf("job %s is already running: %s", req.Name, strings.Join(active, ", "))
+		}
+	}
+	return createJob(ctx, envs, cfg, kubeConfig, origin, req)
+}
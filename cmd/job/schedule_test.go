@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateScheduledJob(t *testing.T) {
+	active := batchv1.JobList{Items: []batchv1.Job{{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "team-a",
+			Name:              "backup-1",
+			CreationTimestamp: metav1.Now(),
+			Annotations:       map[string]string{botkubeAnnotation: "true"},
+		},
+	}}}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     string
+	}{
+		{name: "access revoked", annotations: map[string]string{allowedUsersAnnotation: "U0999999"}, wantErr: "not authorized"},
+		{name: "approval required", annotations: map[string]string{requireApprovalAnnotation: "true"}, wantErr: "requires approval"},
+		{name: "active run", wantErr: "already running: backup-1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.annotations == nil {
+				tc.annotations = map[string]string{}
+			}
+			tc.annotations["botkubeJobArgs"] = testJobArgs
+			calls := fakeKubectl(t, map[string]string{
+				"get cronjob -n team-a backup -ojson":            fakeWorkload(t, "team-a", "backup", tc.annotations),
+				"get cronjobs -A -ojson":                         `{"items": [` + fakeWorkload(t, "team-a", "backup", tc.annotations) + `]}`,
+				"get jobs -A -ojson -l botkube.io/source=backup": fakeJSON(t, active),
+			})
+			scope := jobScope{Channel: "C0123456", User: executor.User{Mention: "<@U0123456>"}}
+			req, err := parseRunRequest("backup team-a", scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = createScheduledJob(context.Background(), nil, testConfig(), scope, nil, executor.Message{}, req)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("createScheduledJob() error = %v, want %q", err, tc.wantErr)
+			}
+			if slices.ContainsFunc(calls(), func(call string) bool { return strings.HasPrefix(call, "create ") }) {
+				t.Errorf("scheduled job was created, kubectl calls: %q", calls())
+			}
+		})
+	}
+}
+
+func TestUnscheduleRun(t *testing.T) {
+	fakeKubectl(t, map[string]string{
+		"get cronjob -n team-a backup -ojson": fakeWorkload(t, "team-a", "backup", map[string]string{allowedUsersAnnotation: "U0123456,U0234567"}),
+	})
+	requester := executor.User{Mention: "<@U0123456>"}
+	schedule := func() string {
+		id := "run-" + strings.ReplaceAll(t.Name(), "/", "-")
+		schedules.Lock()
+		schedules.pending[id] = &scheduledRun{
+			Request: runRequest{Kind: kindCronJob, Name: "backup", Namespace: "team-a", Requester: requester},
+			timer:   time.AfterFunc(time.Hour, func() {}),
+		}
+		schedules.Unlock()
+		return id
+	}
+	pending := func(id string) bool {
+		schedules.Lock()
+		defer schedules.Unlock()
+		_, ok := schedules.pending[id]
+		return ok
+	}
+
+	tests := []struct {
+		name       string
+		user       string
+		wantCancel bool
+	}{
+		{name: "requester", user: "<@U0123456>", wantCancel: true},
+		{name: "allowed user", user: "<@U0234567>", wantCancel: true},
+		{name: "other user", user: "<@U0999999>"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			id := schedule()
+			scope := jobScope{Channel: "C0123456", User: executor.User{Mention: tc.user}, memberOf: map[string]bool{}}
+			unscheduleRun(context.Background(), nil, testConfig(), scope, id)
+			if cancelled := !pending(id); cancelled != tc.wantCancel {
+				t.Errorf("run cancelled = %v, want %v", cancelled, tc.wantCancel)
+			}
+			schedules.Lock()
+			if run, ok := schedules.pending[id]; ok {
+				run.timer.Stop()
+				delete(schedules.pending, id)
+			}
+			schedules.Unlock()
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// argsJSONSchema describes the botkubeJobArgs annotation.
+//
+//go:embed args_schema.json
+var argsJSONSchema string
+
+var argsSchemaLoader = gojsonschema.NewStringLoader(argsJSONSchema)
+
+// parseArgs validates the args definition against the schema and unmarshals it.
+// Issues point to the invalid fields, e.g. "2.type: must be one of ...", so they can be shown to the user.
+// An empty definition means the job takes no args.
+func parseArgs(raw string) ([]Arg, []string) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	result, err := gojsonschema.Validate(argsSchemaLoader, gojsonschema.NewStringLoader(raw))
+	if err != nil {
+		return nil, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+
+	var issues []string
+	for _, resErr := range result.Errors() {
+		issues = append(issues, fmt.Sprintf("%s: %s", resErr.Field(), resErr.Description()))
+	}
+	if len(issues) > 0 {
+		return nil, issues
+	}
+
+	var args []Arg
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		return nil, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	return args, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	argTypeSecret = "secret"
+	// overrideSecretEnv injects an env var from a Secret key: secret-env:<ENV>=<secret>/<key>
+	overrideSecretEnv = "secret-env"
+)
+
+// getSecretKeys returns the sorted keys of the Secret. Values are never read into messages.
+func getSecretKeys(ctx context.Context, envs map[string]string, namespace, name string) ([]string, error) {
+	getCmd := fmt.Sprintf("kubectl get secret -n %s %s -ojson", namespace, name)
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return nil, fmt.Errorf("while getting secret %s: %w", name, err)
+	}
+
+	var secret corev1.Secret
+	if err := json.Unmarshal([]byte(out.Stdout), &secret); err != nil {
+		return nil, fmt.Errorf("while unmarshalling secret: %w", err)
+	}
+
+	var keys []string
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// secretEnvName returns the env var name used to inject the secret arg into the container.
+func secretEnvName(arg Arg) string {
+	if arg.Env != "" {
+		return arg.Env
+	}
+	name := strings.ToUpper(strings.Trim(arg.Flag, "-"))
+	return "BOTKUBE_SECRET_" + strings.ReplaceAll(name, "-", "_")
+}
+
+// argEnvName returns the env var name for args with the env target, derived from the flag if not set.
+func argEnvName(arg Arg) string {
+	if arg.Env != "" {
+		return arg.Env
+	}
+	return strings.ReplaceAll(strings.ToUpper(strings.Trim(arg.Flag, "-")), "-", "_")
+}
+
+// secretArgParts returns the run override injecting the selected Secret key as env var
+// and, if the arg has a flag, the container arg referencing it, so the value never appears in Slack.
+func secretArgParts(arg Arg, selected string) (override string, part string) {
+	fields := strings.Fields(selected)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	key := fields[len(fields)-1]
+	env := secretEnvName(arg)
+
+	override = fmt.Sprintf("%s:%s=%s/%s", overrideSecretEnv, env, arg.Secret, key)
+	if arg.Flag != "" {
+		part = fmt.Sprintf("%s $(%s)", arg.Flag, env)
+	}
+	return override, part
+}
+
+// secretEnvVar builds the container env var definition of the secret-env override value.
+func secretEnvVar(env, ref string) (map[string]interface{}, error) {
+	secret, key, found := strings.Cut(ref, "/")
+	if !found || secret == "" || key == "" {
+		return nil, fmt.Errorf("invalid secret reference %q, expected <secret>/<key>", ref)
+	}
+	return map[string]interface{}{
+		"name": env,
+		"valueFrom": map[string]interface{}{
+			"secretKeyRef": map[string]interface{}{
+				"name": secret,
+				"key":  key,
+			},
+		},
+	}, nil
+}
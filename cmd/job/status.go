@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// botkubeAnnotation marks jobs created by this plugin.
+const botkubeAnnotation = "botkube"
+
+// listBotkubeJobs returns jobs created by this plugin across all namespaces.
+func listBotkubeJobs(ctx context.Context, envs map[string]string) ([]batchv1.Job, error) {
+	return listBotkubeJobsBySelector(ctx, envs, "")
+}
+
+// listBotkubeJobsBySelector returns jobs created by this plugin matching the optional label selector.
+func listBotkubeJobsBySelector(ctx context.Context, envs map[string]string, selector string) ([]batchv1.Job, error) {
+	runCmd := "kubectl get jobs -A -ojson"
+	if selector != "" {
+		runCmd = fmt.Sprintf("%s -l %s", runCmd, selector)
+	}
+	out, err := kubectl(ctx, envs, runCmd)
+	if err != nil {
+		return nil, fmt.Errorf("while listing jobs: %w", err)
+	}
+
+	var list batchv1.JobList
+	if err := json.Unmarshal([]byte(out.Stdout), &list); err != nil {
+		return nil, fmt.Errorf("while unmarshalling jobs: %w", err)
+	}
+
+	var jobs []batchv1.Job
+	for _, job := range list.Items {
+		if job.Annotations[botkubeAnnotation] != "true" {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// findBotkubeJob returns the botkube-created job with a given name. Namespace is optional.
+func findBotkubeJob(ctx context.Context, envs map[string]string, name, namespace string) (batchv1.Job, error) {
+	if namespace != "" {
+		job, err := getJob(ctx, envs, namespace, name)
+		if err != nil {
+			return batchv1.Job{}, err
+		}
+		if job.Annotations[botkubeAnnotation] != "true" {
+			return batchv1.Job{}, fmt.Errorf("job %s/%s was not created by Botkube", namespace, name)
+		}
+		return job, nil
+	}
+
+	jobs, err := listBotkubeJobs(ctx, envs)
+	if err != nil {
+		return batchv1.Job{}, err
+	}
+	for _, job := range jobs {
+		if job.Name == name {
+			return job, nil
+		}
+	}
+	return batchv1.Job{}, fmt.Errorf("job %s not found", name)
+}
+
+// jobPhase returns a human-readable phase of the job.
+func jobPhase(job batchv1.Job) string {
+	if result, done := jobFinished(job); done {
+		return result.Phase
+	}
+	if job.Status.Active > 0 {
+		return "Running"
+	}
+	return "Pending"
+}
+
+// showJobStatus renders the job status with a button to re-query the cluster.
+func showJobStatus(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string) executor.ExecuteOutput {
+	format, value, err := cutOutputFormat(value)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	name, namespace, ok := parseJobRef(value)
+	if !ok {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Usage: %s status <name> [namespace] [-o json|yaml]", pluginName), true),
+		}
+	}
+
+	job, err := findBotkubeJob(ctx, envs, name, namespace)
+	if err == nil {
+		err = authorizeJob(ctx, envs, cfg, scope, job)
+	}
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	if format != "" {
+		return machineOutput(format, summarizeJob(job))
+	}
+
+	startTime := "-"
+	if job.Status.StartTime != nil {
+		startTime = job.Status.StartTime.Format(time.RFC3339)
+	}
+
+	btnBuilder := api.NewMessageButtonBuilder()
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections: []api.Section{
+				{
+					Base: api.Base{
+						Header: fmt.Sprintf("Job %s", job.Name),
+					},
+					TextFields: api.TextFields{
+						{Key: "Namespace", Value: job.Namespace},
+						{Key: "Phase", Value: jobPhase(job)},
+						{Key: "Start time", Value: startTime},
+						{Key: "Active", Value: strconv.Itoa(int(job.Status.Active))},
+						{Key: "Succeeded", Value: strconv.Itoa(int(job.Status.Succeeded))},
+						{Key: "Failed", Value: strconv.Itoa(int(job.Status.Failed))},
+					},
+					Buttons: []api.Button{
+						btnBuilder.ForCommandWithoutDesc("Refresh", fmt.Sprintf("%s status %s %s", pluginName, job.Name, job.Namespace)),
+					},
+				},
+			},
+			OnlyVisibleForYou: true,
+			ReplaceOriginal:   true,
+		},
+	}
+}
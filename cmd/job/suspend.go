@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// cronJobScheduleSection shows the CronJob schedule and whether it is active, with buttons to edit it and to suspend or resume it.
+func cronJobScheduleSection(job Job) api.Section {
+	btnBuilder := api.NewMessageButtonBuilder()
+	state, btn := "active", btnBuilder.ForCommandWithoutDesc("Suspend", fmt.Sprintf("%s suspend %s %s", pluginName, job.Name, job.Namespace), api.ButtonStyleDanger)
+	if job.Suspended {
+		state, btn = "suspended", btnBuilder.ForCommandWithoutDesc("Resume", fmt.Sprintf("%s resume %s %s", pluginName, job.Name, job.Namespace), api.ButtonStylePrimary)
+	}
+	return api.Section{
+		TextFields: api.TextFields{
+			{Key: "Namespace", Value: job.Namespace},
+			{Key: "Schedule", Value: fmt.Sprintf("%s (%s)", job.Schedule, state)},
+		},
+		Buttons: []api.Button{
+			btnBuilder.ForCommandWithoutDesc("Edit schedule", fmt.Sprintf("%s schedule-edit %s %s", pluginName, job.Name, job.Namespace)),
+			btn,
+		},
+	}
+}
+
+// setCronJobSuspend suspends or resumes the schedule of a discovered CronJob. Usage: job suspend|resume <name> [namespace]
+func setCronJobSuspend(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, value string, suspend bool) executor.ExecuteOutput {
+	action := "resume"
+	if suspend {
+		action = "suspend"
+	}
+	name, namespace, ok := parseJobRef(value)
+	if !ok {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Usage: %s %s <name> [namespace]", pluginName, action), true),
+		}
+	}
+
+	// Only CronJobs discovered for the user in this channel can be changed
+	target, err := findCronJob(ctx, envs, cfg, scope, name, namespace)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	patchCmd := fmt.Sprintf(`kubectl patch cronjob -n %s %s -p '{"spec":{"suspend":%t}}'`, target.Namespace, target.Name, suspend)
+	if _, err := kubectl(ctx, envs, patchCmd); err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("while trying to %s cronjob %s: %v", action, target.Name, err), true),
+		}
+	}
+	invalidateJobsCache()
+
+	target.Suspended = suspend
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			BaseBody: api.Body{
+				Plaintext: fmt.Sprintf("CronJob %s was %sd by %s", target.Name, action, scope.User.Mention),
+			},
+			Sections: []api.Section{cronJobScheduleSection(target)},
+		},
+	}
+}
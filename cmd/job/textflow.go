@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// textJobList lists the jobs with their run command, for platforms without interactive messages.
+func textJobList(ctx context.Context, envs map[string]string, cfg Config, scope jobScope) executor.ExecuteOutput {
+	jobs, err := getBotkubeJobs(ctx, envs, cfg, scope)
+	if err != nil && len(jobs) == 0 {
+		return discoveryError(err)
+	}
+	if len(jobs) == 0 {
+		return executor.ExecuteOutput{
+			Message: api.NewPlaintextMessage("No jobs available", true),
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Run a job with `%s run <name> [namespace] [--flag value...]`, run it without args to see its parameters\n", pluginName)
+	for _, job := range jobs {
+		fmt.Fprintf(&out, "\n%s (%s) in namespace %s", textJobName(job), job.Kind, job.Namespace)
+		if len(job.Issues) > 0 {
+			fmt.Fprintf(&out, " %s, see `%s doctor`", invalidJobLabel, pluginName)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(&out, "\n\nSome jobs could not be listed: %v", err)
+	}
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(out.String(), true),
+	}
+}
+
+func textJobName(job Job) string {
+	if job.Kind == kindJob {
+		return jobTemplatePrefix + job.Name
+	}
+	return job.Name
+}
+
+// runFromText runs a job from "<name> [namespace] [--flag value...]" with the same validation as the form.
+// Without all required args, it prints the parameters of the job instead. Commands with run overrides,
+// as generated by the form, are run as they are.
+func runFromText(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, in executor.ExecuteInput, value string) executor.ExecuteOutput {
+	fields, err := splitArgs(value)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+	if len(fields) == 0 {
+		return textJobList(ctx, envs, cfg, scope)
+	}
+	if slices.Contains(fields, runArgsSeparator) {
+		return runJob(ctx, envs, cfg, scope, in, value, false)
+	}
+
+	name, rest := fields[0], fields[1:]
+	var namespace string
+	if len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+		namespace, rest = rest[0], rest[1:]
+	}
+	job, err := findTextJob(ctx, envs, cfg, scope, name, namespace)
+	if err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(err.Error(), true),
+		}
+	}
+
+	job.Args = withLiveDefaults(ctx, envs, job.Namespace, job.Args)
+
+	details := stateDetails{kind: job.Kind, job: job.Name, params: map[string]string{}}
+	var issues []string
+	for i := 0; i < len(rest); i++ {
+		flag := rest[i]
+		// positional args are given by their position, e.g. #0 value
+		idx := slices.IndexFunc(job.Args, func(a Arg) bool {
+			return strings.TrimLeft(argName(a), "-") == strings.TrimLeft(flag, "-")
+		})
+		if !strings.HasPrefix(flag, "-") && !strings.HasPrefix(flag, "#") || idx < 0 {
+			issues = append(issues, fmt.Sprintf("unknown parameter %q", flag))
+			continue
+		}
+		arg := job.Args[idx]
+
+		// bool flags may be given without a value, so only true or false is taken as theirs
+		argVal := "true"
+		if i+1 < len(rest) && isTextArgValue(arg, rest[i+1]) {
+			argVal = rest[i+1]
+			i++
+		} else if arg.Type != "bool" {
+			issues = append(issues, fmt.Sprintf("%s: missing value", arg.Description))
+			continue
+		}
+
+		key := argKey(job.Name, arg)
+		if isSelectArg(arg) {
+			if values := optionValues(ctx, envs, job.Namespace, arg); len(values) > 0 && !slices.Contains(values, argVal) {
+				issues = append(issues, fmt.Sprintf("%s: %q is not one of %s", arg.Description, argVal, strings.Join(values, ", ")))
+				continue
+			}
+		}
+		// list flags and dropdowns accepting several values may be repeated
+		if (arg.Type == argTypeList || arg.Multiple) && details.params[key] != "" {
+			argVal = details.params[key] + "," + argVal
+		}
+		if !isSelectArg(arg) {
+			details.params[key] = argVal
+			continue
+		}
+		details.params[key] = selectParam(arg, argVal)
+	}
+	// defaults are preselected in the form as well
+	for _, arg := range job.Args {
+		key := argKey(job.Name, arg)
+		if _, exists := details.params[key]; exists || arg.Default == "" {
+			continue
+		}
+		if isSelectArg(arg) {
+			details.params[key] = selectParam(arg, arg.Default)
+		} else {
+			details.params[key] = arg.Default
+		}
+	}
+
+	args := visibleArgs(job.Args, details)
+	issues = append(issues, validateSelections(details, args)...)
+	if len(issues) > 0 || (len(rest) == 0 && len(args) > 0) || !allSelectionsMade(details, args) {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(formatJobArgs(job, args, issues), true),
+		}
+	}
+
+	runValue := strings.TrimPrefix(buildFinalCommand(args, job.Namespace, details), pluginName+" run ")
+	return runJob(ctx, envs, cfg, scope, in, runValue, false)
+}
+
+// findTextJob returns the runnable job by name, which must be unique if the namespace is not given.
+func findTextJob(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, name, namespace string) (Job, error) {
+	jobs, err := getBotkubeJobs(ctx, envs, cfg, scope)
+	var found []Job
+	for _, job := range jobs {
+		if textJobName(job) == name && (namespace == "" || job.Namespace == namespace) {
+			found = append(found, job)
+		}
+	}
+	switch {
+	case len(found) == 0 && err != nil:
+		return Job{}, fmt.Errorf("while listing jobs: %w", err)
+	case len(found) == 0:
+		return Job{}, fmt.Errorf("job %s not found, list jobs with `%s run`", name, pluginName)
+	case len(found) > 1:
+		return Job{}, fmt.Errorf("job %s exists in several namespaces, add the namespace: `%s run %s <namespace> ...`", name, pluginName, name)
+	case found[0].Kind == kindDeployment:
+		return Job{}, fmt.Errorf("%s is a deployment, restart it with `%s restart %s %s`", name, pluginName, name, found[0].Namespace)
+	case len(found[0].Issues) > 0:
+		return Job{}, fmt.Errorf("job %s has an invalid definition:\n%s", name, strings.Join(found[0].Issues, "\n"))
+	}
+	return found[0], nil
+}
+
+// formatJobArgs describes the parameters of the job together with problems of the given ones.
+func formatJobArgs(job Job, args []Arg, issues []string) string {
+	var out strings.Builder
+	if len(issues) > 0 {
+		out.WriteString("Invalid parameters:\n")
+		for _, issue := range issues {
+			fmt.Fprintf(&out, "  - %s\n", issue)
+		}
+		out.WriteString("\n")
+	}
+
+	fmt.Fprintf(&out, "Usage: %s run %s %s", pluginName, textJobName(job), job.Namespace)
+	for _, arg := range args {
+		usage := fmt.Sprintf("%s <value>", argName(arg))
+		if arg.Type == "bool" {
+			usage = arg.Flag
+		}
+		if arg.Optional || arg.Type == "bool" || arg.Default != "" {
+			usage = fmt.Sprintf("[%s]", usage)
+		}
+		fmt.Fprintf(&out, " %s", usage)
+	}
+	out.WriteString("\n")
+
+	for _, arg := range args {
+		fmt.Fprintf(&out, "\n  %s  %s (%s", argName(arg), arg.Description, arg.Type)
+		if arg.Default != "" {
+			fmt.Fprintf(&out, ", default %s", arg.Default)
+		}
+		out.WriteString(")")
+		if len(arg.Values) > 0 {
+			fmt.Fprintf(&out, ": %s", strings.Join(arg.Values, ", "))
+		}
+	}
+	return out.String()
+}
+
+// isTextArgValue returns true if the token is the value of the arg rather than the next flag or positional arg,
+// e.g. in --dry-run #0 app. Negative numbers are values of number args.
+func isTextArgValue(arg Arg, token string) bool {
+	if arg.Type == "bool" {
+		return token == "true" || token == "false"
+	}
+	if arg.Type == argTypeNumber || arg.Type == argTypeInt {
+		if _, err := strconv.ParseFloat(token, 64); err == nil {
+			return true
+		}
+	}
+	return !strings.HasPrefix(token, "-") && !strings.HasPrefix(token, "#")
+}
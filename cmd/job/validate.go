@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Arg types rendered as plaintext inputs.
+const (
+	argTypeText   = "text"
+	argTypeNumber = "number"
+	argTypeInt    = "int"
+	// argTypeList holds comma-separated values, passed as a repeated flag: --flag v1 --flag v2
+	argTypeList = "list"
+)
+
+// isInputArg returns true if the arg value is typed by the user instead of being selected.
+func isInputArg(arg Arg) bool {
+	switch arg.Type {
+	case argTypeText, argTypeNumber, argTypeInt, argTypeQuantity, argTypeList:
+		return true
+	}
+	return false
+}
+
+// validateArg returns a user-facing error if the value doesn't match the arg constraints.
+// The arg ErrorMessage replaces the default error description.
+func validateArg(arg Arg, value string) error {
+	var err error
+	switch arg.Type {
+	case argTypeNumber, argTypeInt:
+		err = validateNumber(arg, strings.TrimSpace(value))
+	case argTypeText:
+		err = validatePattern(arg, value)
+	case argTypeList:
+		err = validateList(arg, value)
+	case "dropdown":
+		if arg.Multiple {
+			err = validateList(arg, value)
+		}
+	case argTypeQuantity:
+		err = validateQuantity(arg, strings.TrimSpace(value))
+	}
+	if err != nil && arg.ErrorMessage != "" {
+		return fmt.Errorf("%s: %s", arg.Description, arg.ErrorMessage)
+	}
+	return err
+}
+
+// validatePattern checks the value against the pattern of the arg. The pattern must match the whole value, as
+// an unanchored pattern matching a part of it, e.g. [a-z]+, would accept anything around that part.
+func validatePattern(arg Arg, value string) error {
+	if arg.Pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(`^(?:` + arg.Pattern + `)$`)
+	if err != nil {
+		return fmt.Errorf("%s: invalid pattern %q in the job definition: %v", arg.Description, arg.Pattern, err)
+	}
+	if !re.MatchString(value) {
+		return fmt.Errorf("%s: %q doesn't match the pattern %s", arg.Description, value, arg.Pattern)
+	}
+	return nil
+}
+
+// validateList checks each item of the list against the allowed values and the pattern.
+func validateList(arg Arg, value string) error {
+	items := listItems(value)
+	if len(items) == 0 {
+		return fmt.Errorf("%s: expected comma-separated values", arg.Description)
+	}
+	for _, item := range items {
+		if len(arg.Values) > 0 && !slices.Contains(arg.Values, item) {
+			return fmt.Errorf("%s: %q is not one of %s", arg.Description, item, strings.Join(arg.Values, ", "))
+		}
+		if err := validatePattern(arg, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listItems splits the list value on commas, dropping empty items.
+func listItems(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func validateNumber(arg Arg, value string) error {
+	var (
+		num float64
+		err error
+	)
+	if arg.Type == argTypeInt {
+		var i int64
+		i, err = strconv.ParseInt(value, 10, 64)
+		num = float64(i)
+	} else {
+		num, err = strconv.ParseFloat(value, 64)
+	}
+	// NaN would pass the range checks, as every comparison with it is false
+	if err != nil || math.IsNaN(num) || math.IsInf(num, 0) {
+		return fmt.Errorf("%s: %q is not a valid %s", arg.Description, value, arg.Type)
+	}
+
+	if arg.Min != nil && num < *arg.Min {
+		return fmt.Errorf("%s: %s is lower than the minimum %v", arg.Description, value, *arg.Min)
+	}
+	if arg.Max != nil && num > *arg.Max {
+		return fmt.Errorf("%s: %s is greater than the maximum %v", arg.Description, value, *arg.Max)
+	}
+	if arg.Step != nil && *arg.Step > 0 {
+		var base float64
+		if arg.Min != nil {
+			base = *arg.Min
+		}
+		steps := (num - base) / *arg.Step
+		if math.Abs(steps-math.Round(steps)) > 1e-9 {
+			return fmt.Errorf("%s: %s is not a multiple of step %v", arg.Description, value, *arg.Step)
+		}
+	}
+	return nil
+}
+
+// validateSelections returns validation errors of all filled-in args.
+func validateSelections(details stateDetails, options []Arg) []string {
+	var issues []string
+	for _, option := range options {
+		value, ok := details.params[argKey(details.job, option)]
+		if !ok || value == "" {
+			continue
+		}
+		if err := validateArg(option, value); err != nil {
+			issues = append(issues, err.Error())
+		}
+	}
+	return issues
+}
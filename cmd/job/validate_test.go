@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestValidateNumber(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name    string
+		arg     Arg
+		value   string
+		wantErr bool
+	}{
+		{name: "number", arg: Arg{Type: argTypeNumber}, value: "1.5"},
+		{name: "negative number", arg: Arg{Type: argTypeNumber}, value: "-3"},
+		{name: "not a number", arg: Arg{Type: argTypeNumber}, value: "abc", wantErr: true},
+		{name: "NaN", arg: Arg{Type: argTypeNumber, Min: ptr(0), Max: ptr(10)}, value: "NaN", wantErr: true},
+		{name: "Inf", arg: Arg{Type: argTypeNumber}, value: "+Inf", wantErr: true},
+		{name: "negative Inf", arg: Arg{Type: argTypeNumber}, value: "-Inf", wantErr: true},
+		{name: "int", arg: Arg{Type: argTypeInt}, value: "42"},
+		{name: "fraction as int", arg: Arg{Type: argTypeInt}, value: "4.2", wantErr: true},
+		{name: "NaN as int", arg: Arg{Type: argTypeInt}, value: "NaN", wantErr: true},
+		{name: "at min", arg: Arg{Type: argTypeInt, Min: ptr(1), Max: ptr(5)}, value: "1"},
+		{name: "at max", arg: Arg{Type: argTypeInt, Min: ptr(1), Max: ptr(5)}, value: "5"},
+		{name: "below min", arg: Arg{Type: argTypeInt, Min: ptr(1), Max: ptr(5)}, value: "0", wantErr: true},
+		{name: "above max", arg: Arg{Type: argTypeInt, Min: ptr(1), Max: ptr(5)}, value: "6", wantErr: true},
+		{name: "step", arg: Arg{Type: argTypeNumber, Step: ptr(0.5)}, value: "2.5"},
+		{name: "off step", arg: Arg{Type: argTypeNumber, Step: ptr(0.5)}, value: "2.3", wantErr: true},
+		{name: "step from min", arg: Arg{Type: argTypeInt, Min: ptr(1), Step: ptr(2)}, value: "5"},
+		{name: "off step from min", arg: Arg{Type: argTypeInt, Min: ptr(1), Step: ptr(2)}, value: "4", wantErr: true},
+		{name: "float step", arg: Arg{Type: argTypeNumber, Step: ptr(0.1)}, value: "0.3"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateNumber(tc.arg, tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("validateNumber(%q) error = %v, wantErr %v", tc.value, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		value   string
+		wantErr bool
+	}{
+		{name: "no pattern", value: "anything"},
+		{name: "whole value", pattern: "[a-z0-9-]+", value: "app-1"},
+		{name: "partial match", pattern: "[a-z0-9-]+", value: "app; rm -rf /", wantErr: true},
+		{name: "alternation", pattern: "dev|prod", value: "prod"},
+		{name: "alternation partial match", pattern: "dev|prod", value: "production", wantErr: true},
+		{name: "invalid pattern", pattern: "[a-", value: "a", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validatePattern(Arg{Pattern: tc.pattern}, tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("validatePattern(%q, %q) error = %v, wantErr %v", tc.pattern, tc.value, err, tc.wantErr)
+			}
+		})
+	}
+}
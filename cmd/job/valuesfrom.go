@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ValuesFrom defines a cluster query whose results are offered as dropdown values.
+type ValuesFrom struct {
+	// Resource is the resource type passed to kubectl get, e.g. deployments.
+	Resource string `json:"resource"`
+	// Namespace to query. Defaults to the CronJob namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Selector is an optional label selector.
+	Selector string `json:"selector,omitempty"`
+	// JSONPath selects values from the returned list, e.g. .items[*].metadata.name
+	JSONPath string `json:"jsonpath"`
+}
+
+// getValuesFrom runs the cluster query and returns whitespace-separated results.
+func getValuesFrom(ctx context.Context, envs map[string]string, namespace string, from ValuesFrom) ([]string, error) {
+	if from.Resource == "" || from.JSONPath == "" {
+		return nil, fmt.Errorf("valuesFrom requires resource and jsonpath")
+	}
+	if from.Namespace != "" {
+		namespace = from.Namespace
+	}
+
+	jsonPath := strings.TrimSuffix(strings.TrimPrefix(from.JSONPath, "{"), "}")
+	getCmd := fmt.Sprintf("kubectl get %s -n %s -o 'jsonpath={%s}'", from.Resource, namespace, jsonPath)
+	if from.Selector != "" {
+		getCmd = fmt.Sprintf("%s -l %s", getCmd, from.Selector)
+	}
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return nil, fmt.Errorf("while getting %s: %w", from.Resource, err)
+	}
+	return strings.Fields(out.Stdout), nil
+}
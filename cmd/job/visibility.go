@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/kubeshop/botkube/pkg/api"
+)
+
+// Visibility of plugin messages.
+const (
+	// visibilityPrivate shows messages only to the user who triggered them.
+	visibilityPrivate = "private"
+	// visibilityPublic shows messages to everyone in the channel.
+	visibilityPublic = "public"
+)
+
+// formPrivate returns true if the job form is shown only to the user filling it in.
+func (c Config) formPrivate(channel string) bool {
+	return c.channelVisibility(channel).Form != visibilityPublic
+}
+
+// withResultVisibility sets the visibility of a run confirmation or result if it is configured for the channel.
+// Without configuration, each message keeps its own default.
+func (c Config) withResultVisibility(channel string, msg api.Message) api.Message {
+	switch c.channelVisibility(channel).Results {
+	case visibilityPrivate:
+		msg.OnlyVisibleForYou = true
+	case visibilityPublic:
+		msg.OnlyVisibleForYou = false
+	}
+	return msg
+}
+
+// resultsPrivate returns true if run results are configured to be shown only to the requester.
+func (c Config) resultsPrivate(channel string) bool {
+	return c.channelVisibility(channel).Results == visibilityPrivate
+}
+
+// channelVisibility returns the visibility of the channel, falling back to the global setting for unset fields.
+func (c Config) channelVisibility(channel string) VisibilityConfig {
+	out := VisibilityConfig{Form: c.Visibility.Form, Results: c.Visibility.Results}
+	override, ok := c.Visibility.Channels[channel]
+	if !ok {
+		override = c.Visibility.Channels[anyChannel]
+	}
+	if override.Form != "" {
+		out.Form = override.Form
+	}
+	if override.Results != "" {
+		out.Results = override.Results
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+const (
+	// waitAnnotation makes runs of the job wait for its completion by default, e.g. "10m".
+	waitAnnotation = "botkubeWaitForCompletion"
+	// overrideWait waits for the job to finish in this invocation: wait:=<duration>
+	overrideWait = "wait"
+)
+
+// runWait returns how long the run waits for the job to finish, taken from the wait override
+// or the job annotation and capped at the configured maximum.
+func runWait(cfg Config, req runRequest, source CronJobs) (time.Duration, error) {
+	value := source.Metadata.Annotations[waitAnnotation]
+	for _, override := range req.Overrides {
+		if override.Kind == overrideWait {
+			value = override.Value
+		}
+	}
+	if value == "" {
+		return 0, nil
+	}
+	wait, err := time.ParseDuration(value)
+	if err != nil || wait < 0 {
+		return 0, fmt.Errorf("invalid wait duration %q", value)
+	}
+	return min(wait, cfg.Watch.MaxWait), nil
+}
+
+// waitForCompletion blocks until the created job finishes or the wait times out. A finished job is reported
+// in the reply, otherwise the job is handed over to the background watch which posts the result later.
+func waitForCompletion(ctx context.Context, envs map[string]string, cfg Config, kubeConfig []byte, origin executor.Message, req runRequest, jobName string) executor.ExecuteOutput {
+	waitCtx, cancel := context.WithTimeout(ctx, req.Wait)
+	defer cancel()
+
+	result, err := waitForJob(waitCtx, envs, cfg.Watch.PollInterval, req.Namespace, jobName)
+	if err != nil {
+		startWatch(cfg, kubeConfig, origin, req, jobName)
+		msg := jobStartedMessage(cfg, req, jobName)
+		msg.BaseBody = api.Body{
+			Plaintext: fmt.Sprintf("Job %s did not finish within %s", jobName, req.Wait),
+		}
+		return executor.ExecuteOutput{Message: cfg.withResultVisibility(req.Channel, msg)}
+	}
+	recordOutcome(ctx, envs, cfg, req.Namespace, jobName, result.Phase)
+
+	var logsChannel string
+	if !cfg.resultsPrivate(req.Channel) {
+		logsChannel = channelFromURL(origin.URL)
+		if logsChannel == "" {
+			logsChannel = cfg.ChannelID
+		}
+	}
+	collectLogs(ctx, envs, cfg, &result, req.Namespace, jobName, logsChannel, origin.ParentActivityID)
+	btnBuilder := api.NewMessageButtonBuilder()
+	return executor.ExecuteOutput{
+		Message: cfg.withResultVisibility(req.Channel, api.Message{
+			BaseBody: api.Body{
+				Plaintext: formatJobResult(req.Namespace, jobName, result),
+			},
+			Sections: []api.Section{
+				{
+					Buttons: []api.Button{
+						btnBuilder.ForCommandWithoutDesc("Run again", req.Command, api.ButtonStylePrimary),
+					},
+				},
+			},
+		}),
+	}
+}
+
+// startWatch tracks the job in the background if watching is enabled.
+func startWatch(cfg Config, kubeConfig []byte, origin executor.Message, req runRequest, jobName string) {
+	if !cfg.Watch.Enabled {
+		return
+	}
+	n, err := newNotifier(cfg, origin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "not watching job %s: %v", jobName, err)
+		return
+	}
+	if cfg.resultsPrivate(req.Channel) {
+		n.user = userID(req.Requester)
+	}
+	go watchJob(kubeConfig, cfg, n, req.Namespace, jobName, req.Command)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/plugin"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// jobResult describes the final state of a launched job.
+type jobResult struct {
+	Phase    string
+	Duration time.Duration
+	Reason   string
+	Logs     string
+	// LogsFile links to the logs uploaded as a file when they are too long for the message.
+	LogsFile string
+}
+
+// watchJob tracks the job until it completes, fails or the watch times out, and posts warnings and the outcome
+// together with a button to run the job again with the same parameters.
+// It runs outside of the Execute call, so it persists its own copy of the kubeconfig.
+func watchJob(kubeConfig []byte, cfg Config, n *notifier, namespace, name, runCommand string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Watch.Timeout)
+	defer cancel()
+
+	kubeConfigPath, deleteFn, err := plugin.PersistKubeConfig(ctx, kubeConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write kubeconfig for watching job %s: %v", name, err)
+		return
+	}
+	defer func() {
+		if deleteErr := deleteFn(context.Background()); deleteErr != nil {
+			fmt.Fprintf(os.Stderr, "failed to delete kubeconfig file %s: %v", kubeConfigPath, deleteErr)
+		}
+	}()
+	envs := map[string]string{
+		"KUBECONFIG": kubeConfigPath,
+	}
+
+	// warnings are reported while the job runs and must not be posted after its result
+	stopEvents := func() {}
+	if cfg.Watch.Warnings {
+		eventsCtx, cancelEvents := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			watchJobEvents(eventsCtx, envs, cfg.Watch.PollInterval, n, namespace, name)
+		}()
+		stopEvents = func() {
+			cancelEvents()
+			<-done
+		}
+	}
+
+	// the progress message is edited until the job finishes and then replaced with the result
+	var progress *jobProgress
+	stopProgress := func() {}
+	// ephemeral messages cannot be edited, so private results get no progress message
+	if cfg.Watch.Progress && cfg.Watch.ProgressInterval > 0 && n.user == "" {
+		progress = startProgress(ctx, n, namespace, name)
+	}
+	if progress != nil {
+		progressCtx, cancelProgress := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			progress.run(progressCtx, envs, cfg.Watch.ProgressInterval)
+		}()
+		stopProgress = func() {
+			cancelProgress()
+			<-done
+		}
+	}
+
+	result, err := waitForJob(ctx, envs, cfg.Watch.PollInterval, namespace, name)
+	stopEvents()
+	stopProgress()
+	if err != nil {
+		result = jobResult{Phase: "Unknown", Reason: err.Error()}
+	}
+
+	// the watch context may already be expired at this point
+	postCtx, postCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer postCancel()
+	recordOutcome(postCtx, envs, cfg, namespace, name, result.Phase)
+	// private results are posted as ephemeral messages, their logs are not shared with the channel
+	var logsChannel string
+	if n.user == "" {
+		logsChannel = n.channel
+	}
+	collectLogs(postCtx, envs, cfg, &result, namespace, name, logsChannel, n.threadTS)
+	btnBuilder := api.NewMessageButtonBuilder()
+	rerun := btnBuilder.ForCommandWithoutDesc("Run again", runCommand, api.ButtonStylePrimary)
+	text := formatJobResult(namespace, name, result)
+	if progress != nil {
+		if err := progress.finish(postCtx, text, rerun); err == nil {
+			return
+		}
+	}
+	if err := n.Post(postCtx, text, rerun); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to post result of job %s: %v", name, err)
+	}
+}
+
+// waitForJob polls the job until it reaches a terminal condition.
+func waitForJob(ctx context.Context, envs map[string]string, interval time.Duration, namespace, name string) (jobResult, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := getJob(ctx, envs, namespace, name)
+		if err == nil {
+			if result, done := jobFinished(job); done {
+				result.Reason = describeFailure(ctx, envs, namespace, name, result.Reason)
+				return result, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return jobResult{}, fmt.Errorf("stopped watching the job: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func getJob(ctx context.Context, envs map[string]string, namespace, name string) (batchv1.Job, error) {
+	getCmd := fmt.Sprintf("kubectl get job -n %s %s -ojson", namespace, name)
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return batchv1.Job{}, err
+	}
+
+	var job batchv1.Job
+	if err := json.Unmarshal([]byte(out.Stdout), &job); err != nil {
+		return batchv1.Job{}, fmt.Errorf("while unmarshalling job: %w", err)
+	}
+	return job, nil
+}
+
+// jobFinished returns the job result if the job has a Complete or Failed condition.
+func jobFinished(job batchv1.Job) (jobResult, bool) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		if cond.Type != batchv1.JobComplete && cond.Type != batchv1.JobFailed {
+			continue
+		}
+
+		phase := "Succeeded"
+		if cond.Type == batchv1.JobFailed {
+			phase = "Failed"
+		}
+
+		end := cond.LastTransitionTime.Time
+		if job.Status.CompletionTime != nil {
+			end = job.Status.CompletionTime.Time
+		}
+		var duration time.Duration
+		if job.Status.StartTime != nil {
+			duration = end.Sub(job.Status.StartTime.Time)
+		}
+
+		reason := cond.Reason
+		if cond.Message != "" {
+			reason = fmt.Sprintf("%s: %s", cond.Reason, cond.Message)
+		}
+		return jobResult{Phase: phase, Duration: duration, Reason: reason}, true
+	}
+	return jobResult{}, false
+}
+
+// describeFailure enriches the job reason with the termination state of its containers.
+func describeFailure(ctx context.Context, envs map[string]string, namespace, name, reason string) string {
+	getCmd := fmt.Sprintf("kubectl get pods -n %s -l job-name=%s -ojson", namespace, name)
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return reason
+	}
+
+	var pods corev1.PodList
+	if err := json.Unmarshal([]byte(out.Stdout), &pods); err != nil {
+		return reason
+	}
+
+	var exits []string
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			term := status.State.Terminated
+			if term == nil {
+				continue
+			}
+			exits = append(exits, fmt.Sprintf("%s/%s: %s (exit code %d)", pod.Name, status.Name, term.Reason, term.ExitCode))
+		}
+	}
+	if len(exits) == 0 {
+		return reason
+	}
+	if reason == "" {
+		return strings.Join(exits, "\n")
+	}
+	return fmt.Sprintf("%s\n%s", reason, strings.Join(exits, "\n"))
+}
+
+func formatJobResult(namespace, name string, result jobResult) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "Job *%s* in namespace *%s* finished\n", name, namespace)
+	fmt.Fprintf(&out, "```\nPhase:    %s\nDuration: %s\n", result.Phase, result.Duration.Round(time.Second))
+	if result.Reason != "" {
+		fmt.Fprintf(&out, "Reason:   %s\n", result.Reason)
+	}
+	out.WriteString("```")
+	if result.Logs != "" {
+		fmt.Fprintf(&out, "\nLogs:\n```\n%s\n```", result.Logs)
+	}
+	if result.LogsFile != "" {
+		fmt.Fprintf(&out, "\nLogs are too long for a message, they are uploaded as %s", result.LogsFile)
+	}
+	return out.String()
+}
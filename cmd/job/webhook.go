@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultWebhookTemplate is the payload sent when no template is configured.
+const defaultWebhookTemplate = `{
+  "event": "job.created",
+  "job": {{json .Job}},
+  "kind": {{json .Kind}},
+  "namespace": {{json .Namespace}},
+  "jobName": {{json .JobName}},
+  "cluster": {{json .Cluster}},
+  "requester": {{json .Requester}},
+  "approver": {{json .Approver}},
+  "channel": {{json .Channel}},
+  "args": {{json .Args}},
+  "overrides": {{json .Overrides}},
+  "command": {{json .Command}},
+  "createdAt": {{json .CreatedAt}}
+}`
+
+// webhookData holds the fields available in the webhook payload template.
+type webhookData struct {
+	Job       string
+	Kind      string
+	Namespace string
+	JobName   string
+	Cluster   string
+	Requester string
+	Approver  string
+	Channel   string
+	Args      []string
+	Overrides []string
+	Command   string
+	CreatedAt string
+}
+
+// notifyWebhook posts the created job to the configured webhook, e.g. to record it in a change log.
+// It is sent in the background, so a slow or failing endpoint doesn't delay the run. Failures are logged.
+func notifyWebhook(cfg Config, req runRequest, jobName string, now time.Time) {
+	if cfg.Webhook.URL == "" {
+		return
+	}
+	audit := auditOf(req)
+	data := webhookData{
+		Job:       req.Name,
+		Kind:      req.Kind,
+		Namespace: req.Namespace,
+		JobName:   jobName,
+		Cluster:   req.Cluster,
+		Requester: requesterName(req.Requester),
+		Channel:   req.Channel,
+		Args:      audit.Args,
+		Overrides: audit.Overrides,
+		Command:   req.Command,
+		CreatedAt: now.UTC().Format(time.RFC3339),
+	}
+	if req.Approver.Mention != "" {
+		data.Approver = requesterName(req.Approver)
+	}
+	payload, err := renderWebhookPayload(cfg.Webhook.Template, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to render webhook payload of job %s: %v", jobName, err)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Webhook.Timeout)
+		defer cancel()
+		if err := postWebhook(ctx, cfg.Webhook, payload); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to notify webhook about job %s: %v", jobName, err)
+		}
+	}()
+}
+
+// renderWebhookPayload renders the template, or the default payload if it is empty.
+// The json function writes a value as JSON, so strings are quoted and escaped.
+func renderWebhookPayload(text string, data webhookData) ([]byte, error) {
+	if strings.TrimSpace(text) == "" {
+		text = defaultWebhookTemplate
+	}
+	tmpl, err := template.New("webhook").Option("missingkey=error").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			raw, err := json.Marshal(v)
+			return string(raw), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("while rendering webhook template: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func postWebhook(ctx context.Context, cfg WebhookConfig, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range cfg.Headers {
+		req.Header.Set(key, os.ExpandEnv(value))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// Kinds of workloads that can be driven through the interactive flow.
+const (
+	kindCronJob    = "CronJob"
+	kindJob        = "Job"
+	kindDeployment = "Deployment"
+)
+
+const (
+	// jobTemplateAnnotation marks a Job, usually a suspended one, as a template for ad-hoc runs.
+	jobTemplateAnnotation = "botkubeJobTemplate"
+	// restartAnnotation allows restarting the Deployment with a rollout restart.
+	restartAnnotation = "botkubeRestart"
+	// jobTemplatePrefix marks Job templates in the run command, e.g. job run job/<name> <namespace>
+	jobTemplatePrefix = "job/"
+	// restartConfirm confirms the restart in the restart command.
+	restartConfirm = "confirm"
+)
+
+// workloadKinds lists the kinds in display order.
+var workloadKinds = []string{kindCronJob, kindJob, kindDeployment}
+
+// listCommands returns kubectl commands listing the resource in the allowed namespaces.
+// Listing only included namespaces doesn't require cluster-wide permissions.
+func listCommands(cfg Config, resource string) []string {
+	if len(cfg.Namespaces.Include) == 0 {
+		return []string{fmt.Sprintf("kubectl get %s -A -ojson", resource)}
+	}
+	var runCmds []string
+	for _, ns := range cfg.Namespaces.Include {
+		runCmds = append(runCmds, fmt.Sprintf("kubectl get %s -n %s -ojson", resource, ns))
+	}
+	return runCmds
+}
+
+// listWorkloads returns the resources of a given type in the allowed namespaces. Namespaces that cannot be listed
+// are skipped and their errors joined, so one failing namespace doesn't hide the others. Forbidden lists are only
+// logged, as the plugin may deliberately lack permissions for some kinds or namespaces.
+func listWorkloads(ctx context.Context, envs map[string]string, cfg Config, resource string) ([]CronJobs, error) {
+	var items []CronJobs
+	var errs []error
+	for _, runCmd := range listCommands(cfg, resource) {
+		out, err := kubectl(ctx, envs, runCmd)
+		if err != nil {
+			if strings.Contains(out.Stderr, "(Forbidden)") {
+				fmt.Fprintf(os.Stderr, "skipping %s: %v", resource, err)
+				continue
+			}
+			errs = append(errs, fmt.Errorf("while listing %s: %w", resource, err))
+			continue
+		}
+		var list CronJobsList
+		if err := json.Unmarshal([]byte(out.Stdout), &list); err != nil {
+			errs = append(errs, fmt.Errorf("while unmarshalling %s: %w", resource, err))
+			continue
+		}
+		items = append(items, list.Items...)
+	}
+	return items, errors.Join(errs...)
+}
+
+// sourceResource returns the kubectl resource of a runnable kind, the cronjob or the Job template runs are created from,
+// or the restarted deployment.
+func sourceResource(kind string) string {
+	switch kind {
+	case kindJob:
+		return "job"
+	case kindDeployment:
+		return "deployment"
+	}
+	return "cronjob"
+}
+
+// getWorkload returns the metadata of a single resource, e.g. a cronjob or deployment.
+func getWorkload(ctx context.Context, envs map[string]string, resource, namespace, name string) (CronJobs, error) {
+	getCmd := fmt.Sprintf("kubectl get %s -n %s %s -ojson", resource, namespace, name)
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return CronJobs{}, err
+	}
+
+	var workload CronJobs
+	if err := json.Unmarshal([]byte(out.Stdout), &workload); err != nil {
+		return CronJobs{}, fmt.Errorf("while unmarshalling %s: %w", resource, err)
+	}
+	return workload, nil
+}
+
+// availableKinds returns the kinds of the discovered jobs in display order.
+func availableKinds(jobs []Job) []string {
+	var kinds []string
+	for _, kind := range workloadKinds {
+		if slices.ContainsFunc(jobs, func(j Job) bool { return j.Kind == kind }) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// jobsOfKind filters the jobs by kind. The first available kind is used if kind is empty.
+func jobsOfKind(jobs []Job, kind string) ([]Job, string) {
+	if kind == "" {
+		if kinds := availableKinds(jobs); len(kinds) > 0 {
+			kind = kinds[0]
+		}
+	}
+	var out []Job
+	for _, job := range jobs {
+		if job.Kind == kind {
+			out = append(out, job)
+		}
+	}
+	return out, kind
+}
+
+// createKindSelect renders the kind dropdown shown above the job name select.
+func createKindSelect(kinds []string, selected string, cmd string) api.Select {
+	var options []api.OptionItem
+	for _, kind := range kinds {
+		options = append(options, api.OptionItem{Name: kind, Value: kind})
+	}
+	return api.Select{
+		Name:    "Kind",
+		Command: cmd,
+		OptionGroups: []api.OptionGroup{
+			{
+				Name:    "Kind",
+				Options: options,
+			},
+		},
+		InitialOption: &api.OptionItem{Name: selected, Value: selected},
+	}
+}
+
+// jobFromTemplate returns a Job manifest copied from the Job template, stripped of fields set by the cluster.
+func jobFromTemplate(ctx context.Context, envs map[string]string, namespace, template, jobName string) (map[string]interface{}, error) {
+	getCmd := fmt.Sprintf("kubectl get job -n %s %s -ojson", namespace, template)
+	out, err := kubectl(ctx, envs, getCmd)
+	if err != nil {
+		return nil, fmt.Errorf("while getting job template %s: %w", template, err)
+	}
+
+	var job map[string]interface{}
+	if err := json.Unmarshal([]byte(out.Stdout), &job); err != nil {
+		return nil, fmt.Errorf("while unmarshalling job template: %w", err)
+	}
+
+	metadata, _ := job["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+	}
+	delete(annotations, jobTemplateAnnotation)
+	labels, _ := metadata["labels"].(map[string]interface{})
+	stripJobLabels(labels)
+	job["metadata"] = map[string]interface{}{
+		"name":        jobName,
+		"namespace":   namespace,
+		"labels":      labels,
+		"annotations": annotations,
+	}
+	delete(job, "status")
+
+	spec, _ := job["spec"].(map[string]interface{})
+	delete(spec, "selector")
+	spec["suspend"] = false
+	podTemplate, _ := spec["template"].(map[string]interface{})
+	podMetadata, _ := podTemplate["metadata"].(map[string]interface{})
+	podLabels, _ := podMetadata["labels"].(map[string]interface{})
+	stripJobLabels(podLabels)
+	return job, nil
+}
+
+// stripJobLabels removes labels the Job controller sets for the original Job.
+func stripJobLabels(labels map[string]interface{}) {
+	for _, key := range []string{"controller-uid", "job-name", "batch.kubernetes.io/controller-uid", "batch.kubernetes.io/job-name"} {
+		delete(labels, key)
+	}
+}
+
+// restartDeployment asks for a confirmation and then runs a rollout restart of the Deployment,
+// or requests an approval first if the Deployment requires one.
+func restartDeployment(ctx context.Context, envs map[string]string, cfg Config, scope jobScope, in executor.ExecuteInput, value string) executor.ExecuteOutput {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("usage: %s restart <deployment> <namespace>", pluginName), true),
+		}
+	}
+	name, namespace := fields[0], fields[1]
+	confirmed := len(fields) > 2 && fields[2] == restartConfirm
+
+	if !cfg.isJobAllowed(scope, namespace, name) {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Deployment %s/%s is not allowed in this channel", namespace, name), true),
+		}
+	}
+	deployment, err := getWorkload(ctx, envs, "deployment", namespace, name)
+	if err != nil || deployment.Metadata.Annotations[restartAnnotation] != "true" || !isUserAllowed(ctx, cfg, scope, deployment.Metadata.Annotations) {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("You are not authorized to restart deployment %s/%s", namespace, name), true),
+		}
+	}
+
+	if !confirmed {
+		btnBuilder := api.NewMessageButtonBuilder()
+		return executor.ExecuteOutput{
+			Message: api.Message{
+				Sections: []api.Section{
+					{
+						Base: api.Base{
+							Body: api.Body{
+								Plaintext: fmt.Sprintf("Restart all pods of deployment %s in namespace %s?", name, namespace),
+							},
+						},
+						Buttons: []api.Button{
+							btnBuilder.ForCommandWithoutDesc("Restart", fmt.Sprintf("%s restart %s %s %s", pluginName, name, namespace, restartConfirm), api.ButtonStyleDanger),
+						},
+					},
+				},
+				OnlyVisibleForYou: true,
+				ReplaceOriginal:   true,
+			},
+		}
+	}
+
+	if deployment.Metadata.Annotations[requireApprovalAnnotation] == "true" {
+		return requestApproval(ctx, cfg, in.Context.Message, runRequest{
+			Kind:      kindDeployment,
+			Name:      name,
+			Namespace: namespace,
+			Command:   withClusterFlag(fmt.Sprintf("%s restart %s %s", pluginName, name, namespace), scope.Cluster),
+			Requester: scope.User,
+			Channel:   scope.Channel,
+			Cluster:   scope.Cluster,
+		})
+	}
+	return rolloutRestart(ctx, envs, name, namespace)
+}
+
+// rolloutRestart runs a rollout restart of the Deployment.
+func rolloutRestart(ctx context.Context, envs map[string]string, name, namespace string) executor.ExecuteOutput {
+	restartCmd := fmt.Sprintf("kubectl rollout restart deployment/%s -n %s", name, namespace)
+	if _, err := kubectl(ctx, envs, restartCmd); err != nil {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("while restarting deployment %s: %v", name, err), true),
+		}
+	}
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(fmt.Sprintf("Deployment %s is restarting", name), true),
+	}
+}
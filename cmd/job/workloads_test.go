@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+func TestRestartDeploymentApproval(t *testing.T) {
+	calls := fakeKubectl(t, map[string]string{
+		"get deployment -n team-a api -ojson": fakeWorkload(t, "team-a", "api", map[string]string{
+			restartAnnotation:         "true",
+			requireApprovalAnnotation: "true",
+		}),
+	})
+	requester := jobScope{Channel: "C0123456", User: executor.User{Mention: "<@U0123456>"}, memberOf: map[string]bool{}}
+	approver := jobScope{Channel: "C0123456", User: executor.User{Mention: "<@U0234567>"}, memberOf: map[string]bool{}}
+	cfg := testConfig()
+
+	restartDeployment(context.Background(), nil, cfg, requester, executor.ExecuteInput{}, "api team-a "+restartConfirm)
+	if slices.ContainsFunc(calls(), func(call string) bool { return strings.HasPrefix(call, "rollout restart") }) {
+		t.Fatal("deployment was restarted before an approval")
+	}
+	id := pendingID(t, "api")
+
+	in := executor.ExecuteInput{Context: executor.ExecuteInputContext{Message: executor.Message{User: approver.User}}}
+	approveRun(context.Background(), nil, cfg, approver, in, id)
+	if !slices.Contains(calls(), "rollout restart deployment/api -n team-a") {
+		t.Errorf("approved deployment was not restarted, kubectl calls: %q", calls())
+	}
+}
+
+// pendingID returns the ID of the pending approval of the job or deployment.
+func pendingID(t *testing.T, name string) string {
+	t.Helper()
+	approvals.Lock()
+	defer approvals.Unlock()
+	for id, pending := range approvals.pending {
+		if pending.Request.Name == name {
+			return id
+		}
+	}
+	t.Fatalf("no pending approval of %s", name)
+	return ""
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	"github.com/slack-go/slack"
+)
+
+var (
+	// permalinkRegex extracts the channel ID from a Slack message permalink,
+	// e.g. https://example.slack.com/archives/C0123456789/p1700000000000100
+	permalinkRegex = regexp.MustCompile(`/archives/([A-Z0-9]+)/p\d+`)
+	// channelFlagRegex matches the -ch flag, with a channel name, ID or a channel link Slack renders for #name.
+	channelFlagRegex = regexp.MustCompile(`(^|\s)-ch\s+(<#[A-Z0-9]+(?:\|[^>]*)?>|\S+)`)
+	// dmFlagRegex matches the -dm flag.
+	dmFlagRegex = regexp.MustCompile(`(^|\s)-dm(\s|$)`)
+	// channelLinkRegex extracts the ID and name from a Slack channel link, e.g. <#C0123456789|reports>.
+	channelLinkRegex = regexp.MustCompile(`^<#([A-Z0-9]+)(?:\|([^>]*))?>$`)
+)
+
+// cutChannelFlag returns the value of the -ch flag and the command without it.
+func cutChannelFlag(command string) (channel, rest string) {
+	head, tail := splitFlags(command)
+	match := channelFlagRegex.FindStringSubmatchIndex(head)
+	if match == nil {
+		return "", command
+	}
+	channel = head[match[4]:match[5]]
+	return channel, head[:match[0]] + head[match[1]:] + tail
+}
+
+// cutDMFlag returns whether the -dm flag is set and the command without it.
+func cutDMFlag(command string) (dm bool, rest string) {
+	head, tail := splitFlags(command)
+	match := dmFlagRegex.FindStringSubmatchIndex(head)
+	if match == nil {
+		return false, command
+	}
+	return true, head[:match[0]] + head[match[4]:] + tail
+}
+
+// splitFlags splits the command before -c, so flags are only looked up in the head
+// and the snippet command itself is passed as is.
+func splitFlags(command string) (head, tail string) {
+	if idx := strings.Index(command, " -c "); idx >= 0 {
+		return command[:idx], command[idx:]
+	}
+	return command, ""
+}
+
+// resolveChannel returns the ID of the channel requested with -ch, which must be listed in the channels config,
+// by name with or without #, by ID or as a channel link.
+func resolveChannel(channels map[string]string, requested string) (string, error) {
+	name := strings.TrimPrefix(requested, "#")
+	if match := channelLinkRegex.FindStringSubmatch(requested); match != nil {
+		name = match[1]
+	}
+	for allowedName, id := range channels {
+		if strings.EqualFold(strings.TrimPrefix(allowedName, "#"), name) || id == name {
+			return id, nil
+		}
+	}
+
+	if len(channels) == 0 {
+		return "", fmt.Errorf("channel %s is not allowed, no channels are configured for -ch", requested)
+	}
+	allowed := make([]string, 0, len(channels))
+	for allowedName := range channels {
+		allowed = append(allowed, "#"+strings.TrimPrefix(allowedName, "#"))
+	}
+	slices.Sort(allowed)
+	return "", fmt.Errorf("channel %s is not allowed, use one of %s", requested, strings.Join(allowed, ", "))
+}
+
+// uploadTarget returns the channel of the triggering message and its thread, so results don't flood the channel.
+// When the channel cannot be resolved from the message permalink, the file is shared in the fallback channel.
+func uploadTarget(msg executor.Message, fallbackChannelID string) (channelID, threadTS string) {
+	matches := permalinkRegex.FindStringSubmatch(msg.URL)
+	if len(matches) != 2 {
+		return fallbackChannelID, ""
+	}
+	return matches[1], msg.ParentActivityID
+}
+
+// openDM opens a direct message with the user, so the result is delivered privately. It needs the im:write scope.
+func openDM(ctx context.Context, botToken string, user executor.User) (string, error) {
+	userID := strings.TrimSuffix(strings.TrimPrefix(user.Mention, "<@"), ">")
+	if userID == "" {
+		return "", fmt.Errorf("cannot send a direct message, the requesting user is unknown")
+	}
+	channel, _, _, err := slack.New(botToken).OpenConversationContext(ctx, &slack.OpenConversationParameters{
+		Users: []string{userID},
+	})
+	if err != nil {
+		return "", fmt.Errorf("while opening a direct message with %s: %w", userID, err)
+	}
+	return channel.ID, nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxCommands limits the commands of one invocation, each result is attached as a file of the same message.
+const maxCommands = 10
+
+var (
+	// commandFlagRegex matches a quoted -c flag, which is repeated to run several commands.
+	commandFlagRegex = regexp.MustCompile(`(^|\s)-c\s+('[^']*'|"[^"]*")`)
+	// commandsFileFlagRegex matches the --commands-file flag.
+	commandsFileFlagRegex = regexp.MustCompile(`(^|\s)--commands-file\s+(\S+)`)
+	// messageFlagRegex matches the -m flag of several commands, with a quoted or unquoted message.
+	messageFlagRegex = regexp.MustCompile(`(^|\s)-m\s+('[^']*'|"[^"]*"|\S+)`)
+
+	// commandSeparators split a shell command line into the commands it runs, each one is checked on its own.
+	commandSeparators = regexp.MustCompile(`\|\||&&|[|;&\n]`)
+	// commandNameRegex matches a command name the shell runs as it is written. Quotes, expansions, globs,
+	// negation and leading VAR=value assignments hide the command from the check, e.g. 'kubectl' delete pod app.
+	commandNameRegex = regexp.MustCompile(`^[^\s'"$*?\[\]=!~]+(\s|$)`)
+	// spaceRegex matches runs of whitespace, which the shell splits words on like a single space.
+	spaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// checkCommand refuses the command line unless every command in it matches an allow pattern, when there are any,
+// and none matches a deny pattern. A pattern is a regular expression matched against the start of a command
+// up to a word boundary, e.g. kubectl, helm or kubectl (get|describe).
+// Shell syntax the check cannot follow is refused. Deny patterns are still advisory, the same command can be
+// written in other ways, e.g. kubectl -n apps delete pod app, so commands are restricted with allow patterns.
+func checkCommand(cfg CommandsConfig, cmd string) error {
+	if len(cfg.Allow) == 0 && len(cfg.Deny) == 0 {
+		return nil
+	}
+	allow, err := compileCommandPatterns(cfg.Allow)
+	if err != nil {
+		return fmt.Errorf("invalid commands.allow: %v", err)
+	}
+	deny, err := compileCommandPatterns(cfg.Deny)
+	if err != nil {
+		return fmt.Errorf("invalid commands.deny: %v", err)
+	}
+
+	if substitutionRegex.MatchString(cmd) {
+		return fmt.Errorf("command %q is not allowed, command substitution is not supported when commands are restricted", cmd)
+	}
+	if shellSyntaxRegex.MatchString(cmd) {
+		return fmt.Errorf("command %q is not allowed, %q is not supported when commands are restricted", cmd, shellSyntaxRegex.FindString(cmd))
+	}
+	for _, part := range commandSeparators.Split(cmd, -1) {
+		// patterns are written with single spaces, kubectl  delete or a tab must not get past kubectl delete
+		part = spaceRegex.ReplaceAllString(strings.TrimSpace(part), " ")
+		if part == "" {
+			continue
+		}
+		if !commandNameRegex.MatchString(part) {
+			return fmt.Errorf("command %q is not allowed, the command name must be written as is when commands are restricted", part)
+		}
+		for i, re := range deny {
+			if re.MatchString(part) {
+				return fmt.Errorf("command %q is not allowed, it matches the denied pattern %q", part, cfg.Deny[i])
+			}
+		}
+		if len(allow) > 0 && !matchesAny(allow, part) {
+			return fmt.Errorf("command %q is not allowed, allowed commands are %s", part, strings.Join(cfg.Allow, ", "))
+		}
+	}
+	return nil
+}
+
+// allowedBinary returns the binary of the command if it is kubectl or one of the binaries, by name.
+// Paths are refused, so a binary of the same name elsewhere cannot be run.
+func allowedBinary(binaries []string, cmd string) (string, error) {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("command is empty")
+	}
+	bin := fields[0]
+	if bin == "kubectl" || slices.Contains(binaries, bin) && !strings.ContainsAny(bin, `/\`) {
+		return bin, nil
+	}
+	allowed := append([]string{"kubectl"}, binaries...)
+	return "", fmt.Errorf("command %q is not allowed, only %s can be run in kubectlOnly mode", cmd, strings.Join(allowed, ", "))
+}
+
+// cutCommands returns the commands of an invocation running several of them, given with repeated quoted -c flags
+// or in the file of filesDir named with --commands-file, and the message given with -m. A single -c is left to
+// parseCmdAndMsg, in which case no commands are returned.
+func cutCommands(command, filesDir string) (cmds []string, msg string, err error) {
+	var file string
+	if match := commandsFileFlagRegex.FindStringSubmatchIndex(command); match != nil {
+		file = command[match[4]:match[5]]
+		command = command[:match[0]] + command[match[1]:]
+	}
+	matches := commandFlagRegex.FindAllStringSubmatchIndex(command, -1)
+	if file == "" && len(matches) < 2 {
+		return nil, "", nil
+	}
+
+	rest := command
+	for i := len(matches) - 1; i >= 0; i-- {
+		match := matches[i]
+		cmds = append(cmds, strings.Trim(command[match[4]:match[5]], `"'`))
+		rest = rest[:match[0]] + rest[match[1]:]
+	}
+	slices.Reverse(cmds)
+	if file != "" {
+		fileCmds, err := readCommandsFile(filesDir, file)
+		if err != nil {
+			return nil, "", err
+		}
+		cmds = append(cmds, fileCmds...)
+	}
+	if len(cmds) == 0 {
+		return nil, "", fmt.Errorf("no commands found in %s", file)
+	}
+	if len(cmds) > maxCommands {
+		return nil, "", fmt.Errorf("too many commands, at most %d can be run at once", maxCommands)
+	}
+
+	if match := messageFlagRegex.FindStringSubmatch(rest); match != nil {
+		msg = strings.Trim(match[2], `"'`)
+	}
+	return cmds, msg, nil
+}
+
+// readCommandsFile returns the commands in the file of the directory, one per line. Empty lines and lines
+// starting with # are skipped. Only a file name is accepted, so no other file of the host can be read.
+func readCommandsFile(dir, name string) ([]string, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("--commands-file is not enabled, set commands.filesDir in the snippet plugin config")
+	}
+	if name != filepath.Base(name) || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("--commands-file takes the name of a file in commands.filesDir")
+	}
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("commands file %s not found", name)
+		}
+		return nil, fmt.Errorf("while reading commands file %s: %v", name, err)
+	}
+	defer f.Close()
+
+	var cmds []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cmds = append(cmds, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("while reading commands file: %v", err)
+	}
+	return cmds, nil
+}
+
+func compileCommandPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(`^(?:` + pattern + `)(?:\s|$)`)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %v", pattern, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
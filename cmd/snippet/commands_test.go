@@ -0,0 +1,61 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestCheckCommand(t *testing.T) {
+	cfg := CommandsConfig{
+		Allow: []string{"kubectl", "helm", "grep"},
+		Deny:  []string{"kubectl (delete|exec)", "helm uninstall"},
+	}
+	tests := []struct {
+		name    string
+		cfg     CommandsConfig
+		cmd     string
+		wantErr bool
+		// unixOnly cases use sh syntax, PowerShell and cmd parse them differently
+		unixOnly bool
+	}{
+		{name: "unrestricted", cfg: CommandsConfig{}, cmd: "rm -rf / > out"},
+		{name: "allowed", cfg: cfg, cmd: "kubectl get pods -A"},
+		{name: "allowed pipe", cfg: cfg, cmd: "kubectl get pods -A | grep Error"},
+		{name: "denied", cfg: cfg, cmd: "kubectl delete pod app", wantErr: true},
+		{name: "denied with double space", cfg: cfg, cmd: "kubectl  delete pod app", wantErr: true},
+		{name: "denied with tab", cfg: cfg, cmd: "kubectl\tdelete pod app", wantErr: true},
+		{name: "denied with mixed whitespace", cfg: cfg, cmd: " kubectl \t exec app -- sh", wantErr: true},
+		{name: "denied after pipe with tab", cfg: cfg, cmd: "kubectl get pods |\tkubectl\t\tdelete pod app", wantErr: true},
+		{name: "allowed with tab", cfg: cfg, cmd: "kubectl\tget pods"},
+		{name: "not allowed", cfg: cfg, cmd: "curl http://example.com", wantErr: true},
+		{name: "not allowed after separator", cfg: cfg, cmd: "kubectl get pods; rm -rf /", wantErr: true},
+		{name: "not allowed after and", cfg: cfg, cmd: "kubectl get pods && rm -rf /", wantErr: true},
+		{name: "not allowed on next line", cfg: cfg, cmd: "kubectl get pods\nrm -rf /", wantErr: true},
+		{name: "prefix of an allowed name", cfg: cfg, cmd: "kubectlx get pods", wantErr: true},
+		{name: "command substitution", cfg: cfg, cmd: "kubectl get pod $(kubectl delete pod app)", wantErr: true},
+		{name: "backticks", cfg: cfg, cmd: "kubectl get pod `kubectl delete pod app`", wantErr: true},
+		{name: "subshell", cfg: cfg, cmd: "(kubectl delete pod app)", wantErr: true},
+		{name: "group", cfg: cfg, cmd: "{ kubectl delete pod app; }", wantErr: true},
+		{name: "redirection", cfg: cfg, cmd: "kubectl version > ~/.profile", wantErr: true},
+		{name: "input redirection", cfg: cfg, cmd: "kubectl apply -f - < /tmp/manifest", wantErr: true},
+		{name: "escaped name", cfg: cfg, cmd: `\kubectl delete pod app`, wantErr: true, unixOnly: true},
+		{name: "leading assignment", cfg: cfg, cmd: "A=1 kubectl delete pod app", wantErr: true},
+		{name: "quoted name", cfg: cfg, cmd: "'kubectl' delete pod app", wantErr: true},
+		{name: "partly quoted name", cfg: cfg, cmd: `kube"ctl" delete pod app`, wantErr: true},
+		{name: "variable name", cfg: cfg, cmd: "$KUBECTL delete pod app", wantErr: true},
+		{name: "glob name", cfg: cfg, cmd: "kubect? delete pod app", wantErr: true},
+		{name: "negation", cfg: cfg, cmd: "! kubectl delete pod app", wantErr: true},
+		{name: "invalid pattern", cfg: CommandsConfig{Allow: []string{"kubectl("}}, cmd: "kubectl get pods", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.unixOnly && runtime.GOOS == "windows" {
+				t.Skip("sh syntax")
+			}
+			err := checkCommand(tc.cfg, tc.cmd)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("checkCommand(%q) error = %v, wantErr %v", tc.cmd, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"regexp"
+)
+
+// gzipFlagRegex matches the --gzip flag.
+var gzipFlagRegex = regexp.MustCompile(`(^|\s)--gzip(\s|$)`)
+
+// cutGzipFlag returns whether the --gzip flag is set and the command without it.
+func cutGzipFlag(command string) (compress bool, rest string) {
+	head, tail := splitFlags(command)
+	match := gzipFlagRegex.FindStringSubmatchIndex(head)
+	if match == nil {
+		return false, command
+	}
+	return true, head[:match[0]] + head[match[4]:] + tail
+}
+
+// gzipContent compresses the output, so multi-megabyte results fit the upload limits of the platforms.
+func gzipContent(content string) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		return "", fmt.Errorf("while compressing the output: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("while compressing the output: %v", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"botkube.io/plugins-example/internal/azblobupload"
+	"botkube.io/plugins-example/internal/gcsupload"
+	"botkube.io/plugins-example/internal/s3upload"
+	"botkube.io/plugins-example/internal/teamsupload"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// commConfigPath is Botkube's communication config, read only when the plugin config has no bot token.
+	commConfigPath = "/config/comm_config.yaml"
+
+	// The env vars override the plugin config, e.g. with values from a Secret.
+	botTokenEnv         = "BOTKUBE_SNIPPET_BOT_TOKEN"
+	channelIDEnv        = "BOTKUBE_SNIPPET_CHANNEL_ID"
+	teamsAppPasswordEnv = "BOTKUBE_SNIPPET_TEAMS_APP_PASSWORD"
+	mattermostTokenEnv  = "BOTKUBE_SNIPPET_MATTERMOST_TOKEN"
+	discordTokenEnv     = "BOTKUBE_SNIPPET_DISCORD_TOKEN"
+	s3SecretKeyEnv      = "BOTKUBE_SNIPPET_S3_SECRET_ACCESS_KEY"
+	azureAccountKeyEnv  = "BOTKUBE_SNIPPET_AZURE_ACCOUNT_KEY"
+	githubTokenEnv      = "BOTKUBE_SNIPPET_GITHUB_TOKEN"
+)
+
+// Communication platforms files are delivered to.
+const (
+	platformSlack      = "slack"
+	platformTeams      = "teams"
+	platformMattermost = "mattermost"
+	platformDiscord    = "discord"
+	platformS3         = "s3"
+	platformGCS        = "gcs"
+	platformAzureBlob  = "azureblob"
+	platformGist       = "gist"
+)
+
+//go:embed config_schema.json
+var configJSONSchema string
+
+// Config holds the snippet executor configuration.
+type Config struct {
+	// BotToken is the Slack bot token used to upload snippets, it needs the files:write scope.
+	BotToken string `yaml:"botToken"`
+	// ChannelID is the channel snippets are shared in when the channel of the command cannot be resolved.
+	ChannelID string `yaml:"channelID"`
+	// Channels maps names of channels results can be sent to with -ch to their IDs, e.g. reports: C0123456789.
+	Channels map[string]string `yaml:"channels"`
+	// DM sends results to the requesting user in a direct message by default, as with the -dm flag.
+	DM bool `yaml:"dm"`
+	// Commands restricts the commands that can be run.
+	Commands CommandsConfig `yaml:"commands"`
+	// KubectlOnly disables the shell: only kubectl and Binaries are run, directly, so pipes and other
+	// shell syntax are not interpreted.
+	KubectlOnly bool `yaml:"kubectlOnly"`
+	// Timeout limits commands not run with -timeout, 5m by default.
+	Timeout time.Duration `yaml:"timeout"`
+	// StreamInterval is how often the progress message of --stream is edited, 10s by default.
+	StreamInterval time.Duration `yaml:"streamInterval"`
+	// Binaries lists binaries other than kubectl allowed in KubectlOnly mode, looked up in PATH, e.g. helm.
+	Binaries []string `yaml:"binaries"`
+	// Dependencies are binaries downloaded by the plugin, by name, e.g. jq or istioctl. They are added to PATH
+	// of the commands naming them.
+	Dependencies map[string]DependencyConfig `yaml:"dependencies"`
+	// Env are env vars of the executed commands, by name. A value is a literal, e.g. API_URL: https://...,
+	// or an EnvSource reading a Secret key, e.g. TOKEN: {secretKeyRef: {name: api, key: token}}, which is
+	// only set for presets.
+	Env map[string]interface{} `yaml:"env"`
+	// Presets are commands run by name with snippet preset <name>, e.g. common diagnostics.
+	Presets map[string]PresetConfig `yaml:"presets"`
+	// Platform is slack, teams, mattermost, discord, gist, or one of the storage backends s3, gcs and azureblob.
+	// When empty, it is selected from the configured credentials.
+	Platform string `yaml:"platform"`
+	// Teams configures delivery to Microsoft Teams.
+	Teams TeamsConfig `yaml:"teams"`
+	// Mattermost configures delivery to Mattermost.
+	Mattermost MattermostConfig `yaml:"mattermost"`
+	// Discord configures delivery to Discord.
+	Discord DiscordConfig `yaml:"discord"`
+	// S3 configures delivery of presigned links to files stored in an S3 bucket.
+	S3 S3Config `yaml:"s3"`
+	// GCS configures delivery of signed links to files stored in a Google Cloud Storage bucket.
+	GCS GCSConfig `yaml:"gcs"`
+	// AzureBlob configures delivery of SAS links to files stored in an Azure Blob Storage container.
+	AzureBlob AzureBlobConfig `yaml:"azureBlob"`
+	// Gist configures delivery as GitHub Gists.
+	Gist GistConfig `yaml:"gist"`
+	// CommunicationGroup is the group in Botkube's communication config the token and channel are read from
+	// when BotToken is not set. Deprecated: mounting the communication config doesn't work on Botkube Cloud.
+	CommunicationGroup string `yaml:"communicationGroup,omitempty"`
+}
+
+// CommandsConfig holds patterns of allowed and denied commands. Each command of a command line, e.g. both sides
+// of a pipe, must match an allow pattern, when there are any, and no deny pattern.
+type CommandsConfig struct {
+	// Allow lists patterns of allowed commands, e.g. kubectl, helm and istioctl. When empty, all commands are allowed.
+	Allow []string `yaml:"allow"`
+	// Deny lists patterns of denied commands, e.g. kubectl delete. They take precedence over Allow. They are
+	// advisory, a command can be written in ways they don't match, e.g. kubectl -n apps delete.
+	Deny []string `yaml:"deny"`
+	// FilesDir is the directory of the files --commands-file reads by name, e.g. a mounted ConfigMap.
+	// The flag is refused when it is empty.
+	FilesDir string `yaml:"filesDir"`
+}
+
+// TeamsConfig holds the Azure AD app and the channel files are uploaded to.
+type TeamsConfig struct {
+	// TenantID is the Azure AD tenant of the app.
+	TenantID string `yaml:"tenantID"`
+	// AppID and AppPassword are the client ID and secret of the app, it needs the Files.ReadWrite.All
+	// application permission. When not set, the Botkube Teams app of the communication group is used.
+	AppID       string `yaml:"appID"`
+	AppPassword string `yaml:"appPassword"`
+	// TeamID and ChannelID select the channel whose files folder the results are uploaded to.
+	TeamID    string `yaml:"teamID"`
+	ChannelID string `yaml:"channelID"`
+}
+
+// MattermostConfig holds the Mattermost server and the channel files are shared in.
+type MattermostConfig struct {
+	// URL of the Mattermost server, e.g. https://mattermost.example.com.
+	URL string `yaml:"url"`
+	// Token is the access token of the bot. When not set, the Botkube bot of the communication group is used.
+	Token string `yaml:"token"`
+	// ChannelID is the channel files are shared in, unless another one is requested with -ch.
+	ChannelID string `yaml:"channelID"`
+}
+
+// DiscordConfig holds the bot and the channel files are posted in.
+type DiscordConfig struct {
+	// Token is the bot token. When not set, the Botkube bot of the communication group is used.
+	Token string `yaml:"token"`
+	// ChannelID is the channel files are posted in, unless another one is requested with -ch.
+	ChannelID string `yaml:"channelID"`
+}
+
+// S3Config holds the bucket files are stored in. Without an access key, the default AWS credential chain is used,
+// e.g. the IAM role of the Botkube service account.
+type S3Config struct {
+	Bucket string `yaml:"bucket"`
+	Region string `yaml:"region"`
+	// Endpoint of S3-compatible storage, e.g. MinIO.
+	Endpoint string `yaml:"endpoint"`
+	// Prefix of the object keys, e.g. snippets/.
+	Prefix          string `yaml:"prefix"`
+	AccessKeyID     string `yaml:"accessKeyID"`
+	SecretAccessKey string `yaml:"secretAccessKey"`
+	// LinkExpiry is the validity of the presigned links, 24h by default and at most 7 days.
+	LinkExpiry time.Duration `yaml:"linkExpiry"`
+}
+
+// GCSConfig holds the bucket files are stored in. Without a credentials file, Application Default Credentials
+// are used, e.g. GKE Workload Identity.
+type GCSConfig struct {
+	Bucket string `yaml:"bucket"`
+	// Prefix of the object names, e.g. snippets/.
+	Prefix          string `yaml:"prefix"`
+	CredentialsFile string `yaml:"credentialsFile"`
+	// ServiceAccount is the email links are signed as, detected from the credentials when empty.
+	ServiceAccount string `yaml:"serviceAccount"`
+	// LinkExpiry is the validity of the signed links, 24h by default and at most 7 days.
+	LinkExpiry time.Duration `yaml:"linkExpiry"`
+}
+
+// AzureBlobConfig holds the container files are stored in. Without an account key, Azure AD Workload Identity
+// of the Botkube pod is used.
+type AzureBlobConfig struct {
+	AccountName string `yaml:"accountName"`
+	Container   string `yaml:"container"`
+	// Prefix of the blob names, e.g. snippets/.
+	Prefix     string `yaml:"prefix"`
+	AccountKey string `yaml:"accountKey"`
+	// Endpoint of the Blob service, e.g. for sovereign clouds.
+	Endpoint string `yaml:"endpoint"`
+	// LinkExpiry is the validity of the SAS links, 24h by default and at most 7 days.
+	LinkExpiry time.Duration `yaml:"linkExpiry"`
+}
+
+// GistConfig holds the GitHub token Gists are created with.
+type GistConfig struct {
+	// Token needs the gist scope, or the Gists write permission of a fine-grained token.
+	Token string `yaml:"token"`
+	// Public creates public Gists, by default they are secret and only found with the link.
+	Public bool `yaml:"public"`
+	// APIURL is the API of GitHub Enterprise Server, e.g. https://github.example.com/api/v3.
+	APIURL string `yaml:"apiURL"`
+}
+
+// DependencyConfig holds the download URLs of a binary.
+type DependencyConfig struct {
+	// URLs of the binary by platform, e.g. linux/amd64, as the URLs of Botkube plugin dependencies: archives are
+	// extracted, with the directory of the binary given after //, and ?checksum=sha256:... verifies the download.
+	URLs map[string]string `yaml:"urls"`
+}
+
+// PresetConfig holds a command run with snippet preset <name>.
+type PresetConfig struct {
+	// Command is the command line, run the same way as the one of -c.
+	Command string `yaml:"command"`
+	// Description tells what the preset does in the list of presets, it is the message of the result unless -m is given.
+	Description string `yaml:"description"`
+	// Filename is the filename template of the result unless -f is given.
+	Filename string `yaml:"filename"`
+}
+
+// commConfig is the part of Botkube's communication config holding the settings of the supported platforms.
+type commConfig struct {
+	Communications map[string]commGroup `yaml:"communications"`
+}
+
+type commGroup struct {
+	SocketSlack struct {
+		Enabled  bool   `yaml:"enabled"`
+		BotToken string `yaml:"botToken"`
+		Channels map[string]struct {
+			ID string `yaml:"id"`
+		} `yaml:"channels"`
+	} `yaml:"socketSlack"`
+	Teams struct {
+		Enabled     bool   `yaml:"enabled"`
+		AppID       string `yaml:"appID"`
+		AppPassword string `yaml:"appPassword"`
+	} `yaml:"teams"`
+	Mattermost struct {
+		Enabled bool   `yaml:"enabled"`
+		URL     string `yaml:"url"`
+		Token   string `yaml:"token"`
+	} `yaml:"mattermost"`
+	Discord struct {
+		Enabled bool   `yaml:"enabled"`
+		Token   string `yaml:"token"`
+	} `yaml:"discord"`
+}
+
+// selectPlatform returns the platform files are delivered to. Without an explicit platform, a storage backend
+// is used when its bucket or container is configured, Gists when a GitHub token is configured and Slack when
+// a bot token is configured, otherwise
+// the platform configured in the plugin config or, as a fallback, the only platform enabled in the communication group.
+func selectPlatform(cfg Config) (string, error) {
+	switch cfg.Platform {
+	case platformSlack, platformTeams, platformMattermost, platformDiscord, platformS3, platformGCS, platformAzureBlob, platformGist:
+		return cfg.Platform, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported platform %q, use %s", cfg.Platform, strings.Join([]string{
+			platformSlack, platformTeams, platformMattermost, platformDiscord, platformS3, platformGCS, platformAzureBlob, platformGist,
+		}, ", "))
+	}
+
+	switch {
+	case cfg.S3.Bucket != "":
+		return platformS3, nil
+	case cfg.GCS.Bucket != "":
+		return platformGCS, nil
+	case cfg.AzureBlob.AccountName != "":
+		return platformAzureBlob, nil
+	case envOr(githubTokenEnv, cfg.Gist.Token) != "":
+		return platformGist, nil
+	case envOr(botTokenEnv, cfg.BotToken) != "":
+		return platformSlack, nil
+	case cfg.Teams.TeamID != "":
+		return platformTeams, nil
+	case cfg.Mattermost.URL != "":
+		return platformMattermost, nil
+	case envOr(discordTokenEnv, cfg.Discord.Token) != "" || cfg.Discord.ChannelID != "":
+		return platformDiscord, nil
+	}
+	if cfg.CommunicationGroup == "" {
+		return platformSlack, nil
+	}
+	group, err := readCommGroup(cfg.CommunicationGroup)
+	if err != nil || group.SocketSlack.Enabled {
+		return platformSlack, nil
+	}
+	var enabled []string
+	for platform, on := range map[string]bool{
+		platformTeams:      group.Teams.Enabled,
+		platformMattermost: group.Mattermost.Enabled,
+		platformDiscord:    group.Discord.Enabled,
+	} {
+		if on {
+			enabled = append(enabled, platform)
+		}
+	}
+	if len(enabled) == 1 {
+		return enabled[0], nil
+	}
+	return platformSlack, nil
+}
+
+// mattermostCredentials returns the server URL and bot token, falling back to the Botkube Mattermost bot.
+func mattermostCredentials(cfg Config) (serverURL, token string, err error) {
+	serverURL = cfg.Mattermost.URL
+	token = envOr(mattermostTokenEnv, cfg.Mattermost.Token)
+	if (serverURL == "" || token == "") && cfg.CommunicationGroup != "" {
+		group, err := readCommGroup(cfg.CommunicationGroup)
+		if err != nil {
+			return "", "", err
+		}
+		if serverURL == "" {
+			serverURL = group.Mattermost.URL
+		}
+		if token == "" {
+			token = group.Mattermost.Token
+		}
+	}
+	if serverURL == "" || token == "" {
+		return "", "", fmt.Errorf("mattermost.url and mattermost.token are not configured")
+	}
+	return serverURL, token, nil
+}
+
+// teamsCredentials returns the Azure AD app used to upload files, falling back to the Botkube Teams app.
+func teamsCredentials(cfg Config) (teamsupload.Credentials, error) {
+	creds := teamsupload.Credentials{
+		TenantID:    cfg.Teams.TenantID,
+		AppID:       cfg.Teams.AppID,
+		AppPassword: envOr(teamsAppPasswordEnv, cfg.Teams.AppPassword),
+	}
+	if (creds.AppID == "" || creds.AppPassword == "") && cfg.CommunicationGroup != "" {
+		group, err := readCommGroup(cfg.CommunicationGroup)
+		if err != nil {
+			return teamsupload.Credentials{}, err
+		}
+		if creds.AppID == "" {
+			creds.AppID = group.Teams.AppID
+		}
+		if creds.AppPassword == "" {
+			creds.AppPassword = group.Teams.AppPassword
+		}
+	}
+	if creds.TenantID == "" || creds.AppID == "" || creds.AppPassword == "" {
+		return teamsupload.Credentials{}, fmt.Errorf("teams.tenantID, teams.appID and teams.appPassword are not configured")
+	}
+	return creds, nil
+}
+
+// s3Options returns the bucket and the static credentials, if any, the file is uploaded with.
+func s3Options(cfg Config) s3upload.Options {
+	return s3upload.Options{
+		Bucket:          cfg.S3.Bucket,
+		Region:          cfg.S3.Region,
+		Endpoint:        cfg.S3.Endpoint,
+		AccessKeyID:     cfg.S3.AccessKeyID,
+		SecretAccessKey: envOr(s3SecretKeyEnv, cfg.S3.SecretAccessKey),
+	}
+}
+
+// gcsOptions returns the bucket and the credentials the file is uploaded with.
+func gcsOptions(cfg Config) gcsupload.Options {
+	return gcsupload.Options{
+		Bucket:          cfg.GCS.Bucket,
+		CredentialsFile: cfg.GCS.CredentialsFile,
+		ServiceAccount:  cfg.GCS.ServiceAccount,
+	}
+}
+
+// azureBlobOptions returns the container and the account key, if any, the file is uploaded with.
+func azureBlobOptions(cfg Config) azblobupload.Options {
+	return azblobupload.Options{
+		AccountName: cfg.AzureBlob.AccountName,
+		Container:   cfg.AzureBlob.Container,
+		AccountKey:  envOr(azureAccountKeyEnv, cfg.AzureBlob.AccountKey),
+		Endpoint:    cfg.AzureBlob.Endpoint,
+	}
+}
+
+// slackCredentials returns the bot token and the fallback channel ID, which may be empty. Env vars take precedence
+// over the plugin config, Botkube's communication config is only read as a fallback for what is still missing.
+func slackCredentials(cfg Config) (botToken, channelID string, err error) {
+	botToken = envOr(botTokenEnv, cfg.BotToken)
+	channelID = envOr(channelIDEnv, cfg.ChannelID)
+	if botToken != "" && (channelID != "" || cfg.CommunicationGroup == "") {
+		return botToken, channelID, nil
+	}
+	if cfg.CommunicationGroup == "" {
+		return "", "", fmt.Errorf("botToken is not configured, set it in the plugin config or with %s", botTokenEnv)
+	}
+
+	fileToken, fileChannelID, err := readCommConfig(cfg.CommunicationGroup)
+	if err != nil {
+		if botToken == "" {
+			return "", "", err
+		}
+		// the channel of the command is used instead
+		fmt.Fprintf(os.Stderr, "failed to read the fallback channel: %v", err)
+		return botToken, channelID, nil
+	}
+	if botToken == "" {
+		botToken = fileToken
+	}
+	if channelID == "" {
+		channelID = fileChannelID
+	}
+	if botToken == "" {
+		return "", "", fmt.Errorf("communication group %s has no Socket Slack bot token", cfg.CommunicationGroup)
+	}
+	return botToken, channelID, nil
+}
+
+// readCommConfig returns the bot token and default channel ID of the group from Botkube's communication config.
+func readCommConfig(group string) (string, string, error) {
+	config, err := readCommGroup(group)
+	if err != nil {
+		return "", "", err
+	}
+	return config.SocketSlack.BotToken, config.SocketSlack.Channels["default"].ID, nil
+}
+
+// readCommGroup returns the group from Botkube's communication config.
+func readCommGroup(group string) (commGroup, error) {
+	data, err := os.ReadFile(commConfigPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return commGroup{}, fmt.Errorf("credentials are not configured and %s is not mounted", commConfigPath)
+		}
+		return commGroup{}, fmt.Errorf("error reading YAML file: %v", err)
+	}
+	var config commConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return commGroup{}, fmt.Errorf("error parsing YAML file: %v", err)
+	}
+	communications, exists := config.Communications[group]
+	if !exists {
+		return commGroup{}, fmt.Errorf("communication group %s not found in %s", group, commConfigPath)
+	}
+	return communications, nil
+}
+
+// envOr returns the value of the env var or def when it is empty.
+func envOr(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
+
+// discordToken returns the bot token, falling back to the Botkube Discord bot.
+func discordToken(cfg Config) (string, error) {
+	token := envOr(discordTokenEnv, cfg.Discord.Token)
+	if token == "" && cfg.CommunicationGroup != "" {
+		group, err := readCommGroup(cfg.CommunicationGroup)
+		if err != nil {
+			return "", err
+		}
+		token = group.Discord.Token
+	}
+	if token == "" {
+		return "", fmt.Errorf("discord.token is not configured")
+	}
+	return token, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+// conversions are the --convert formats, mapped to the file type of the converted output.
+var conversions = map[string]string{
+	"yaml":  "yaml",
+	"csv":   "csv",
+	"table": "txt",
+}
+
+// convertFlagRegex matches the --convert flag, e.g. --convert csv.
+var convertFlagRegex = regexp.MustCompile(`(^|\s)--convert\s+(\S+)`)
+
+// cutConvertFlag returns the format of the --convert flag and the command without it.
+func cutConvertFlag(command string) (string, string) {
+	format, rest := cutQuotedFlag(convertFlagRegex, command)
+	return strings.ToLower(format), rest
+}
+
+// convertValues converts JSON values to YAML documents, or to the rows of a CSV file or a table.
+func convertValues(format string, values []interface{}) (string, error) {
+	if format == "yaml" {
+		docs := make([]string, 0, len(values))
+		for _, v := range values {
+			data, err := yaml.Marshal(v)
+			if err != nil {
+				return "", fmt.Errorf("while converting to YAML: %v", err)
+			}
+			docs = append(docs, string(data))
+		}
+		return strings.Join(docs, "---\n"), nil
+	}
+
+	columns, rows := tabulate(values)
+	var b strings.Builder
+	if format == "csv" {
+		w := csv.NewWriter(&b)
+		records := make([][]string, 0, len(rows)+1)
+		records = append(records, columns)
+		for _, row := range rows {
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				record[i] = row[column]
+			}
+			records = append(records, record)
+		}
+		if err := w.WriteAll(records); err != nil {
+			return "", fmt.Errorf("while converting to CSV: %v", err)
+		}
+		return b.String(), nil
+	}
+
+	w := tabwriter.NewWriter(&b, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = row[column]
+			if cells[i] == "" {
+				cells[i] = "<none>"
+			}
+			// tabs and line breaks would break the alignment
+			cells[i] = strings.Join(strings.Fields(cells[i]), " ")
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("while converting to a table: %v", err)
+	}
+	return b.String(), nil
+}
+
+// tabulate returns a row for each value, with a column for each scalar field, named by its path, e.g.
+// metadata.name. The items of kubectl lists and the elements of arrays are rows of their own.
+func tabulate(values []interface{}) ([]string, []map[string]string) {
+	var rows []map[string]string
+	seen := map[string]bool{}
+	var columns []string
+	for _, v := range values {
+		for _, item := range rowItems(v) {
+			row := map[string]string{}
+			flatten(row, "", item)
+			for column := range row {
+				if !seen[column] {
+					seen[column] = true
+					columns = append(columns, column)
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	slices.Sort(columns)
+	return columns, rows
+}
+
+// rowItems returns the items of a kubectl list or the elements of an array, or the value itself.
+func rowItems(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		if kind, ok := v["kind"].(string); ok && strings.HasSuffix(kind, "List") {
+			if items, ok := v["items"].([]interface{}); ok {
+				return items
+			}
+		}
+	}
+	return []interface{}{v}
+}
+
+// flatten sets the scalar fields of v in row. Arrays of scalars are joined with commas, other arrays
+// are indexed, e.g. spec.containers.0.name.
+func flatten(row map[string]string, path string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			flatten(row, joinPath(path, key), value)
+		}
+	case []interface{}:
+		if scalars, ok := scalarStrings(v); ok {
+			row[columnName(path)] = strings.Join(scalars, ",")
+			return
+		}
+		for i, value := range v {
+			flatten(row, joinPath(path, strconv.Itoa(i)), value)
+		}
+	default:
+		row[columnName(path)] = scalarString(v)
+	}
+}
+
+func scalarStrings(values []interface{}) ([]string, bool) {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, false
+		}
+		out = append(out, scalarString(v))
+	}
+	return out, true
+}
+
+func scalarString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// columnName names the column of a path, a scalar value has no path.
+func columnName(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
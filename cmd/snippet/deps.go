@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-getter"
+	"github.com/kubeshop/botkube/pkg/plugin"
+)
+
+var (
+	// dependencyNameRegex matches valid names of configured dependencies, they are binary names.
+	dependencyNameRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	// commandWordRegex matches the words of a command that may name a dependency.
+	commandWordRegex = regexp.MustCompile(`[A-Za-z0-9._-]+`)
+
+	// dependenciesMu serializes downloads, so concurrent commands don't download the same binary twice.
+	dependenciesMu sync.Mutex
+)
+
+// installDependencies downloads the configured dependencies named in the command, unless they were downloaded
+// before, and returns their paths by name. Dependencies are stored in the temp dir, by the hash of their URL,
+// so a changed URL, e.g. of a new version, is downloaded again.
+func installDependencies(ctx context.Context, deps map[string]DependencyConfig, cmd string) (map[string]string, error) {
+	if len(deps) == 0 {
+		return nil, nil
+	}
+	named := map[string]bool{}
+	for _, word := range commandWordRegex.FindAllString(cmd, -1) {
+		named[word] = true
+	}
+
+	paths := map[string]string{}
+	for name, dep := range deps {
+		if !named[name] {
+			continue
+		}
+		path, err := installDependency(ctx, name, dep)
+		if err != nil {
+			return nil, err
+		}
+		paths[name] = path
+	}
+	return paths, nil
+}
+
+func installDependency(ctx context.Context, name string, dep DependencyConfig) (string, error) {
+	if !dependencyNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid dependency name %q, use the name of its binary", name)
+	}
+	if dependencies[name] {
+		return "", fmt.Errorf("dependency %s is bundled with the plugin, remove it from dependencies", name)
+	}
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	src, ok := dep.URLs[platform]
+	if !ok {
+		return "", fmt.Errorf("dependency %s has no URL for %s", name, platform)
+	}
+
+	sum := sha256.Sum256([]byte(src))
+	dir := filepath.Join(os.TempDir(), "snippet-dependencies", hex.EncodeToString(sum[:8]))
+	bin := filepath.Join(dir, name+exeSuffix)
+
+	dependenciesMu.Lock()
+	defer dependenciesMu.Unlock()
+	if _, err := os.Stat(bin); err == nil {
+		return bin, nil
+	}
+	if err := download(ctx, filepath.Base(bin), src, dir); err != nil {
+		return "", fmt.Errorf("while downloading dependency %s: %v", name, err)
+	}
+	return bin, nil
+}
+
+// download stores the binary of the URL as dir/name. A single file is saved under the name, an archive is
+// extracted and must have the binary in the directory selected after //.
+func download(ctx context.Context, name, src, dir string) error {
+	parsedURL, err := url.Parse(src)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %v", src, err)
+	}
+	query := parsedURL.Query()
+	query.Set("filename", name)
+	parsedURL.RawQuery = query.Encode()
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	tmp := dir + ".downloading"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	client := &getter.Client{
+		Ctx:  ctx,
+		Src:  parsedURL.String(),
+		Dst:  tmp,
+		Pwd:  pwd,
+		Mode: getter.ClientModeAny,
+	}
+	if err := client.Get(); err != nil {
+		return err
+	}
+
+	if stat, err := os.Stat(tmp); err == nil && !stat.IsDir() {
+		// a single file may be stored as the destination itself
+		if err := os.MkdirAll(tmp+".d", 0o755); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, filepath.Join(tmp+".d", name)); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp+".d", tmp); err != nil {
+			return err
+		}
+	}
+	if err := os.Chmod(filepath.Join(tmp, name), 0o755); err != nil {
+		return fmt.Errorf("no %s binary in the download, for archives, give its directory after //: %v", name, err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
+
+// dependencyPath returns the path of a binary downloaded by Botkube, or the name to look it up in PATH
+// when the dependency directory is not set. Binaries have the .exe extension on Windows.
+func dependencyPath(name string) string {
+	if dir := os.Getenv(plugin.DependencyDirEnvName); dir != "" {
+		return filepath.Join(dir, name+exeSuffix)
+	}
+	return name
+}
+
+// dependencyEnv returns the environment of the plugin with the directories of the dependencies prepended
+// to PATH, or nil, to keep the environment, without dependencies.
+func dependencyEnv(paths map[string]string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	var dirs []string
+	for _, path := range paths {
+		dirs = append(dirs, filepath.Dir(path))
+	}
+
+	env := os.Environ()
+	for i, kv := range env {
+		if value, ok := strings.CutPrefix(kv, "PATH="); ok {
+			env[i] = "PATH=" + strings.Join(append(dirs, value), string(os.PathListSeparator))
+			return env
+		}
+	}
+	return append(env, "PATH="+strings.Join(dirs, string(os.PathListSeparator)))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"botkube.io/plugins-example/internal/discordupload"
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// sendToDiscord posts the file as a message attachment in the configured channel, or the channel requested with -ch.
+func sendToDiscord(ctx context.Context, cfg Config, req snippetRequest, files []snippetFile) (executor.ExecuteOutput, error) {
+	if req.DM {
+		return executor.ExecuteOutput{}, fmt.Errorf("-dm is not supported on Discord")
+	}
+	token, err := discordToken(cfg)
+	if err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+	channelID := cfg.Discord.ChannelID
+	if req.Channel != "" {
+		channelID, err = resolveChannel(cfg.Channels, req.Channel)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+	}
+	if channelID == "" {
+		return executor.ExecuteOutput{}, fmt.Errorf("discord.channelID is not configured")
+	}
+
+	attachments := make([]discordupload.File, 0, len(files))
+	for _, file := range files {
+		attachments = append(attachments, discordupload.File{Name: file.Name, Content: file.Content})
+	}
+
+	filename := fileNames(files)
+	message := fmt.Sprintf("Command %s result sent, please check attachement with the following name: %s", req.Cmd, filename)
+	if req.Msg != "" {
+		message = fmt.Sprintf("%s please check attachement with the following name: %s", req.Msg, filename)
+	}
+	if _, err := discordupload.Upload(ctx, token, channelID, message, attachments...); err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(fmt.Sprintf("Command %s result sent, please check attachement with the following name: %s", req.Cmd, filename), false),
+	}, nil
+}
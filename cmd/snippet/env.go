@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// envNameRegex matches valid names of env vars of the config.
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvSource is an env var value read from Kubernetes, the form of the values of Config.Env that are not literals.
+type EnvSource struct {
+	SecretKeyRef *SecretKeyRef `yaml:"secretKeyRef"`
+}
+
+// SecretKeyRef selects a key of a Secret.
+type SecretKeyRef struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+	// Namespace of the Secret, the namespace of the kubeconfig of the execution when empty.
+	Namespace string `yaml:"namespace"`
+}
+
+// commandEnv returns the env vars of the config as NAME=value, sorted by name. Secret keys are read with kubectl
+// and the kubeconfig of the execution, right before the command runs, so a rotated Secret is picked up.
+func commandEnv(ctx context.Context, env map[string]interface{}, kubeConfig []byte) ([]string, error) {
+	if len(env) == 0 {
+		return nil, nil
+	}
+	secrets := map[string]*corev1.Secret{}
+	vars := make([]string, 0, len(env))
+	for name, value := range env {
+		if !envNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("invalid env var name %q", name)
+		}
+		ref, literal, err := parseEnvValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of env var %s: %v", name, err)
+		}
+		if ref == nil {
+			vars = append(vars, name+"="+literal)
+			continue
+		}
+
+		id := ref.Namespace + "/" + ref.Name
+		secret, ok := secrets[id]
+		if !ok {
+			secret, err = getSecret(ctx, kubeConfig, ref.Namespace, ref.Name)
+			if err != nil {
+				return nil, fmt.Errorf("while reading env var %s: %v", name, err)
+			}
+			secrets[id] = secret
+		}
+		data, ok := secret.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("while reading env var %s: secret %s has no key %s", name, ref.Name, ref.Key)
+		}
+		vars = append(vars, name+"="+string(data))
+	}
+	slices.Sort(vars)
+	return vars, nil
+}
+
+// literalEnv returns the env vars of the config without the ones reading a Secret key. Any command could print
+// them, e.g. snippet -c env, so Secret keys are only set for presets, whose commands are fixed by the config.
+func literalEnv(env map[string]interface{}) map[string]interface{} {
+	literals := map[string]interface{}{}
+	for name, value := range env {
+		// invalid values are kept, so commandEnv reports them
+		if ref, _, err := parseEnvValue(value); err == nil && ref != nil {
+			continue
+		}
+		literals[name] = value
+	}
+	return literals
+}
+
+// parseEnvValue returns the Secret key of an env var value, or its literal value. Numbers and booleans,
+// e.g. PORT: 8080, are literals too.
+func parseEnvValue(value interface{}) (*SecretKeyRef, string, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, "", nil
+	case string:
+		return nil, value, nil
+	case map[string]interface{}, map[interface{}]interface{}:
+	default:
+		return nil, fmt.Sprint(value), nil
+	}
+
+	// the merged config holds the value as a map, it is decoded the way the config is
+	raw, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, "", err
+	}
+	var source EnvSource
+	if err := yaml.UnmarshalStrict(raw, &source); err != nil {
+		return nil, "", err
+	}
+	ref := source.SecretKeyRef
+	if ref == nil || ref.Name == "" || ref.Key == "" {
+		return nil, "", fmt.Errorf("secretKeyRef with a name and a key is required")
+	}
+	return ref, "", nil
+}
+
+// getSecret reads the Secret with kubectl, so the plugin needs no client of its own.
+func getSecret(ctx context.Context, kubeConfig []byte, namespace, name string) (*corev1.Secret, error) {
+	cmd := "kubectl get secret " + name + " -o json"
+	if namespace != "" {
+		cmd += " -n " + namespace
+	}
+	result, err := executeWithKubeConfig(ctx, cmd, kubeConfig, dependencyPath("kubectl"), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("while getting secret %s: %s", name, strings.TrimSpace(result.Stderr))
+	}
+
+	var secret corev1.Secret
+	if err := json.Unmarshal([]byte(result.Stdout), &secret); err != nil {
+		return nil, fmt.Errorf("while unmarshalling secret %s: %v", name, err)
+	}
+	return &secret, nil
+}
+
+// withEnv returns env, or the environment of the plugin when it is nil, with the vars appended.
+// Later vars take precedence, so the vars override the environment of the plugin.
+func withEnv(env, vars []string) []string {
+	if len(vars) == 0 {
+		return env
+	}
+	if env == nil {
+		env = os.Environ()
+	}
+	return append(env, vars...)
+}
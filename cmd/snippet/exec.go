@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/plugin"
+	"github.com/mattn/go-shellwords"
+)
+
+// dependencies are the binaries downloaded by Botkube, their commands are run with the kubeconfig of the execution.
+var dependencies = map[string]bool{
+	"kubectl": true,
+	"helm":    true,
+}
+
+// executeCommand runs kubectl and helm commands with the kubeconfig of the execution and other commands in a shell,
+// sh, or PowerShell on Windows.
+// In kubectlOnly mode, there is no shell: only kubectl and the configured binaries are run, directly.
+// A command exiting with a non-zero code is not an error, its exit code and stderr are in the result.
+// Configured dependencies named in the command are downloaded first and added to PATH, and the env vars
+// of the config are set. The output is also written to live as it is produced, if it is set.
+func executeCommand(ctx context.Context, cfg Config, cmd string, kubeConfig []byte, live io.Writer) (commandResult, error) {
+	tools, err := installDependencies(ctx, cfg.Dependencies, cmd)
+	if err != nil {
+		return commandResult{}, err
+	}
+	vars, err := commandEnv(ctx, cfg.Env, kubeConfig)
+	if err != nil {
+		return commandResult{}, err
+	}
+	env := withEnv(dependencyEnv(tools), vars)
+
+	if cfg.KubectlOnly {
+		bin, err := allowedBinary(cfg.Binaries, cmd)
+		if err != nil {
+			return commandResult{}, err
+		}
+		// other binaries are looked up in PATH
+		path := bin
+		if dependencies[bin] {
+			path = dependencyPath(bin)
+		} else if tool, ok := tools[bin]; ok {
+			path = tool
+		}
+		return executeWithKubeConfig(ctx, cmd, kubeConfig, path, env, live)
+	}
+
+	if bin, _, _ := strings.Cut(strings.TrimSpace(cmd), " "); dependencies[bin] {
+		return executeWithKubeConfig(ctx, cmd, kubeConfig, dependencyPath(bin), env, live)
+	}
+
+	c := shellCommand(ctx, cmd)
+	c.Env = env
+	// killing only the shell would leave the commands it started running
+	killProcessGroup(c)
+	result, err := run(c, live)
+	if err != nil {
+		return result, fmt.Errorf("failed to run command %s: %v", cmd, err)
+	}
+	return result, nil
+}
+
+// executeWithKubeConfig runs the command without a shell, with KUBECONFIG set to the kubeconfig of the execution.
+// The arguments are parsed the same way as by plugin.ExecuteCommand, the binary is run from path.
+// The environment of the plugin is used when env is nil.
+func executeWithKubeConfig(ctx context.Context, cmd string, kubeConfig []byte, path string, env []string, live io.Writer) (commandResult, error) {
+	parser := shellwords.NewParser()
+	parser.ParseEnv = false
+	parser.ParseBacktick = false
+	args, err := parser.Parse(cmd)
+	if err != nil {
+		return commandResult{}, fmt.Errorf("while parsing command %s: %v", cmd, err)
+	}
+	if len(args) == 0 {
+		return commandResult{}, fmt.Errorf("invalid raw command: %q", cmd)
+	}
+	kubeConfigPath, deleteFn, err := plugin.PersistKubeConfig(ctx, kubeConfig)
+	if err != nil {
+		return commandResult{}, fmt.Errorf("error writing kubeconfig file: %v", err)
+	}
+	defer func() {
+		if deleteErr := deleteFn(ctx); deleteErr != nil {
+			fmt.Fprintf(os.Stderr, "failed to delete kubeconfig file %s: %v", kubeConfigPath, deleteErr)
+		}
+	}()
+
+	if env == nil {
+		env = os.Environ()
+	}
+	c := exec.CommandContext(ctx, path, args[1:]...)
+	c.Env = append(env, "KUBECONFIG="+kubeConfigPath)
+	if args[0] == "helm" {
+		c.Env = append(c.Env, helmEnv()...)
+	}
+	// e.g. credential plugins started by kubectl
+	killProcessGroup(c)
+	result, err := run(c, live)
+	if err != nil {
+		return result, fmt.Errorf("failed to run command %s: %v", cmd, err)
+	}
+	return result, nil
+}
+
+// helmEnv keeps the helm cache, config and data in the temp dir, as the home dir of the plugin may not be writable.
+// Directories set in the environment of the plugin are kept.
+func helmEnv() []string {
+	dir := filepath.Join(os.TempDir(), "helm")
+	var env []string
+	for name, sub := range map[string]string{
+		"HELM_CACHE_HOME":  ".cache",
+		"HELM_CONFIG_HOME": ".config",
+		"HELM_DATA_HOME":   ".local/share",
+	} {
+		if os.Getenv(name) == "" {
+			env = append(env, name+"="+filepath.Join(dir, sub))
+		}
+	}
+	return env
+}
+
+// run runs the command and returns its output. Only a command that couldn't be run is an error,
+// the partial output of a killed one, e.g. after a timeout, is kept.
+func run(c *exec.Cmd, live io.Writer) (commandResult, error) {
+	var stdout, stderr bytes.Buffer
+	c.Stdout, c.Stderr = &stdout, &stderr
+	if live != nil {
+		c.Stdout, c.Stderr = io.MultiWriter(&stdout, live), io.MultiWriter(&stderr, live)
+	}
+	c.WaitDelay = waitDelay
+
+	err := c.Run()
+	result := commandResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: c.ProcessState.ExitCode(),
+	}
+	// a command that exited has a process state, even if it failed or was killed
+	if err != nil && c.ProcessState == nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// killProcessGroup is a no-op, process groups are only used on Unix. Only the command itself is killed
+// when its context is done.
+func killProcessGroup(*exec.Cmd) {}
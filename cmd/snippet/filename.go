@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultFilenameExt is added to filenames without an extension when no file type is selected,
+// so the file is previewed as text.
+const defaultFilenameExt = ".log"
+
+var (
+	// filenameFlagRegex matches the -f flag with a quoted or unquoted name.
+	filenameFlagRegex = regexp.MustCompile(`(^|\s)-f\s+('[^']*'|"[^"]*"|\S+)`)
+	// invalidFilenameChars are replaced in rendered filenames, so they are valid on every platform.
+	invalidFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	repeatedDashes       = regexp.MustCompile(`-{2,}`)
+)
+
+// snippetFile is a shared file with the result of a command.
+type snippetFile struct {
+	Name    string
+	Content string
+	// SlackType is the Slack snippet type, Slack detects it from the name when it is empty.
+	SlackType string
+}
+
+// fileNames returns the names of the files for messages, e.g. "a.log, b.log".
+func fileNames(files []snippetFile) string {
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// openLabel returns the label of the button opening a file, e.g. "Open file", or "Open a.log" for one of several files.
+func openLabel(action, noun, name string, count int) string {
+	if count > 1 {
+		return action + " " + name
+	}
+	return strings.TrimSpace(action + " " + noun)
+}
+
+// uniqueName returns the name, with a -2, -3, ... suffix before the extension if it is already taken.
+func uniqueName(taken map[string]bool, name string) string {
+	ext := path.Ext(name)
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// filenameData holds the fields available in the -f template.
+type filenameData struct {
+	// Command is the snippet command, e.g. kubectl-get-pods.
+	Command   string
+	Date      string
+	Time      string
+	Timestamp string
+}
+
+// cutFilenameFlag returns the value of the -f flag and the command without it.
+func cutFilenameFlag(command string) (name, rest string) {
+	head, tail := splitFlags(command)
+	match := filenameFlagRegex.FindStringSubmatchIndex(head)
+	if match == nil {
+		return "", command
+	}
+	name = strings.Trim(head[match[4]:match[5]], `"'`)
+	return name, head[:match[0]] + head[match[1]:] + tail
+}
+
+// renderFilename renders the -f template, e.g. {{.Command}}-{{.Date}}, or returns <unixtime>.log without it.
+// The result is sanitized and gets the extension of the file type, or .log, if it has none.
+func renderFilename(text, cmd, ext string, now time.Time) (string, error) {
+	if ext == "" {
+		ext = defaultFilenameExt
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	if text == "" {
+		return timestamp + ext, nil
+	}
+
+	tmpl, err := template.New("filename").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid filename %q: %v", text, err)
+	}
+	var out strings.Builder
+	err = tmpl.Execute(&out, filenameData{
+		Command:   sanitizeFilename(cmd),
+		Date:      now.UTC().Format("2006-01-02"),
+		Time:      now.UTC().Format("15-04-05"),
+		Timestamp: timestamp,
+	})
+	if err != nil {
+		return "", fmt.Errorf("while rendering filename %q: %v", text, err)
+	}
+
+	name := sanitizeFilename(out.String())
+	if name == "" || strings.Trim(name, ".") == "" {
+		return "", fmt.Errorf("filename %q rendered an empty name", text)
+	}
+	if path.Ext(name) == "" {
+		name += ext
+	}
+	return name, nil
+}
+
+// sanitizeFilename replaces spaces, slashes and other special characters with dashes.
+func sanitizeFilename(name string) string {
+	name = invalidFilenameChars.ReplaceAllString(strings.TrimSpace(name), "-")
+	return strings.Trim(repeatedDashes.ReplaceAllString(name, "-"), "-")
+}
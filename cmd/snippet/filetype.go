@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// fileType is a snippet type selected with -t, with the extension of its files and the Slack snippet type.
+type fileType struct {
+	Ext       string
+	SlackType string
+}
+
+var fileTypes = map[string]fileType{
+	"yaml": {Ext: ".yaml", SlackType: "yaml"},
+	"json": {Ext: ".json", SlackType: "json"},
+	"txt":  {Ext: ".txt", SlackType: "text"},
+	"diff": {Ext: ".diff", SlackType: "diff"},
+	"go":   {Ext: ".go", SlackType: "go"},
+	"csv":  {Ext: ".csv", SlackType: "csv"},
+}
+
+var (
+	// typeFlagRegex matches the -t flag.
+	typeFlagRegex = regexp.MustCompile(`(^|\s)-t\s+(\S+)`)
+	// outputFormatRegex matches the output format of kubectl and helm commands, e.g. -o yaml or --output=json.
+	outputFormatRegex = regexp.MustCompile(`(?:^|\s)(?:-o\s*=?\s*|--output[=\s]\s*)(yaml|json)\b`)
+)
+
+// cutTypeFlag returns the value of the -t flag and the command without it.
+func cutTypeFlag(command string) (name, rest string) {
+	head, tail := splitFlags(command)
+	match := typeFlagRegex.FindStringSubmatchIndex(head)
+	if match == nil {
+		return "", command
+	}
+	return head[match[4]:match[5]], head[:match[0]] + head[match[1]:] + tail
+}
+
+// resolveFileType returns the type given with -t, or the type detected from the command, e.g. yaml for -o yaml
+// and helm get manifest, and diff for kubectl diff. An empty name without a detected type returns the zero fileType.
+func resolveFileType(name, cmd string) (fileType, error) {
+	if name != "" {
+		ft, ok := fileTypes[strings.ToLower(name)]
+		if !ok {
+			names := make([]string, 0, len(fileTypes))
+			for n := range fileTypes {
+				names = append(names, n)
+			}
+			slices.Sort(names)
+			return fileType{}, fmt.Errorf("unsupported file type %q, use one of %s", name, strings.Join(names, ", "))
+		}
+		return ft, nil
+	}
+
+	if match := outputFormatRegex.FindStringSubmatch(cmd); match != nil {
+		return fileTypes[match[1]], nil
+	}
+	fields := strings.Fields(cmd)
+	if len(fields) > 0 && (fields[0] == "diff" || len(fields) > 1 && fields[1] == "diff") {
+		return fileTypes["diff"], nil
+	}
+	// helm prints manifests, hooks and values as YAML by default
+	if len(fields) > 2 && fields[0] == "helm" && fields[1] == "get" && slices.Contains([]string{"manifest", "hooks", "values"}, fields[2]) {
+		return fileTypes["yaml"], nil
+	}
+	return fileType{}, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/itchyny/gojq"
+)
+
+var (
+	// jqFlagRegex matches the --jq flag with a quoted or unquoted expression, e.g. --jq '.items[].metadata.name'.
+	jqFlagRegex = regexp.MustCompile(`(^|\s)--jq\s+('[^']*'|"[^"]*"|\S+)`)
+	// grepFlagRegex matches the --grep flag with a quoted or unquoted regular expression, e.g. --grep 'Error|Warn'.
+	grepFlagRegex = regexp.MustCompile(`(^|\s)--grep\s+('[^']*'|"[^"]*"|\S+)`)
+)
+
+// outputFilter trims the output of commands before it is shared, without a shell pipe.
+type outputFilter struct {
+	jq      *gojq.Code
+	convert string
+	grep    *regexp.Regexp
+}
+
+// cutJQFlag returns the expression of the --jq flag and the command without it.
+func cutJQFlag(command string) (string, string) {
+	return cutQuotedFlag(jqFlagRegex, command)
+}
+
+// cutGrepFlag returns the regular expression of the --grep flag and the command without it.
+func cutGrepFlag(command string) (string, string) {
+	return cutQuotedFlag(grepFlagRegex, command)
+}
+
+func cutQuotedFlag(re *regexp.Regexp, command string) (string, string) {
+	head, tail := splitFlags(command)
+	match := re.FindStringSubmatchIndex(head)
+	if match == nil {
+		return "", command
+	}
+	value := strings.Trim(head[match[4]:match[5]], `"'`)
+	return value, head[:match[0]] + head[match[1]:] + tail
+}
+
+// newOutputFilter compiles the --jq expression and the --grep regular expression and checks the --convert format,
+// any of them may be empty.
+func newOutputFilter(jq, convert, grep string) (outputFilter, error) {
+	var filter outputFilter
+	if convert != "" {
+		if _, ok := conversions[convert]; !ok {
+			return outputFilter{}, fmt.Errorf("unsupported --convert format %q, use one of yaml, csv, table", convert)
+		}
+		filter.convert = convert
+	}
+	if jq != "" {
+		query, err := gojq.Parse(jq)
+		if err != nil {
+			return outputFilter{}, fmt.Errorf("invalid --jq expression %q: %v", jq, err)
+		}
+		// env and $ENV would expose the env vars of the plugin, which hold its tokens
+		filter.jq, err = gojq.Compile(query, gojq.WithEnvironLoader(func() []string { return nil }))
+		if err != nil {
+			return outputFilter{}, fmt.Errorf("invalid --jq expression %q: %v", jq, err)
+		}
+	}
+	if grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			return outputFilter{}, fmt.Errorf("invalid --grep expression %q: %v", grep, err)
+		}
+		filter.grep = re
+	}
+	return filter, nil
+}
+
+// apply runs the output through the jq expression, converts it and then keeps the lines matching the grep expression.
+func (f outputFilter) apply(ctx context.Context, out string) (string, error) {
+	if (f.jq != nil || f.convert != "") && strings.TrimSpace(out) != "" {
+		values, err := decodeJSON(out)
+		if err != nil {
+			return "", err
+		}
+		if f.jq != nil {
+			values, err = f.runJQ(ctx, values)
+			if err != nil {
+				return "", err
+			}
+		}
+		if f.convert != "" {
+			out, err = convertValues(f.convert, values)
+		} else {
+			out, err = encodeValues(values)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if f.grep != nil {
+		out = f.grepLines(out)
+	}
+	return out, nil
+}
+
+// decodeJSON returns the JSON values of the output, e.g. a single kubectl list or a stream of objects.
+func decodeJSON(out string) ([]interface{}, error) {
+	var values []interface{}
+	dec := json.NewDecoder(strings.NewReader(out))
+	// numbers are kept as json.Number, which gojq converts without losing the precision of large integers
+	dec.UseNumber()
+	for {
+		var value interface{}
+		err := dec.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			return values, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("--jq and --convert need JSON output, e.g. with -o json: %v", err)
+		}
+		values = append(values, value)
+	}
+}
+
+// runJQ runs the expression on each value and returns all results.
+func (f outputFilter) runJQ(ctx context.Context, values []interface{}) ([]interface{}, error) {
+	var results []interface{}
+	for _, value := range values {
+		iter := f.jq.RunWithContext(ctx, value)
+		for {
+			v, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if err, ok := v.(error); ok {
+				var haltErr *gojq.HaltError
+				if errors.As(err, &haltErr) && haltErr.Value() == nil {
+					break
+				}
+				return nil, fmt.Errorf("while running --jq: %v", err)
+			}
+			results = append(results, v)
+		}
+	}
+	return results, nil
+}
+
+// encodeValues writes strings raw, as with jq -r, and other values as indented JSON, one per line.
+func encodeValues(values []interface{}) (string, error) {
+	var b strings.Builder
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			b.WriteString(s)
+			b.WriteString("\n")
+			continue
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("while encoding --jq result: %v", err)
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+func (f outputFilter) grepLines(out string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(out, "\n") {
+		if line != "" && f.grep.MatchString(strings.TrimSuffix(line, "\n")) {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
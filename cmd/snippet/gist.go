@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"botkube.io/plugins-example/internal/gistupload"
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// sendToGist creates a Gist, secret unless gist.public is set, and replies with its link.
+// With -dm, the link is only visible to the requesting user.
+func sendToGist(ctx context.Context, cfg Config, req snippetRequest, files []snippetFile) (executor.ExecuteOutput, error) {
+	if req.Channel != "" {
+		return executor.ExecuteOutput{}, fmt.Errorf("-ch is not supported with gist delivery")
+	}
+	if req.Gzip {
+		return executor.ExecuteOutput{}, fmt.Errorf("--gzip is not supported with gist delivery, gists only hold text")
+	}
+	token := envOr(githubTokenEnv, cfg.Gist.Token)
+	if token == "" {
+		return executor.ExecuteOutput{}, fmt.Errorf("gist.token is not configured, set it in the plugin config or with %s", githubTokenEnv)
+	}
+
+	description := fmt.Sprintf("Command %s result", req.Cmd)
+	if req.Msg != "" {
+		description = req.Msg
+	}
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		contents[file.Name] = file.Content
+	}
+	link, err := gistupload.Upload(ctx, cfg.Gist.APIURL, token, description, cfg.Gist.Public, contents)
+	if err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+
+	text := fmt.Sprintf("Command %s result shared as a gist: %s", req.Cmd, fileNames(files))
+	if req.Msg != "" {
+		text = fmt.Sprintf("%s %s", req.Msg, text)
+	}
+	btnBuilder := api.NewMessageButtonBuilder()
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections: []api.Section{
+				{
+					Base: api.Base{
+						Body: api.Body{
+							Plaintext: text,
+						},
+					},
+					Buttons: []api.Button{
+						btnBuilder.ForURL("Open gist", link),
+					},
+				},
+			},
+			OnlyVisibleForYou: req.DM || cfg.DM,
+		},
+	}, nil
+}
@@ -1,18 +1,9 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"net/url"
-	"os"
-	"os/exec"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
@@ -20,157 +11,19 @@ import (
 	"github.com/kubeshop/botkube/pkg/api"
 	"github.com/kubeshop/botkube/pkg/api/executor"
 	"github.com/kubeshop/botkube/pkg/plugin"
-	"gopkg.in/yaml.v2"
 )
 
-type Config struct {
-	CommunicationGroup string `yaml:"communicationGroup,omitempty"`
-	Communications map[string]struct {
-		SocketSlack struct {
-			AppToken string `yaml:"appToken"`
-			BotToken string `yaml:"botToken"`
-			Enabled  bool   `yaml:"enabled"`
-			Channels  map[string]struct {
-				Name     string   `yaml:"name"`
-				ID       string   `yaml:"id"`
-				Bindings struct {
-					Executors []string `yaml:"executors"`
-					Sources   []string `yaml:"sources"`
-				} `yaml:"bindings"`
-			} `yaml:"channels"`
-		} `yaml:"socketSlack"`
-	} `yaml:"communications"`
-}
-
-const (
-	configPath = "/config/comm_config.yaml"
-)
-
-type UploadURLResponse struct {
-	UploadURL string `json:"upload_url"`
-	FileID    string `json:"file_id"`
-}
-
-type CompleteUploadPayload struct {
-	Files         []FileInfo `json:"files"`
-	ChannelID     string     `json:"channel_id"`
-	InitialComment string    `json:"initial_comment"`
-}
-
-type FileInfo struct {
-	ID string `json:"id"`
-}
-
 const description = "snippet"
 
 // version is set via ldflags by GoReleaser.
 var version = "dev"
-var configJSONSchema      string
 
 // SnippetExecutor implements the Botkube executor plugin interface.
 type SnippetExecutor struct{}
 
-func getUploadURL(token, filename string, fileSize int) (string, string, error) {
-	url := "https://slack.com/api/files.getUploadURLExternal"
-	data := map[string]string{
-		"filename": filename,
-		"token":    token,
-		"length":   fmt.Sprintf("%d", fileSize),
-	}
-
-	resp, err := postForm(url, data)
-	if err != nil {
-		return "", "", err
-	}
-
-	var result UploadURLResponse
-	err = json.Unmarshal(resp, &result)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to parse response: %v", err)
-	}
-
-	if result.UploadURL == "null" {
-		return "", "", fmt.Errorf("error getting upload URL: %s", string(resp))
-	}
-
-	return result.UploadURL, result.FileID, nil
-}
-
-func uploadFile(uploadURL, content string) error {
-	req, err := http.NewRequest("POST", uploadURL, strings.NewReader(content))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-	req.Header.Set("Content-Type", "application/octet-stream")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to upload file: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("error uploading file: %s", string(body))
-	}
-
-	return nil
-}
-
-func completeUpload(token, fileID, channelID, message string) error {
-	url := "https://slack.com/api/files.completeUploadExternal"
-	payload := CompleteUploadPayload{
-		Files:         []FileInfo{{ID: fileID}},
-		ChannelID:     channelID,
-		InitialComment: message,
-	}
-
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %v", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json;charset=utf-8")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("error completing upload: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("error completing upload: %s", string(body))
-	}
-
-	return nil
-}
-
-func postForm(urlString string, data map[string]string) ([]byte, error) {
-    form := url.Values{}
-    for key, value := range data {
-        form.Add(key, value)
-    }
-
-    resp, err := http.PostForm(urlString, form)
-    if err != nil {
-        return nil, err
-    }
-    defer resp.Body.Close()
-
-    return io.ReadAll(resp.Body)
-}
-
-
 const (
 	kubectlVersion   = "v1.28.1"
+	helmVersion      = "v3.13.2"
 )
 
 func (SnippetExecutor) Metadata(context.Context) (api.MetadataOutput, error) {
@@ -188,6 +41,19 @@ func (SnippetExecutor) Metadata(context.Context) (api.MetadataOutput, error) {
 					"linux/386":     fmt.Sprintf("https://dl.k8s.io/release/%s/bin/linux/386/kubectl", kubectlVersion),
 				},
 			},
+			// the binary is extracted from the archive, from the directory after //
+			"helm": {
+				URLs: map[string]string{
+					"windows/amd64": fmt.Sprintf("https://get.helm.sh/helm-%s-windows-amd64.zip//windows-amd64", helmVersion),
+					"darwin/amd64":  fmt.Sprintf("https://get.helm.sh/helm-%s-darwin-amd64.tar.gz//darwin-amd64", helmVersion),
+					"darwin/arm64":  fmt.Sprintf("https://get.helm.sh/helm-%s-darwin-arm64.tar.gz//darwin-arm64", helmVersion),
+					"linux/amd64":   fmt.Sprintf("https://get.helm.sh/helm-%s-linux-amd64.tar.gz//linux-amd64", helmVersion),
+					"linux/arm64":   fmt.Sprintf("https://get.helm.sh/helm-%s-linux-arm64.tar.gz//linux-arm64", helmVersion),
+					"linux/s390x":   fmt.Sprintf("https://get.helm.sh/helm-%s-linux-s390x.tar.gz//linux-s390x", helmVersion),
+					"linux/ppc64le": fmt.Sprintf("https://get.helm.sh/helm-%s-linux-ppc64le.tar.gz//linux-ppc64le", helmVersion),
+					"linux/386":     fmt.Sprintf("https://get.helm.sh/helm-%s-linux-386.tar.gz//linux-386", helmVersion),
+				},
+			},
 		},
 		Version:     version,
 		Description:      description,
@@ -198,64 +64,230 @@ func (SnippetExecutor) Metadata(context.Context) (api.MetadataOutput, error) {
 }
 
 
-// Execute returns a given command as a response.
-//
-//nolint:gocritic  //hugeParam: in is heavy (80 bytes); consider passing it by pointer
+// snippetRequest is the parsed snippet command.
+type snippetRequest struct {
+	// Cmd is the command, or the commands separated by semicolons, as shown in messages.
+	Cmd string
+	// Cmds are the commands to run, each result is shared as its own file.
+	Cmds []string
+	Msg  string
+	// Channel is the channel requested with -ch.
+	Channel string
+	// DM is set by the -dm flag.
+	DM bool
+	// Filename is the template given with -f.
+	Filename string
+	// Type is the file type given with -t, it is detected from each command when empty.
+	Type string
+	// Gzip is set by the --gzip flag.
+	Gzip bool
+	// Timeout is given with -timeout, the config default applies when it is zero.
+	Timeout time.Duration
+	// Stream is set by the --stream flag.
+	Stream bool
+	// Filter is compiled from the --jq and --grep flags.
+	Filter outputFilter
+}
+
 func (SnippetExecutor) Execute(ctx context.Context, in executor.ExecuteInput) (executor.ExecuteOutput, error) {
-	var cmd, msg, message string
+	var req snippetRequest
+	var err error
 
-	msg, cmd, err := parseCmdAndMsg(in.Command)
-	if err != nil {
+	if err := validateConfig(in.Configs); err != nil {
 		return executor.ExecuteOutput{}, err
 	}
-
 	var cfg Config
 	err = plugin.MergeExecutorConfigs(in.Configs, &cfg)
 	if err != nil {
 		return executor.ExecuteOutput{}, err
 	}
-	
-	botToken, channelID, err := getConfig(cfg.CommunicationGroup)
 
-	// Step 1: Execute the command
-	content, err := executeCommand(ctx, cmd, in.Context.KubeConfig)
+	req.Channel, in.Command = cutChannelFlag(in.Command)
+	req.DM, in.Command = cutDMFlag(in.Command)
+	req.Filename, in.Command = cutFilenameFlag(in.Command)
+	req.Gzip, in.Command = cutGzipFlag(in.Command)
+	req.Stream, in.Command = cutStreamFlag(in.Command)
+	req.Timeout, in.Command, err = cutTimeoutFlag(in.Command)
 	if err != nil {
 		return executor.ExecuteOutput{}, err
 	}
-	if content == "" {
-		content = "empty output"
-	}
-	fileSize := len(content)
-	filename := fmt.Sprintf("%s.log", strconv.FormatInt(time.Now().Unix(), 10))
-
-	// Step 2: Get the upload URL
-	uploadURL, fileID, err := getUploadURL(botToken, filename, fileSize)
+	req.Type, in.Command = cutTypeFlag(in.Command)
+	var jq, convert, grep string
+	jq, in.Command = cutJQFlag(in.Command)
+	convert, in.Command = cutConvertFlag(in.Command)
+	grep, in.Command = cutGrepFlag(in.Command)
+	req.Filter, err = newOutputFilter(jq, convert, grep)
 	if err != nil {
 		return executor.ExecuteOutput{}, err
 	}
+	if req.Type == "" {
+		// converted output is shared as a file of its format
+		req.Type = conversions[convert]
+	}
+	if name, msg, ok := cutPresetCommand(in.Command); ok {
+		if name == "" {
+			return listPresets(cfg.Presets), nil
+		}
+		preset, err := findPreset(cfg.Presets, name)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+		req.Cmds, req.Msg = []string{preset.Command}, msg
+		if req.Msg == "" {
+			req.Msg = preset.Description
+		}
+		if req.Filename == "" {
+			req.Filename = preset.Filename
+		}
+	} else {
+		req.Cmds, req.Msg, err = cutCommands(in.Command, cfg.Commands.FilesDir)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+		cfg.Env = literalEnv(cfg.Env)
+	}
+	if len(req.Cmds) == 0 {
+		req.Msg, req.Cmd, err = parseCmdAndMsg(in.Command)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+		req.Cmds = []string{req.Cmd}
+	}
+	req.Cmd = strings.Join(req.Cmds, "; ")
+	if req.DM && req.Channel != "" {
+		return executor.ExecuteOutput{}, fmt.Errorf("-dm and -ch cannot be used together")
+	}
+	if req.Stream && len(req.Cmds) > 1 {
+		return executor.ExecuteOutput{}, fmt.Errorf("--stream supports a single command")
+	}
 
-	// Step 3: Upload the file
-	err = uploadFile(uploadURL, content)
+	// the files are named up front, so a wrong -f or -t is reported before anything runs
+	files := make([]snippetFile, 0, len(req.Cmds))
+	taken := map[string]bool{}
+	now := time.Now()
+	for _, cmd := range req.Cmds {
+		ft, err := resolveFileType(req.Type, cmd)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+		name, err := renderFilename(req.Filename, cmd, ft.Ext, now)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+		if req.Filename == "" && len(req.Cmds) > 1 {
+			// <unixtime>.log would be the same for all commands
+			name, err = renderFilename("{{.Command}}", cmd, ft.Ext, now)
+			if err != nil {
+				return executor.ExecuteOutput{}, err
+			}
+		}
+		files = append(files, snippetFile{Name: uniqueName(taken, name), SlackType: ft.SlackType})
+	}
+
+	for _, cmd := range req.Cmds {
+		if err := checkCommand(cfg.Commands, cmd); err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+	}
+	platform, err := selectPlatform(cfg)
 	if err != nil {
 		return executor.ExecuteOutput{}, err
 	}
-
-	// fmt.Printf("%s has been successfully executed\n", command) 
-	if msg != "" {
-		message = fmt.Sprintf("%s please check attachement with the following name: %s", msg, filename)
-	} else {
-		message = fmt.Sprintf("Command %s result sent, please check attachement with the following name: %s", cmd, filename)
+	var dest slackDestination
+	if platform == platformSlack {
+		dest, err = slackTarget(ctx, cfg, in.Context.Message, req)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+	} else if req.Stream {
+		return executor.ExecuteOutput{}, fmt.Errorf("--stream is only supported on Slack")
 	}
 
-		// Step 4: Complete the upload and post the message
-		err = completeUpload(botToken, fileID, channelID, message)
+	// Step 1: Execute the commands
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = cfg.Timeout
+	}
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	var notes []string
+	for i, cmd := range req.Cmds {
+		note, err := runSnippet(ctx, cfg, dest, req, cmd, in.Context.KubeConfig, timeout, &files[i])
 		if err != nil {
 			return executor.ExecuteOutput{}, err
 		}
+		if note != "" {
+			notes = append(notes, note)
+		}
+	}
+	if len(notes) > 0 {
+		req.Msg = strings.TrimSpace(req.Msg + " " + strings.Join(notes, " "))
+	}
 
-	return executor.ExecuteOutput{
-		Message: api.NewCodeBlockMessage(fmt.Sprintf("Command %s result sent, please check attachement with the following name: %s", cmd, filename), false),
-	}, nil
+	// Step 2: Deliver the files on the communication platform
+	switch platform {
+	case platformTeams:
+		return sendToTeams(ctx, cfg, req, files)
+	case platformMattermost:
+		return sendToMattermost(ctx, cfg, req, files)
+	case platformDiscord:
+		return sendToDiscord(ctx, cfg, req, files)
+	case platformS3, platformGCS, platformAzureBlob:
+		return sendToStorage(ctx, cfg, platform, req, files)
+	case platformGist:
+		return sendToGist(ctx, cfg, req, files)
+	}
+	return sendToSlack(ctx, dest, req, files)
+}
+
+// runSnippet runs the command and sets the content of its file. It returns a note for the message
+// when the command failed or timed out.
+func runSnippet(ctx context.Context, cfg Config, dest slackDestination, req snippetRequest, cmd string, kubeConfig []byte, timeout time.Duration, file *snippetFile) (string, error) {
+	var result commandResult
+	var timedOut bool
+	var err error
+	if req.Stream {
+		result, timedOut, err = executeStreaming(ctx, cfg, dest, cmd, kubeConfig, timeout)
+	} else {
+		result, timedOut, err = executeWithTimeout(ctx, cfg, cmd, kubeConfig, timeout, nil)
+	}
+	if err != nil {
+		return "", err
+	}
+	filtered, err := req.Filter.apply(ctx, result.Stdout)
+	switch {
+	case err == nil:
+		result.Stdout = filtered
+	case timedOut || result.ExitCode != 0:
+		// the output of a failed command is shared as it is, it tells what went wrong
+	default:
+		return "", fmt.Errorf("while filtering the output of %s: %v", cmd, err)
+	}
+
+	var note string
+	content := result.render()
+	switch {
+	case timedOut:
+		content += fmt.Sprintf("\n[command timed out after %s, the output is partial]\n", timeout)
+		note = fmt.Sprintf("Command %s timed out after %s, the output is partial.", cmd, timeout)
+	case result.ExitCode != 0:
+		note = fmt.Sprintf("Command %s failed with exit code %d.", cmd, result.ExitCode)
+	}
+	if content == "" {
+		content = "empty output"
+	}
+	if req.Gzip {
+		content, err = gzipContent(content)
+		if err != nil {
+			return "", err
+		}
+		file.Name += ".gz"
+		// the compressed file is not a snippet, it cannot be highlighted
+		file.SlackType = ""
+	}
+	file.Content = content
+	return note, nil
 }
 
 func (SnippetExecutor) Help(context.Context) (api.Message, error) {
@@ -269,31 +301,13 @@ func (SnippetExecutor) Help(context.Context) (api.Message, error) {
 				},
 				Buttons: []api.Button{
 					btnBuilder.ForCommandWithDescCmd("Run", "snippet -c echo 'hello world'"),
+					btnBuilder.ForCommandWithDescCmd("Presets", "snippet preset"),
 				},
 			},
 		},
 	}, nil
 }
 
-func getConfig(group string) (string, string, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return "","", fmt.Errorf("error reading YAML file: %v", err)
-	}
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return "","", fmt.Errorf("error parsing YAML file: %v", err)
-	}
-	socketSlack, exists := config.Communications[group]
-	if !exists {
-		log.Fatalf("group %s not found", group)
-	}
-	
-	botToken := socketSlack.SocketSlack.BotToken
-	channelID := socketSlack.SocketSlack.Channels["default"].ID
-	return botToken, channelID, nil
-}
-
 func parseCommand(cmd string) (action, value string) {
 	parts := strings.Fields(cmd)
 	if len(parts) > 1 {
@@ -352,32 +366,6 @@ func parseCmdAndMsg(command string) (string, string, error) {
 	return msg, cmd, nil
 }
 
-func executeCommand(ctx context.Context, cmd string, kubeConfig []byte) (string, error) {
-	if strings.HasPrefix(cmd, "kubectl") {
-		kubeConfigPath, deleteFn, err := plugin.PersistKubeConfig(ctx, kubeConfig)
-		if err != nil {
-			return "", fmt.Errorf("error writing kubeconfig file: %v", err)
-		}
-		defer func() {
-			if deleteErr := deleteFn(ctx); deleteErr != nil {
-				fmt.Fprintf(os.Stderr, "failed to delete kubeconfig file %s: %v", kubeConfigPath, deleteErr)
-			}
-		}()
-		envs := map[string]string{
-			"KUBECONFIG": kubeConfigPath,
-		}
-
-		out, err := plugin.ExecuteCommand(ctx, cmd, plugin.ExecuteCommandEnvs(envs))
-		return out.Stdout, err
-	}
-
-	out, err := exec.Command("sh", "-c", cmd).Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to run command %s: %v", cmd, err)
-	}
-	return string(out), nil
-}
-
 func main() {
 	executor.Serve(map[string]go_plugin.Plugin{
 		"snippet": &executor.Plugin{
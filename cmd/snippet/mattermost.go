@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"botkube.io/plugins-example/internal/mattermostupload"
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// sendToMattermost shares the file in the configured channel, or the channel requested with -ch.
+func sendToMattermost(ctx context.Context, cfg Config, req snippetRequest, files []snippetFile) (executor.ExecuteOutput, error) {
+	if req.DM {
+		return executor.ExecuteOutput{}, fmt.Errorf("-dm is not supported on Mattermost")
+	}
+	serverURL, token, err := mattermostCredentials(cfg)
+	if err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+	channelID := cfg.Mattermost.ChannelID
+	if req.Channel != "" {
+		channelID, err = resolveChannel(cfg.Channels, req.Channel)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+	}
+	if channelID == "" {
+		return executor.ExecuteOutput{}, fmt.Errorf("mattermost.channelID is not configured")
+	}
+
+	fileIDs := make([]string, 0, len(files))
+	for _, file := range files {
+		fileID, err := mattermostupload.UploadFile(ctx, serverURL, token, channelID, file.Name, file.Content)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+
+	filename := fileNames(files)
+	message := fmt.Sprintf("Command %s result sent, please check attachement with the following name: %s", req.Cmd, filename)
+	if req.Msg != "" {
+		message = fmt.Sprintf("%s please check attachement with the following name: %s", req.Msg, filename)
+	}
+	if _, err := mattermostupload.CreatePost(ctx, serverURL, token, channelID, message, fileIDs...); err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(fmt.Sprintf("Command %s result sent, please check attachement with the following name: %s", req.Cmd, filename), false),
+	}, nil
+}
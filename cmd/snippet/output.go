@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// commandResult holds what a command wrote and how it exited.
+type commandResult struct {
+	Stdout string
+	Stderr string
+	// ExitCode is -1 when the command was killed, e.g. after a timeout.
+	ExitCode int
+}
+
+// render returns the content of the shared file. The output of successful commands is shared as is, otherwise
+// stderr and the exit code follow stdout in separate sections.
+func (r commandResult) render() string {
+	if r.Stderr == "" && r.ExitCode == 0 {
+		return r.Stdout
+	}
+	var out strings.Builder
+	writeSection(&out, r.Stdout)
+	if r.Stderr != "" {
+		out.WriteString("\n----- stderr -----\n")
+		writeSection(&out, r.Stderr)
+	}
+	fmt.Fprintf(&out, "\n----- exit code: %d -----\n", r.ExitCode)
+	return out.String()
+}
+
+// writeSection writes the text, ending it with a newline, so section markers start on their own line.
+func writeSection(out *strings.Builder, text string) {
+	out.WriteString(text)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		out.WriteString("\n")
+	}
+}
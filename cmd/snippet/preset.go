@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// presetCommandRegex matches the preset subcommand, e.g. snippet preset not-running, with the rest of the line.
+var presetCommandRegex = regexp.MustCompile(`^\s*\S+\s+preset(\s.*)?$`)
+
+// cutPresetCommand returns the preset name and the -m message of the preset subcommand, and false for
+// other commands. The name is empty when the presets are listed.
+func cutPresetCommand(command string) (name, msg string, ok bool) {
+	match := presetCommandRegex.FindStringSubmatch(command)
+	if match == nil {
+		return "", "", false
+	}
+	rest := match[1]
+	if m := messageFlagRegex.FindStringSubmatchIndex(rest); m != nil {
+		msg = strings.Trim(rest[m[4]:m[5]], `"'`)
+		rest = rest[:m[0]] + rest[m[1]:]
+	}
+	return strings.TrimSpace(rest), msg, true
+}
+
+// findPreset returns the configured preset of the name.
+func findPreset(presets map[string]PresetConfig, name string) (PresetConfig, error) {
+	preset, ok := presets[name]
+	if !ok {
+		if len(presets) == 0 {
+			return PresetConfig{}, fmt.Errorf("unknown preset %q, no presets are configured", name)
+		}
+		return PresetConfig{}, fmt.Errorf("unknown preset %q, use %s", name, strings.Join(presetNames(presets), ", "))
+	}
+	if strings.TrimSpace(preset.Command) == "" {
+		return PresetConfig{}, fmt.Errorf("preset %s has no command", name)
+	}
+	return preset, nil
+}
+
+// listPresets returns a message with a button running each preset, described by its description or command.
+func listPresets(presets map[string]PresetConfig) executor.ExecuteOutput {
+	if len(presets) == 0 {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage("No presets are configured, add them to presets in the snippet plugin config.", false),
+		}
+	}
+	btnBuilder := api.NewMessageButtonBuilder()
+	var buttons api.Buttons
+	for _, name := range presetNames(presets) {
+		desc := presets[name].Description
+		if desc == "" {
+			desc = presets[name].Command
+		}
+		buttons = append(buttons, btnBuilder.ForCommand(name, "snippet preset "+name, desc))
+	}
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections: []api.Section{
+				{
+					Base: api.Base{
+						Header: "Snippet presets",
+					},
+					Buttons: buttons,
+				},
+			},
+		},
+	}
+}
+
+func presetNames(presets map[string]PresetConfig) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeshop/botkube/pkg/api/executor"
+	"github.com/kubeshop/botkube/pkg/plugin"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var configSchemaLoader = gojsonschema.NewStringLoader(configJSONSchema)
+
+// validateConfig validates the merged plugin configs against the config schema. Issues point to the invalid
+// fields, e.g. "s3.linkExpiry: Does not match pattern ...", so a misconfiguration is reported with the command
+// instead of failing later, e.g. an unknown platform or a typo in a field name that would be ignored.
+func validateConfig(configs []*executor.Config) error {
+	var merged map[string]interface{}
+	if err := plugin.MergeExecutorConfigs(configs, &merged); err != nil {
+		return fmt.Errorf("while merging plugin configs: %v", err)
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+
+	result, err := gojsonschema.Validate(configSchemaLoader, gojsonschema.NewGoLoader(merged))
+	if err != nil {
+		return fmt.Errorf("while validating plugin config: %v", err)
+	}
+	var issues []string
+	for _, resErr := range result.Errors() {
+		desc := resErr.Description()
+		if resErr.Type() == "pattern" {
+			// the patterns of the schema only match durations
+			desc = "must be a duration, e.g. 30s, 5m or 24h"
+		}
+		issues = append(issues, fmt.Sprintf("%s: %s", resErr.Field(), desc))
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("invalid snippet plugin config, fix it in the Botkube configuration: %s", strings.Join(issues, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+)
+
+// substitutionRegex matches command substitution, which hides commands from the check.
+var substitutionRegex = regexp.MustCompile("`|\\$\\(")
+
+// shellSyntaxRegex matches subshells, groups, redirections and escapes, which hide commands from the check
+// or write files, e.g. (kubectl delete pod app), \kubectl delete pod app or kubectl version > ~/.profile.
+var shellSyntaxRegex = regexp.MustCompile(`[(){}<>\\]`)
+
+// exeSuffix is the extension of downloaded binaries.
+const exeSuffix = ""
+
+// shellCommand runs the command line with sh.
+func shellCommand(ctx context.Context, cmd string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", cmd)
+}
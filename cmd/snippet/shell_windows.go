@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"syscall"
+)
+
+// substitutionRegex matches command substitution, which hides commands from the check. In PowerShell, commands
+// also run in subexpressions and parentheses, e.g. echo (kubectl delete pod app), and the backtick escapes.
+var substitutionRegex = regexp.MustCompile("`|\\$\\(|@\\(|\\(")
+
+// shellSyntaxRegex matches script blocks, redirections and the escape of cmd, which hide commands from the check
+// or write files, e.g. kubectl version > profile.ps1. The backslash is a path separator on Windows.
+var shellSyntaxRegex = regexp.MustCompile(`[{}<>^]`)
+
+// exeSuffix is the extension of downloaded binaries, Windows only runs files with an executable extension.
+const exeSuffix = ".exe"
+
+// shellCommand runs the command line with PowerShell, or with cmd when PowerShell is not installed,
+// e.g. on Nano Server.
+func shellCommand(ctx context.Context, cmd string) *exec.Cmd {
+	if powershell, err := exec.LookPath("powershell.exe"); err == nil {
+		return exec.CommandContext(ctx, powershell, "-NoLogo", "-NoProfile", "-NonInteractive", "-Command", cmd)
+	}
+	c := exec.CommandContext(ctx, "cmd.exe")
+	// cmd doesn't parse its arguments the way the escaping of exec expects, so the command line is passed as is
+	c.SysProcAttr = &syscall.SysProcAttr{CmdLine: fmt.Sprintf(`/d /s /c "%s"`, cmd)}
+	return c
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"botkube.io/plugins-example/internal/slackupload"
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// slackDestination is the Slack conversation files and progress messages are posted in.
+type slackDestination struct {
+	BotToken  string
+	ChannelID string
+	ThreadTS  string
+	// DM is set when the channel is a direct message with the requesting user.
+	DM bool
+}
+
+// slackTarget returns the thread of the command, the channel requested with -ch or a direct message.
+// It is resolved before the command runs, so a wrong channel is reported without waiting for the output.
+func slackTarget(ctx context.Context, cfg Config, origin executor.Message, req snippetRequest) (slackDestination, error) {
+	botToken, channelID, err := slackCredentials(cfg)
+	if err != nil {
+		return slackDestination{}, err
+	}
+	dest := slackDestination{BotToken: botToken}
+	switch {
+	case req.Channel != "":
+		dest.ChannelID, err = resolveChannel(cfg.Channels, req.Channel)
+		if err != nil {
+			return slackDestination{}, err
+		}
+	case req.DM || cfg.DM:
+		dest.DM = true
+		dest.ChannelID, err = openDM(ctx, botToken, origin.User)
+		if err != nil {
+			return slackDestination{}, err
+		}
+	default:
+		dest.ChannelID, dest.ThreadTS = uploadTarget(origin, channelID)
+	}
+	if dest.ChannelID == "" {
+		return slackDestination{}, fmt.Errorf("cannot resolve the channel of the command, set channelID in the plugin config")
+	}
+	return dest, nil
+}
+
+// sendToSlack shares the files in the destination, in a single message.
+func sendToSlack(ctx context.Context, dest slackDestination, req snippetRequest, files []snippetFile) (executor.ExecuteOutput, error) {
+	uploads := make([]slackupload.File, 0, len(files))
+	for _, file := range files {
+		uploads = append(uploads, slackupload.File{Name: file.Name, Content: file.Content, SnippetType: file.SlackType})
+	}
+	filename := fileNames(files)
+
+	var message string
+	if req.Msg != "" {
+		message = fmt.Sprintf("%s please check attachement with the following name: %s", req.Msg, filename)
+	} else {
+		message = fmt.Sprintf("Command %s result sent, please check attachement with the following name: %s", req.Cmd, filename)
+	}
+
+	_, err := slackupload.UploadFiles(ctx, dest.BotToken, dest.ChannelID, dest.ThreadTS, message, uploads)
+	if err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+
+	if dest.DM {
+		return executor.ExecuteOutput{
+			Message: api.NewCodeBlockMessage(fmt.Sprintf("Command %s result sent to you in a direct message: %s", req.Cmd, filename), true),
+		}, nil
+	}
+	return executor.ExecuteOutput{
+		Message: api.NewCodeBlockMessage(fmt.Sprintf("Command %s result sent, please check attachement with the following name: %s", req.Cmd, filename), false),
+	}, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"botkube.io/plugins-example/internal/azblobupload"
+	"botkube.io/plugins-example/internal/gcsupload"
+	"botkube.io/plugins-example/internal/s3upload"
+	"github.com/google/uuid"
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// defaultLinkExpiry is the validity of links to stored files when linkExpiry is not set.
+const defaultLinkExpiry = 24 * time.Hour
+
+// storageBackend stores files in a bucket or container and shares them with links, which expire.
+type storageBackend interface {
+	// Upload stores the content under the key and returns a link to download it, valid for expiry.
+	Upload(ctx context.Context, key, content string, expiry time.Duration) (string, error)
+}
+
+type s3Backend struct{ opts s3upload.Options }
+
+func (b s3Backend) Upload(ctx context.Context, key, content string, expiry time.Duration) (string, error) {
+	return s3upload.Upload(ctx, b.opts, key, content, expiry)
+}
+
+type gcsBackend struct{ opts gcsupload.Options }
+
+func (b gcsBackend) Upload(ctx context.Context, key, content string, expiry time.Duration) (string, error) {
+	return gcsupload.Upload(ctx, b.opts, key, content, expiry)
+}
+
+type azureBlobBackend struct{ opts azblobupload.Options }
+
+func (b azureBlobBackend) Upload(ctx context.Context, key, content string, expiry time.Duration) (string, error) {
+	return azblobupload.Upload(ctx, b.opts, key, content, expiry)
+}
+
+// storageTarget returns the backend of the storage platform with the key prefix and link expiry of its config.
+func storageTarget(cfg Config, platform string) (storageBackend, string, time.Duration, error) {
+	switch platform {
+	case platformS3:
+		if cfg.S3.Bucket == "" {
+			return nil, "", 0, fmt.Errorf("s3.bucket is not configured")
+		}
+		return s3Backend{s3Options(cfg)}, cfg.S3.Prefix, cfg.S3.LinkExpiry, nil
+	case platformGCS:
+		if cfg.GCS.Bucket == "" {
+			return nil, "", 0, fmt.Errorf("gcs.bucket is not configured")
+		}
+		return gcsBackend{gcsOptions(cfg)}, cfg.GCS.Prefix, cfg.GCS.LinkExpiry, nil
+	case platformAzureBlob:
+		if cfg.AzureBlob.AccountName == "" || cfg.AzureBlob.Container == "" {
+			return nil, "", 0, fmt.Errorf("azureBlob.accountName and azureBlob.container are not configured")
+		}
+		return azureBlobBackend{azureBlobOptions(cfg)}, cfg.AzureBlob.Prefix, cfg.AzureBlob.LinkExpiry, nil
+	}
+	return nil, "", 0, fmt.Errorf("%s is not a storage platform", platform)
+}
+
+// sendToStorage stores the file with the backend of the platform and replies with a link, which expires.
+// With -dm, the link is only visible to the requesting user.
+func sendToStorage(ctx context.Context, cfg Config, platform string, req snippetRequest, files []snippetFile) (executor.ExecuteOutput, error) {
+	if req.Channel != "" {
+		return executor.ExecuteOutput{}, fmt.Errorf("-ch is not supported with %s delivery", platform)
+	}
+	backend, prefix, expiry, err := storageTarget(cfg, platform)
+	if err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+	if expiry == 0 {
+		expiry = defaultLinkExpiry
+	}
+
+	// the random part keeps files with the same name apart
+	dir := prefix + uuid.NewString() + "/"
+	btnBuilder := api.NewMessageButtonBuilder()
+	var buttons []api.Button
+	for _, file := range files {
+		link, err := backend.Upload(ctx, dir+file.Name, file.Content, expiry)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+		buttons = append(buttons, btnBuilder.ForURL(openLabel("Download", "", file.Name, len(files)), link))
+	}
+
+	text := fmt.Sprintf("Command %s result uploaded: %s, the link expires in %s", req.Cmd, fileNames(files), expiry)
+	if req.Msg != "" {
+		text = fmt.Sprintf("%s %s", req.Msg, text)
+	}
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections: []api.Section{
+				{
+					Base: api.Base{
+						Body: api.Body{
+							Plaintext: text,
+						},
+					},
+					Buttons: buttons,
+				},
+			},
+			OnlyVisibleForYou: req.DM || cfg.DM,
+		},
+	}, nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// defaultStreamInterval is how often the progress message is edited when streamInterval is not set.
+	defaultStreamInterval = 10 * time.Second
+	// streamTailLines and streamTailBytes limit the output shown in the progress message.
+	streamTailLines = 10
+	streamTailBytes = 2000
+)
+
+// streamFlagRegex matches the --stream flag.
+var streamFlagRegex = regexp.MustCompile(`(^|\s)--stream(\s|$)`)
+
+// cutStreamFlag returns whether the --stream flag is set and the command without it.
+func cutStreamFlag(command string) (stream bool, rest string) {
+	head, tail := splitFlags(command)
+	match := streamFlagRegex.FindStringSubmatchIndex(head)
+	if match == nil {
+		return false, command
+	}
+	return true, head[:match[0]] + head[match[4]:] + tail
+}
+
+// tailBuffer keeps the last lines written to it. It is written by the command and read by the progress updates.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > 2*streamTailBytes {
+		t.buf = append([]byte(nil), t.buf[len(t.buf)-streamTailBytes:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the last complete and the current, partial, line.
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	text := string(t.buf)
+	if len(text) > streamTailBytes {
+		// the cut may split a multi-byte character
+		text = strings.ToValidUTF8(text[len(text)-streamTailBytes:], "")
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > streamTailLines {
+		lines = lines[len(lines)-streamTailLines:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// streamProgress is a Slack message of a running command, edited with the elapsed time and the last lines
+// of the output until the command exits. The executor reply is only sent when Execute returns,
+// so the progress is posted as a separate message of the bot.
+type streamProgress struct {
+	client  *slack.Client
+	channel string
+	ts      string
+	cmd     string
+	started time.Time
+	tail    tailBuffer
+}
+
+// startStreamProgress posts the initial progress message in the destination.
+func startStreamProgress(ctx context.Context, dest slackDestination, cmd string) (*streamProgress, error) {
+	p := &streamProgress{
+		client:  slack.New(dest.BotToken),
+		channel: dest.ChannelID,
+		cmd:     cmd,
+		started: time.Now(),
+	}
+	opts := []slack.MsgOption{slack.MsgOptionText(p.text(), false)}
+	if dest.ThreadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(dest.ThreadTS))
+	}
+	_, ts, err := p.client.PostMessageContext(ctx, p.channel, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("while posting progress of command %s: %w", cmd, err)
+	}
+	p.ts = ts
+	return p, nil
+}
+
+// run edits the message with the elapsed time and the last lines of the output until ctx is done.
+func (p *streamProgress) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := p.update(ctx, p.text()); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "failed to update progress of command %s: %v", p.cmd, err)
+		}
+	}
+}
+
+// finish replaces the progress with the outcome of the command.
+func (p *streamProgress) finish(ctx context.Context, outcome string) error {
+	elapsed := time.Since(p.started).Round(time.Second)
+	return p.update(ctx, fmt.Sprintf("Command `%s` %s after %s", p.cmd, outcome, elapsed))
+}
+
+func (p *streamProgress) update(ctx context.Context, text string) error {
+	if _, _, _, err := p.client.UpdateMessageContext(ctx, p.channel, p.ts, slack.MsgOptionText(text, false)); err != nil {
+		return fmt.Errorf("while updating message in channel %s: %w", p.channel, err)
+	}
+	return nil
+}
+
+func (p *streamProgress) text() string {
+	elapsed := time.Since(p.started).Round(time.Second)
+	text := fmt.Sprintf("Command `%s` is running for %s", p.cmd, elapsed)
+	if tail := p.tail.String(); tail != "" {
+		// a fence in the output would end the code block early
+		text += "\n```\n" + strings.ReplaceAll(tail, "```", "'''") + "\n```"
+	}
+	return text
+}
+
+// executeStreaming runs the command like executeWithTimeout while the progress message is edited,
+// and replaces the progress with the outcome when the command exits.
+func executeStreaming(ctx context.Context, cfg Config, dest slackDestination, cmd string, kubeConfig []byte, timeout time.Duration) (commandResult, bool, error) {
+	progress, err := startStreamProgress(ctx, dest, cmd)
+	if err != nil {
+		return commandResult{}, false, err
+	}
+	interval := cfg.StreamInterval
+	if interval <= 0 {
+		interval = defaultStreamInterval
+	}
+
+	progressCtx, cancelProgress := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		progress.run(progressCtx, interval)
+	}()
+	result, timedOut, err := executeWithTimeout(ctx, cfg, cmd, kubeConfig, timeout, &progress.tail)
+	cancelProgress()
+	<-done
+
+	var outcome string
+	switch {
+	case ctx.Err() != nil:
+		outcome = "was cancelled"
+	case err != nil:
+		outcome = "could not be run"
+	case timedOut:
+		outcome = "timed out, the partial output is attached"
+	case result.ExitCode != 0:
+		outcome = fmt.Sprintf("failed with exit code %d, the output is attached", result.ExitCode)
+	default:
+		outcome = "finished, the output is attached"
+	}
+	if finishErr := progress.finish(ctx, outcome); finishErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to finish progress of command %s: %v", cmd, finishErr)
+	}
+	return result, timedOut, err
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"botkube.io/plugins-example/internal/teamsupload"
+	"github.com/kubeshop/botkube/pkg/api"
+	"github.com/kubeshop/botkube/pkg/api/executor"
+)
+
+// sendToTeams uploads the file to the files folder of the configured channel and replies with a link to it.
+// The app can't post channel messages on its own, so the link is delivered by the Botkube response.
+func sendToTeams(ctx context.Context, cfg Config, req snippetRequest, files []snippetFile) (executor.ExecuteOutput, error) {
+	if req.Channel != "" || req.DM {
+		return executor.ExecuteOutput{}, fmt.Errorf("-ch and -dm are only supported on Slack")
+	}
+	creds, err := teamsCredentials(cfg)
+	if err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+	if cfg.Teams.TeamID == "" || cfg.Teams.ChannelID == "" {
+		return executor.ExecuteOutput{}, fmt.Errorf("teams.teamID and teams.channelID are not configured")
+	}
+
+	token, err := teamsupload.GetToken(ctx, creds)
+	if err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+	driveID, folderID, err := teamsupload.GetFilesFolder(ctx, token, cfg.Teams.TeamID, cfg.Teams.ChannelID)
+	if err != nil {
+		return executor.ExecuteOutput{}, err
+	}
+	btnBuilder := api.NewMessageButtonBuilder()
+	var names []string
+	var buttons []api.Button
+	for _, file := range files {
+		item, err := teamsupload.UploadFile(ctx, token, driveID, folderID, file.Name, file.Content)
+		if err != nil {
+			return executor.ExecuteOutput{}, err
+		}
+		names = append(names, item.Name)
+		buttons = append(buttons, btnBuilder.ForURL(openLabel("Open", "file", item.Name, len(files)), item.WebURL))
+	}
+
+	text := fmt.Sprintf("Command %s result uploaded to the channel files: %s", req.Cmd, strings.Join(names, ", "))
+	if req.Msg != "" {
+		text = fmt.Sprintf("%s %s", req.Msg, text)
+	}
+	return executor.ExecuteOutput{
+		Message: api.Message{
+			Sections: []api.Section{
+				{
+					Base: api.Base{
+						Body: api.Body{
+							Plaintext: text,
+						},
+					},
+					Buttons: buttons,
+				},
+			},
+		},
+	}, nil
+}
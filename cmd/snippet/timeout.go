@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+)
+
+const (
+	// defaultTimeout limits commands when neither -timeout nor the timeout config is set.
+	defaultTimeout = 5 * time.Minute
+	// waitDelay is how long output pipes are kept open after a timed out command is killed,
+	// e.g. when a child of the shell still holds them.
+	waitDelay = 5 * time.Second
+)
+
+// timeoutFlagRegex matches the -timeout flag, e.g. -timeout 5m.
+var timeoutFlagRegex = regexp.MustCompile(`(^|\s)-timeout\s+(\S+)`)
+
+// cutTimeoutFlag returns the value of the -timeout flag and the command without it.
+func cutTimeoutFlag(command string) (time.Duration, string, error) {
+	head, tail := splitFlags(command)
+	match := timeoutFlagRegex.FindStringSubmatchIndex(head)
+	if match == nil {
+		return 0, command, nil
+	}
+	value := head[match[4]:match[5]]
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, command, fmt.Errorf("invalid -timeout %q, use a positive duration, e.g. 30s or 5m", value)
+	}
+	return timeout, head[:match[0]] + head[match[1]:] + tail, nil
+}
+
+// executeWithTimeout runs the command until the timeout, killing it when it is exceeded.
+// A timed out command returns its partial output with timedOut set instead of an error.
+func executeWithTimeout(ctx context.Context, cfg Config, cmd string, kubeConfig []byte, timeout time.Duration, live io.Writer) (result commandResult, timedOut bool, err error) {
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err = executeCommand(cmdCtx, cfg, cmd, kubeConfig, live)
+	// a cancelled request, or one past its own deadline, has no one to share the partial output with
+	if ctx.Err() != nil {
+		return result, false, fmt.Errorf("command %s was stopped: %w", cmd, ctx.Err())
+	}
+	if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		return result, true, nil
+	}
+	return result, false, err
+}
@@ -0,0 +1,131 @@
+// Package azblobupload stores files in an Azure Blob Storage container and shares them with SAS URLs, which expire.
+// It authenticates with the storage account key or, without it, with Azure AD Workload Identity, in which case
+// the link is a user delegation SAS.
+package azblobupload
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
+)
+
+const (
+	// MaxExpiry is the longest validity of a user delegation SAS, also applied to account key SAS.
+	MaxExpiry = 7 * 24 * time.Hour
+
+	// clockSkew is subtracted from the start of SAS validity, so links work on hosts with a late clock.
+	clockSkew = 5 * time.Minute
+)
+
+// Options select the container and the credentials used to upload the file.
+type Options struct {
+	AccountName string
+	Container   string
+	// AccountKey is optional, without it the Workload Identity env vars injected into the pod are used:
+	// AZURE_CLIENT_ID, AZURE_TENANT_ID, AZURE_FEDERATED_TOKEN_FILE and AZURE_AUTHORITY_HOST.
+	AccountKey string
+	// Endpoint of the Blob service, https://<account>.blob.core.windows.net by default.
+	Endpoint string
+}
+
+// signer signs a SAS with the credentials the file was uploaded with.
+type signer func(ctx context.Context, values sas.BlobSignatureValues) (sas.QueryParameters, error)
+
+// Upload stores the content under the key and returns a SAS URL to download it, valid for expiry.
+func Upload(ctx context.Context, opts Options, key, content string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > MaxExpiry {
+		return "", fmt.Errorf("invalid link expiry %s, it must be positive and at most %s", expiry, MaxExpiry)
+	}
+	client, sign, err := newClient(opts)
+	if err != nil {
+		return "", err
+	}
+
+	blobClient := client.NewContainerClient(opts.Container).NewBlockBlobClient(key)
+	_, err = blobClient.UploadBuffer(ctx, []byte(content), &blockblob.UploadBufferOptions{
+		HTTPHeaders: &blob.HTTPHeaders{
+			BlobContentType: to.Ptr(contentType(key)),
+			// browsers download the file with its name instead of showing it
+			BlobContentDisposition: to.Ptr(fmt.Sprintf("attachment; filename=%q", path.Base(key))),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error uploading %s: %v", key, err)
+	}
+
+	now := time.Now().UTC()
+	query, err := sign(ctx, sas.BlobSignatureValues{
+		Protocol:      sas.ProtocolHTTPS,
+		StartTime:     now.Add(-clockSkew),
+		ExpiryTime:    now.Add(expiry),
+		Permissions:   (&sas.BlobPermissions{Read: true}).String(),
+		ContainerName: opts.Container,
+		BlobName:      key,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error signing the link to %s: %v", key, err)
+	}
+	return blobClient.URL() + "?" + query.Encode(), nil
+}
+
+// newClient returns a Blob service client authorized with the account key or Workload Identity, and the signer
+// of SAS for the same credentials.
+func newClient(opts Options) (*service.Client, signer, error) {
+	if opts.AccountKey != "" {
+		cred, err := service.NewSharedKeyCredential(opts.AccountName, opts.AccountKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid account key: %v", err)
+		}
+		client, err := service.NewClientWithSharedKeyCredential(serviceURL(opts), cred, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("while creating Blob service client: %v", err)
+		}
+		return client, func(_ context.Context, values sas.BlobSignatureValues) (sas.QueryParameters, error) {
+			return values.SignWithSharedKey(cred)
+		}, nil
+	}
+
+	cred, err := azidentity.NewWorkloadIdentityCredential(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("no account key is configured and Workload Identity is not enabled for the pod: %v", err)
+	}
+	client, err := service.NewClient(serviceURL(opts), cred, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("while creating Blob service client: %v", err)
+	}
+	return client, func(ctx context.Context, values sas.BlobSignatureValues) (sas.QueryParameters, error) {
+		// the key needs the Microsoft.Storage/storageAccounts/blobServices/generateUserDelegationKey permission
+		delegation, err := client.GetUserDelegationCredential(ctx, service.KeyInfo{
+			Start:  to.Ptr(values.StartTime.UTC().Format(sas.TimeFormat)),
+			Expiry: to.Ptr(values.ExpiryTime.UTC().Format(sas.TimeFormat)),
+		}, nil)
+		if err != nil {
+			return sas.QueryParameters{}, fmt.Errorf("error getting user delegation key: %v", err)
+		}
+		return values.SignWithUserDelegation(delegation)
+	}, nil
+}
+
+func serviceURL(opts Options) string {
+	if opts.Endpoint != "" {
+		return strings.TrimSuffix(opts.Endpoint, "/")
+	}
+	return fmt.Sprintf("https://%s.blob.core.windows.net", opts.AccountName)
+}
+
+// contentType returns the type of the stored file, outputs are text unless they are compressed.
+func contentType(key string) string {
+	if path.Ext(key) == ".gz" {
+		return "application/gzip"
+	}
+	return "text/plain; charset=utf-8"
+}
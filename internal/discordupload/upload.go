@@ -0,0 +1,96 @@
+// Package discordupload posts files to Discord channels as message attachments with a bot token.
+package discordupload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+const apiURL = "https://discord.com/api/v10"
+
+type messagePayload struct {
+	Content     string       `json:"content"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ID       int    `json:"id"`
+	Filename string `json:"filename"`
+}
+
+type message struct {
+	ID string `json:"id"`
+}
+
+// File is an attachment of the message.
+type File struct {
+	Name    string
+	Content string
+}
+
+// Upload posts the message with the files attached in the channel, at most 10 of them. It returns the message ID.
+func Upload(ctx context.Context, token, channelID, text string, files ...File) (string, error) {
+	attachments := make([]attachment, 0, len(files))
+	for i, f := range files {
+		attachments = append(attachments, attachment{ID: i, Filename: f.Name})
+	}
+	payload, err := json.Marshal(messagePayload{
+		Content:     text,
+		Attachments: attachments,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %v", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("payload_json", string(payload)); err != nil {
+		return "", fmt.Errorf("failed to create form: %v", err)
+	}
+	for i, f := range files {
+		file, err := form.CreateFormFile(fmt.Sprintf("files[%d]", i), f.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to create form: %v", err)
+		}
+		if _, err := io.WriteString(file, f.Content); err != nil {
+			return "", fmt.Errorf("failed to create form: %v", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to create form: %v", err)
+	}
+
+	messagesURL := fmt.Sprintf("%s/channels/%s/messages", apiURL, url.PathEscape(channelID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, messagesURL, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bot "+token)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error posting message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error posting message: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// e.g. 413 when the file exceeds the upload limit of the server
+		return "", fmt.Errorf("error posting message: %s: %s", resp.Status, string(bytes.TrimSpace(respBody)))
+	}
+	var created message
+	if err := json.Unmarshal(respBody, &created); err != nil {
+		return "", fmt.Errorf("failed to parse response: %v", err)
+	}
+	return created.ID, nil
+}
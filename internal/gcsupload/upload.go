@@ -0,0 +1,76 @@
+// Package gcsupload stores files in a Google Cloud Storage bucket and shares them with signed URLs, which expire.
+// Without a credentials file, Application Default Credentials are used, e.g. GKE Workload Identity.
+package gcsupload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// MaxExpiry is the longest validity of a V4 signed URL.
+const MaxExpiry = 7 * 24 * time.Hour
+
+// Options select the bucket and the credentials used to upload the file.
+type Options struct {
+	Bucket string
+	// CredentialsFile is an optional service account key file.
+	CredentialsFile string
+	// ServiceAccount is the email of the service account URLs are signed as. It is detected from the credentials
+	// when empty. Without a private key, URLs are signed with the IAM signBlob API, which needs the
+	// Service Account Token Creator role.
+	ServiceAccount string
+}
+
+// Upload stores the content under the key and returns a signed URL to download it, valid for expiry.
+func Upload(ctx context.Context, opts Options, key, content string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > MaxExpiry {
+		return "", fmt.Errorf("invalid link expiry %s, it must be positive and at most %s", expiry, MaxExpiry)
+	}
+	var clientOpts []option.ClientOption
+	if opts.CredentialsFile != "" {
+		clientOpts = append(clientOpts, option.WithCredentialsFile(opts.CredentialsFile))
+	}
+	client, err := storage.NewClient(ctx, clientOpts...)
+	if err != nil {
+		return "", fmt.Errorf("while creating the storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(opts.Bucket)
+	w := bucket.Object(key).NewWriter(ctx)
+	w.ContentType = contentType(key)
+	// browsers download the file with its name instead of showing it
+	w.ContentDisposition = fmt.Sprintf("attachment; filename=%q", path.Base(key))
+	if _, err := io.WriteString(w, content); err != nil {
+		w.Close()
+		return "", fmt.Errorf("error uploading gs://%s/%s: %v", opts.Bucket, key, err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("error uploading gs://%s/%s: %v", opts.Bucket, key, err)
+	}
+
+	link, err := bucket.SignedURL(key, &storage.SignedURLOptions{
+		GoogleAccessID: opts.ServiceAccount,
+		Method:         "GET",
+		Expires:        time.Now().Add(expiry),
+		Scheme:         storage.SigningSchemeV4,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error signing gs://%s/%s: %v", opts.Bucket, key, err)
+	}
+	return link, nil
+}
+
+// contentType returns the type of the stored file, outputs are text unless they are compressed.
+func contentType(key string) string {
+	if path.Ext(key) == ".gz" {
+		return "application/gzip"
+	}
+	return "text/plain; charset=utf-8"
+}
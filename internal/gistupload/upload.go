@@ -0,0 +1,81 @@
+// Package gistupload shares files as GitHub Gists, secret by default, so they are only found with the link.
+package gistupload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultAPIURL is the GitHub API, GitHub Enterprise Server uses https://<host>/api/v3.
+const defaultAPIURL = "https://api.github.com"
+
+type gistFile struct {
+	Content string `json:"content"`
+}
+
+type createGistPayload struct {
+	Description string              `json:"description"`
+	Public      bool                `json:"public"`
+	Files       map[string]gistFile `json:"files"`
+}
+
+type gist struct {
+	HTMLURL string `json:"html_url"`
+}
+
+// Upload creates a Gist with the files, by name, and returns its URL. The token needs the gist scope,
+// or the Gists write permission of a fine-grained token. An empty apiURL selects github.com.
+func Upload(ctx context.Context, apiURL, token, description string, public bool, files map[string]string) (string, error) {
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	gistFiles := make(map[string]gistFile, len(files))
+	for name, content := range files {
+		gistFiles[name] = gistFile{Content: content}
+	}
+	payload, err := json.Marshal(createGistPayload{
+		Description: description,
+		Public:      public,
+		Files:       gistFiles,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(apiURL, "/")+"/gists", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	var created gist
+	if err := do(req, &created); err != nil {
+		return "", fmt.Errorf("error creating gist: %v", err)
+	}
+	return created.HTMLURL, nil
+}
+
+// do sends the request and decodes the JSON response into out. Error responses are returned with their body.
+func do(req *http.Request, out interface{}) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, string(bytes.TrimSpace(body)))
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,126 @@
+// Package mattermostupload uploads files to Mattermost channels: the file is uploaded with the files API
+// and shared by creating a post referencing it.
+package mattermostupload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+type uploadResponse struct {
+	FileInfos []struct {
+		ID string `json:"id"`
+	} `json:"file_infos"`
+}
+
+type createPostPayload struct {
+	ChannelID string   `json:"channel_id"`
+	Message   string   `json:"message"`
+	FileIDs   []string `json:"file_ids"`
+}
+
+type post struct {
+	ID string `json:"id"`
+}
+
+// Upload shares the content as a file in the channel with the message. It returns the ID of the created post.
+func Upload(ctx context.Context, serverURL, token, channelID, filename, content, message string) (string, error) {
+	fileID, err := UploadFile(ctx, serverURL, token, channelID, filename, content)
+	if err != nil {
+		return "", err
+	}
+	return CreatePost(ctx, serverURL, token, channelID, message, fileID)
+}
+
+// UploadFile uploads the content to the channel and returns the file ID. The file is not visible until
+// a post references it.
+func UploadFile(ctx context.Context, serverURL, token, channelID, filename, content string) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("channel_id", channelID); err != nil {
+		return "", fmt.Errorf("failed to create form: %v", err)
+	}
+	file, err := form.CreateFormFile("files", filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create form: %v", err)
+	}
+	if _, err := io.WriteString(file, content); err != nil {
+		return "", fmt.Errorf("failed to create form: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to create form: %v", err)
+	}
+
+	req, err := apiRequest(ctx, serverURL, token, http.MethodPost, "/files", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var result uploadResponse
+	if err := do(req, &result); err != nil {
+		return "", fmt.Errorf("error uploading file: %v", err)
+	}
+	if len(result.FileInfos) == 0 {
+		return "", fmt.Errorf("error uploading file: no file info returned")
+	}
+	return result.FileInfos[0].ID, nil
+}
+
+// CreatePost posts the message with the uploaded files in the channel and returns the post ID.
+func CreatePost(ctx context.Context, serverURL, token, channelID, message string, fileIDs ...string) (string, error) {
+	payload, err := json.Marshal(createPostPayload{
+		ChannelID: channelID,
+		Message:   message,
+		FileIDs:   fileIDs,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %v", err)
+	}
+
+	req, err := apiRequest(ctx, serverURL, token, http.MethodPost, "/posts", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var created post
+	if err := do(req, &created); err != nil {
+		return "", fmt.Errorf("error creating post: %v", err)
+	}
+	return created.ID, nil
+}
+
+func apiRequest(ctx context.Context, serverURL, token, method, path string, body io.Reader) (*http.Request, error) {
+	url := strings.TrimSuffix(serverURL, "/") + "/api/v4" + path
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// do sends the request and decodes the JSON response into out. Error responses are returned with their body.
+func do(req *http.Request, out interface{}) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, string(bytes.TrimSpace(body)))
+	}
+	return json.Unmarshal(body, out)
+}
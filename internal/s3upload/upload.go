@@ -0,0 +1,94 @@
+// Package s3upload stores files in an S3 bucket and shares them with presigned URLs, which expire,
+// so large or sensitive outputs don't have to be stored by the communication platform.
+package s3upload
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// MaxExpiry is the longest validity of a presigned URL S3 accepts.
+const MaxExpiry = 7 * 24 * time.Hour
+
+// Options select the bucket and the credentials used to upload the file.
+type Options struct {
+	Bucket string
+	Region string
+	// Endpoint is set for S3-compatible storage, e.g. MinIO. Such endpoints are addressed with path-style URLs.
+	Endpoint string
+	// AccessKeyID and SecretAccessKey are optional, without them the default credential chain is used,
+	// e.g. the IAM role of the service account.
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Upload stores the content under the key and returns a presigned URL to download it, valid for expiry.
+func Upload(ctx context.Context, opts Options, key, content string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > MaxExpiry {
+		return "", fmt.Errorf("invalid link expiry %s, it must be positive and at most %s", expiry, MaxExpiry)
+	}
+	sess, err := newSession(opts)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s3manager.NewUploader(sess).UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(opts.Bucket),
+		Key:         aws.String(key),
+		Body:        strings.NewReader(content),
+		ContentType: aws.String(contentType(key)),
+		// browsers download the file with its name instead of showing it
+		ContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", path.Base(key))),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error uploading s3://%s/%s: %v", opts.Bucket, key, err)
+	}
+
+	req, _ := s3.New(sess).GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(opts.Bucket),
+		Key:    aws.String(key),
+	})
+	link, err := req.Presign(expiry)
+	if err != nil {
+		return "", fmt.Errorf("error presigning s3://%s/%s: %v", opts.Bucket, key, err)
+	}
+	return link, nil
+}
+
+// contentType returns the type of the stored file, outputs are text unless they are compressed.
+func contentType(key string) string {
+	if path.Ext(key) == ".gz" {
+		return "application/gzip"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+func newSession(opts Options) (*session.Session, error) {
+	cfg := aws.NewConfig()
+	if opts.Region != "" {
+		cfg = cfg.WithRegion(opts.Region)
+	}
+	if opts.Endpoint != "" {
+		cfg = cfg.WithEndpoint(opts.Endpoint).WithS3ForcePathStyle(true)
+	}
+	if opts.AccessKeyID != "" || opts.SecretAccessKey != "" {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(opts.AccessKeyID, opts.SecretAccessKey, ""))
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("while creating the AWS session: %v", err)
+	}
+	return sess, nil
+}
@@ -0,0 +1,60 @@
+package slackupload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// attempts is how many times a call failing with a rate limit or a server error is made.
+	attempts = 5
+	// backoff is the wait before the first retry, doubled for each next one unless Slack sets Retry-After.
+	backoff = time.Second
+	// maxWait bounds a single wait, including the one requested with Retry-After.
+	maxWait = 30 * time.Second
+)
+
+// retry calls fn until it succeeds, retrying rate limited calls and server errors (5xx) with exponential backoff.
+// The returned error describes the last attempt.
+func retry(ctx context.Context, fn func() error) error {
+	wait := backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		delay, retryable := retryDelay(err, wait)
+		if !retryable {
+			return err
+		}
+		if attempt >= attempts {
+			return fmt.Errorf("%v, after %d attempts", err, attempts)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(min(delay, maxWait)):
+		}
+		wait *= 2
+	}
+}
+
+// retryDelay returns the wait before retrying the failed call, the one requested with Retry-After
+// or the fallback, and false if the error is not transient.
+func retryDelay(err error, fallback time.Duration) (time.Duration, bool) {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return rateLimited.RetryAfter, true
+	}
+	var statusErr slack.StatusCodeError
+	if errors.As(err, &statusErr) {
+		return fallback, statusErr.Code == http.StatusTooManyRequests || statusErr.Code >= http.StatusInternalServerError
+	}
+	return 0, false
+}
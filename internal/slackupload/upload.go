@@ -0,0 +1,92 @@
+// Package slackupload uploads files to Slack channels with the external upload flow of the slack-go SDK:
+// an upload URL is requested, the content is sent to it and the upload is completed by sharing the file.
+// Rate limited requests and server errors are retried with exponential backoff, honoring Retry-After.
+package slackupload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// File is a file to upload.
+type File struct {
+	Name    string
+	Content string
+	// SnippetType, e.g. yaml, sets the syntax highlighting of the file, Slack detects it from the name when empty.
+	SnippetType string
+}
+
+// Upload shares the content as a file in the channel, in the thread if threadTS is set. It returns the file ID.
+func Upload(ctx context.Context, token, channelID, threadTS, filename, content, message string) (string, error) {
+	client := slack.New(token)
+	var file *slack.FileSummary
+	err := retry(ctx, func() (err error) {
+		file, err = client.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+			Filename:        filename,
+			FileSize:        len(content),
+			Content:         content,
+			Channel:         channelID,
+			ThreadTimestamp: threadTS,
+			InitialComment:  message,
+		})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("error uploading file: %v", err)
+	}
+	return file.ID, nil
+}
+
+// UploadFiles shares the files in a single message in the channel, in the thread if threadTS is set.
+// It returns the file IDs.
+func UploadFiles(ctx context.Context, token, channelID, threadTS, message string, files []File) ([]string, error) {
+	client := slack.New(token)
+	summaries := make([]slack.FileSummary, 0, len(files))
+	for _, file := range files {
+		var upload *slack.GetUploadURLExternalResponse
+		err := retry(ctx, func() (err error) {
+			upload, err = client.GetUploadURLExternalContext(ctx, slack.GetUploadURLExternalParameters{
+				FileName:    file.Name,
+				FileSize:    len(file.Content),
+				SnippetType: file.SnippetType,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error getting upload URL: %v", err)
+		}
+
+		err = retry(ctx, func() error {
+			return client.UploadToURL(ctx, slack.UploadToURLParameters{
+				UploadURL: upload.UploadURL,
+				Content:   file.Content,
+				Filename:  file.Name,
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error uploading file: %v", err)
+		}
+		summaries = append(summaries, slack.FileSummary{ID: upload.FileID, Title: file.Name})
+	}
+
+	err := retry(ctx, func() error {
+		_, err := client.CompleteUploadExternalContext(ctx, slack.CompleteUploadExternalParameters{
+			Files:           summaries,
+			Channel:         channelID,
+			ThreadTimestamp: threadTS,
+			InitialComment:  message,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error completing upload: %v", err)
+	}
+
+	ids := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		ids = append(ids, summary.ID)
+	}
+	return ids, nil
+}
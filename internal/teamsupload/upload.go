@@ -0,0 +1,181 @@
+// Package teamsupload uploads files to the files folder of a Microsoft Teams channel with the Microsoft Graph API.
+// The app authenticates with the client credentials flow and needs the Files.ReadWrite.All application permission.
+package teamsupload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	graphURL = "https://graph.microsoft.com/v1.0"
+	loginURL = "https://login.microsoftonline.com"
+
+	// maxSimpleUploadSize is the limit of a single PUT upload, larger files are uploaded in an upload session.
+	maxSimpleUploadSize = 4 * 1024 * 1024
+	// chunkSize of upload sessions, it must be a multiple of 320 KiB.
+	chunkSize = 10 * 320 * 1024
+)
+
+// Credentials of the Azure AD app used to call Microsoft Graph.
+type Credentials struct {
+	TenantID    string
+	AppID       string
+	AppPassword string
+}
+
+// DriveItem is the uploaded file.
+type DriveItem struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	WebURL string `json:"webUrl"`
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type filesFolder struct {
+	ID              string `json:"id"`
+	ParentReference struct {
+		DriveID string `json:"driveId"`
+	} `json:"parentReference"`
+}
+
+type uploadSession struct {
+	UploadURL string `json:"uploadUrl"`
+}
+
+// Upload stores the content as a file in the files folder of the channel, which is the channel's SharePoint folder.
+func Upload(ctx context.Context, creds Credentials, teamID, channelID, filename, content string) (DriveItem, error) {
+	token, err := GetToken(ctx, creds)
+	if err != nil {
+		return DriveItem{}, err
+	}
+	driveID, folderID, err := GetFilesFolder(ctx, token, teamID, channelID)
+	if err != nil {
+		return DriveItem{}, err
+	}
+	return UploadFile(ctx, token, driveID, folderID, filename, content)
+}
+
+// GetToken returns a Microsoft Graph access token of the app.
+func GetToken(ctx context.Context, creds Credentials) (string, error) {
+	form := url.Values{
+		"client_id":     {creds.AppID},
+		"client_secret": {creds.AppPassword},
+		"scope":         {"https://graph.microsoft.com/.default"},
+		"grant_type":    {"client_credentials"},
+	}
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", loginURL, url.PathEscape(creds.TenantID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result tokenResponse
+	if err := do(req, &result); err != nil {
+		return "", fmt.Errorf("error getting access token: %v", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("error getting access token: %s", result.ErrorDescription)
+	}
+	return result.AccessToken, nil
+}
+
+// GetFilesFolder returns the drive and item ID of the channel's files folder.
+func GetFilesFolder(ctx context.Context, token, teamID, channelID string) (string, string, error) {
+	folderURL := fmt.Sprintf("%s/teams/%s/channels/%s/filesFolder", graphURL, url.PathEscape(teamID), url.PathEscape(channelID))
+	req, err := graphRequest(ctx, token, http.MethodGet, folderURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	var folder filesFolder
+	if err := do(req, &folder); err != nil {
+		return "", "", fmt.Errorf("error getting files folder of channel %s: %v", channelID, err)
+	}
+	return folder.ParentReference.DriveID, folder.ID, nil
+}
+
+// UploadFile uploads the content to the folder, in chunks if it is larger than a single request allows.
+func UploadFile(ctx context.Context, token, driveID, folderID, filename, content string) (DriveItem, error) {
+	itemURL := fmt.Sprintf("%s/drives/%s/items/%s:/%s:", graphURL, url.PathEscape(driveID), url.PathEscape(folderID), url.PathEscape(filename))
+	if len(content) <= maxSimpleUploadSize {
+		req, err := graphRequest(ctx, token, http.MethodPut, itemURL+"/content", strings.NewReader(content))
+		if err != nil {
+			return DriveItem{}, err
+		}
+		req.Header.Set("Content-Type", "text/plain")
+
+		var item DriveItem
+		if err := do(req, &item); err != nil {
+			return DriveItem{}, fmt.Errorf("error uploading file: %v", err)
+		}
+		return item, nil
+	}
+
+	req, err := graphRequest(ctx, token, http.MethodPost, itemURL+"/createUploadSession", strings.NewReader(`{"item":{"@microsoft.graph.conflictBehavior":"rename"}}`))
+	if err != nil {
+		return DriveItem{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var session uploadSession
+	if err := do(req, &session); err != nil {
+		return DriveItem{}, fmt.Errorf("error creating upload session: %v", err)
+	}
+
+	var item DriveItem
+	for start := 0; start < len(content); start += chunkSize {
+		end := min(start+chunkSize, len(content))
+		// the upload URL is pre-authenticated, it must not get the Authorization header
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, session.UploadURL, strings.NewReader(content[start:end]))
+		if err != nil {
+			return DriveItem{}, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(content)))
+		// the last chunk returns the created item, the others the next expected ranges
+		if err := do(req, &item); err != nil {
+			return DriveItem{}, fmt.Errorf("error uploading file: %v", err)
+		}
+	}
+	return item, nil
+}
+
+func graphRequest(ctx context.Context, token, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// do sends the request and decodes the JSON response into out. Error responses are returned with their body.
+func do(req *http.Request, out interface{}) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, string(bytes.TrimSpace(body)))
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}